@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"asset-tracker/internal/catalog"
+	"asset-tracker/internal/config"
+	"asset-tracker/internal/db"
+	"asset-tracker/internal/providers"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report the catalog diff without writing to public.assets")
+	flag.Parse()
+
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+
+	cfg, err := config.LoadForCatalog()
+	if err != nil {
+		slog.Error("failed to load catalog config", "error", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	database, err := db.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		slog.Error("failed to initialize database", "error", err)
+		os.Exit(1)
+	}
+	defer database.Close()
+
+	provider, err := providers.NewCatalogFromConfig(cfg)
+	if err != nil {
+		slog.Error("failed to initialize catalog provider", "error", err)
+		os.Exit(1)
+	}
+
+	plan, err := catalog.NewSyncer(database, provider).Run(ctx, *dryRun)
+	if err != nil {
+		slog.Error("catalog sync failed", "error", err)
+		os.Exit(1)
+	}
+
+	if *dryRun {
+		for _, change := range plan.Changes {
+			slog.Info(
+				"catalog change",
+				"kind", string(change.Kind),
+				"asset_id", change.AssetID,
+				"market_data_id", change.MarketDataID,
+				"old_symbol", change.OldSymbol,
+				"new_symbol", change.NewSymbol,
+				"old_name", change.OldName,
+				"new_name", change.NewName,
+			)
+		}
+		for _, collision := range plan.Collisions {
+			slog.Warn(
+				"catalog collision",
+				"reason", string(collision.Reason),
+				"market_data_id", collision.MarketDataID,
+				"symbol", collision.Symbol,
+				"name", collision.Name,
+				"existing_asset_id", collision.ExistingID,
+				"competing_listings", collision.CompetingCount,
+			)
+		}
+	}
+
+	slog.Info(
+		"catalog sync completed",
+		"dry_run", *dryRun,
+		"inserts", plan.Count(catalog.ChangeInsert),
+		"renames", plan.Count(catalog.ChangeRename),
+		"symbol_changes", plan.Count(catalog.ChangeSymbolChange),
+		"links", plan.Count(catalog.ChangeLink),
+		"collisions", len(plan.Collisions),
+		"unchanged", plan.Unchanged,
+		"skipped", plan.Skipped,
+	)
+}
@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
+	"expvar"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -12,6 +15,7 @@ import (
 	"asset-tracker/internal/db"
 	"asset-tracker/internal/prices"
 	"asset-tracker/internal/providers"
+	"asset-tracker/internal/webhooks"
 )
 
 func main() {
@@ -43,6 +47,13 @@ func main() {
 		return nil
 	})
 
+	go runWebhookJobs(ctx, database)
+	go runHousekeeping(ctx, database)
+	go runBackfill(ctx, database, providerSet)
+	if cfg.DebugAddr != "" {
+		go serveDebugVars(ctx, cfg.DebugAddr)
+	}
+
 	slog.Info("worker started", "interval_seconds", 30)
 	if err := scheduler.Run(ctx); err != nil && err != context.Canceled {
 		slog.Error("worker stopped unexpectedly", "error", err)
@@ -50,3 +61,84 @@ func main() {
 	}
 	slog.Info("worker stopped")
 }
+
+// runWebhookJobs delivers queued webhooks and queues daily summaries on their
+// own schedules so slow endpoints never delay price refreshes.
+func runWebhookJobs(ctx context.Context, database *db.DB) {
+	dispatcher := webhooks.NewDispatcher(database, nil)
+	dispatch := prices.NewScheduler(10*time.Second, func(ctx context.Context) error {
+		delivered, err := dispatcher.DispatchDue(ctx)
+		if err != nil {
+			slog.Error("webhook dispatch cycle failed", "error", err)
+		} else if delivered > 0 {
+			slog.Info("webhooks delivered", "count", delivered)
+		}
+		return nil
+	})
+	summaries := prices.NewScheduler(5*time.Minute, func(ctx context.Context) error {
+		queued, err := webhooks.EnqueueDailySummaries(ctx, database, time.Now())
+		if err != nil {
+			slog.Error("daily summary cycle failed", "error", err)
+		} else if queued > 0 {
+			slog.Info("daily summaries queued", "count", queued)
+		}
+		return nil
+	})
+
+	go func() { _ = summaries.Run(ctx) }()
+	_ = dispatch.Run(ctx)
+}
+
+// runHousekeeping purges soft-deleted lots once their restore window has
+// passed, and expired idempotency keys.
+func runHousekeeping(ctx context.Context, database *db.DB) {
+	housekeeping := prices.NewScheduler(time.Hour, func(ctx context.Context) error {
+		purged, err := database.PurgeDeletedLots(ctx, time.Now().Add(-db.DeletedLotRetention))
+		if err != nil {
+			slog.Error("deleted lot purge failed", "error", err)
+		} else if purged > 0 {
+			slog.Info("deleted lots purged", "count", purged)
+		}
+
+		expired, err := database.PurgeExpiredIdempotencyKeys(ctx, time.Now())
+		if err != nil {
+			slog.Error("idempotency key purge failed", "error", err)
+		} else if expired > 0 {
+			slog.Info("expired idempotency keys purged", "count", expired)
+		}
+		return nil
+	})
+	_ = housekeeping.Run(ctx)
+}
+
+// runBackfill fills in daily prices from each asset's earliest lot up to its
+// first live snapshot, a few provider requests per cycle.
+func runBackfill(ctx context.Context, database *db.DB, providerSet providers.ProviderSet) {
+	backfiller := prices.NewBackfiller(database, providerSet.StockHistory, providerSet.CryptoHistory)
+	backfill := prices.NewScheduler(10*time.Minute, func(ctx context.Context) error {
+		inserted, err := backfiller.Run(ctx)
+		if err != nil {
+			slog.Error("price backfill cycle failed", "error", err)
+		} else if inserted > 0 {
+			slog.Info("price snapshots backfilled", "count", inserted)
+		}
+		return nil
+	})
+	_ = backfill.Run(ctx)
+}
+
+// serveDebugVars exposes the worker's expvar metrics, such as the provider
+// counters, at /debug/vars.
+func serveDebugVars(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	slog.Info("worker debug server started", "addr", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("worker debug server stopped", "error", err)
+	}
+}
@@ -38,7 +38,9 @@ func main() {
 	hub := ws.NewHub()
 	verifier := auth.NewSupabaseVerifier(cfg.SupabaseURL, cfg.SupabaseSecretKey)
 	server := ws.NewServer(hub, verifier)
+	server.Watchlists = database
 	apiServer := api.NewServer(database, verifier)
+	apiServer.AdminUserIDs = cfg.AdminUserIDs
 
 	router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -57,6 +59,8 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	go listenAlertEvents(ctx, database, server)
+
 	serverErrCh := make(chan error, 1)
 	go func() {
 		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -82,3 +86,32 @@ func main() {
 	}
 	slog.Info("ws/api server stopped")
 }
+
+// listenAlertEvents relays alert events notified by the worker to connected
+// websocket sessions, reconnecting after listener failures.
+func listenAlertEvents(ctx context.Context, database *db.DB, server *ws.Server) {
+	for {
+		err := database.ListenAlertEvents(ctx, func(event db.AlertEvent) {
+			server.PublishAlertEvent(event.UserID, ws.AlertEvent{
+				ID:            event.ID,
+				AlertID:       event.AlertID,
+				AssetID:       event.AssetID,
+				Kind:          string(event.Kind),
+				Threshold:     event.Threshold,
+				ObservedValue: event.ObservedValue,
+				Price:         event.Price,
+				TriggeredAt:   event.TriggeredAt.UTC().Format(time.RFC3339),
+			})
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Error("alert event listener stopped; retrying", "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
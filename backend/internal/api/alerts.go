@@ -0,0 +1,288 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"asset-tracker/internal/db"
+)
+
+const (
+	defaultAlertCooldownSec   = 3600
+	defaultAlertHysteresisPct = 0.01
+)
+
+type alertResponse struct {
+	ID              int64   `json:"id"`
+	AssetID         int64   `json:"asset_id"`
+	Kind            string  `json:"kind"`
+	Threshold       float64 `json:"threshold"`
+	CooldownSec     int     `json:"cooldown_sec"`
+	HysteresisPct   float64 `json:"hysteresis_pct"`
+	Enabled         bool    `json:"enabled"`
+	Armed           bool    `json:"armed"`
+	LastTriggeredAt *string `json:"last_triggered_at"`
+	CreatedAt       string  `json:"created_at"`
+}
+
+type alertEventResponse struct {
+	ID            int64   `json:"id"`
+	AlertID       int64   `json:"alert_id"`
+	AssetID       int64   `json:"asset_id"`
+	Kind          string  `json:"kind"`
+	Threshold     float64 `json:"threshold"`
+	ObservedValue float64 `json:"observed_value"`
+	Price         float64 `json:"price"`
+	TriggeredAt   string  `json:"triggered_at"`
+}
+
+type createAlertRequest struct {
+	AssetID       int64    `json:"asset_id"`
+	Kind          string   `json:"kind"`
+	Threshold     float64  `json:"threshold"`
+	CooldownSec   *int     `json:"cooldown_sec"`
+	HysteresisPct *float64 `json:"hysteresis_pct"`
+	Enabled       *bool    `json:"enabled"`
+}
+
+type createAlertResponse struct {
+	ID int64 `json:"id"`
+}
+
+type updateAlertRequest struct {
+	Threshold     *float64 `json:"threshold"`
+	CooldownSec   *int     `json:"cooldown_sec"`
+	HysteresisPct *float64 `json:"hysteresis_pct"`
+	Enabled       *bool    `json:"enabled"`
+}
+
+func (s *Server) handleListAlerts(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	alerts, err := s.DB.ListAlertsByUser(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load alerts")
+		return
+	}
+
+	response := make([]alertResponse, 0, len(alerts))
+	for _, alert := range alerts {
+		item := alertResponse{
+			ID:            alert.ID,
+			AssetID:       alert.AssetID,
+			Kind:          string(alert.Kind),
+			Threshold:     alert.Threshold,
+			CooldownSec:   alert.CooldownSec,
+			HysteresisPct: alert.HysteresisPct,
+			Enabled:       alert.Enabled,
+			Armed:         alert.Armed,
+			CreatedAt:     alert.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		if alert.LastTriggeredAt.Valid {
+			triggeredAt := alert.LastTriggeredAt.Time.UTC().Format(time.RFC3339)
+			item.LastTriggeredAt = &triggeredAt
+		}
+		response = append(response, item)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleCreateAlert(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	var req createAlertRequest
+	if err := decodeJSONBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	kind, ok := parseAlertKind(req.Kind)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "kind must be price_above, price_below, position_drawdown, or change_24h")
+		return
+	}
+	if req.AssetID <= 0 {
+		writeError(w, http.StatusBadRequest, "asset_id must be greater than 0")
+		return
+	}
+	if message := validateAlertFields(&req.Threshold, req.CooldownSec, req.HysteresisPct); message != "" {
+		writeError(w, http.StatusBadRequest, message)
+		return
+	}
+
+	alert := db.Alert{
+		UserID:        userID,
+		AssetID:       req.AssetID,
+		Kind:          kind,
+		Threshold:     req.Threshold,
+		CooldownSec:   defaultAlertCooldownSec,
+		HysteresisPct: defaultAlertHysteresisPct,
+		Enabled:       true,
+	}
+	if req.CooldownSec != nil {
+		alert.CooldownSec = *req.CooldownSec
+	}
+	if req.HysteresisPct != nil {
+		alert.HysteresisPct = *req.HysteresisPct
+	}
+	if req.Enabled != nil {
+		alert.Enabled = *req.Enabled
+	}
+
+	id, err := s.DB.InsertAlert(r.Context(), alert)
+	switch {
+	case errors.Is(err, db.ErrAssetNotFound):
+		writeError(w, http.StatusBadRequest, "asset_id must reference an existing asset")
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "failed to create alert")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, createAlertResponse{ID: id})
+}
+
+func (s *Server) handleUpdateAlert(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	alertID, err := parseIDParam(r, "alertID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid alert id")
+		return
+	}
+
+	var req updateAlertRequest
+	if err := decodeJSONBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if message := validateAlertFields(req.Threshold, req.CooldownSec, req.HysteresisPct); message != "" {
+		writeError(w, http.StatusBadRequest, message)
+		return
+	}
+
+	updated, err := s.DB.UpdateAlertForUser(r.Context(), userID, alertID, db.AlertPatch{
+		Threshold:     req.Threshold,
+		CooldownSec:   req.CooldownSec,
+		HysteresisPct: req.HysteresisPct,
+		Enabled:       req.Enabled,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update alert")
+		return
+	}
+	if !updated {
+		writeError(w, http.StatusNotFound, "alert not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleDeleteAlert(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	alertID, err := parseIDParam(r, "alertID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid alert id")
+		return
+	}
+
+	deleted, err := s.DB.DeleteAlertForUser(r.Context(), userID, alertID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete alert")
+		return
+	}
+	if !deleted {
+		writeError(w, http.StatusNotFound, "alert not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleListAlertEvents(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	limit := 50
+	if rawLimit := strings.TrimSpace(r.URL.Query().Get("limit")); rawLimit != "" {
+		parsedLimit, err := strconv.Atoi(rawLimit)
+		if err != nil || parsedLimit <= 0 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		if parsedLimit > 200 {
+			parsedLimit = 200
+		}
+		limit = parsedLimit
+	}
+
+	events, err := s.DB.ListAlertEventsByUser(r.Context(), userID, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load alert events")
+		return
+	}
+
+	response := make([]alertEventResponse, 0, len(events))
+	for _, event := range events {
+		response = append(response, alertEventResponse{
+			ID:            event.ID,
+			AlertID:       event.AlertID,
+			AssetID:       event.AssetID,
+			Kind:          string(event.Kind),
+			Threshold:     event.Threshold,
+			ObservedValue: event.ObservedValue,
+			Price:         event.Price,
+			TriggeredAt:   event.TriggeredAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func parseAlertKind(value string) (db.AlertKind, bool) {
+	kind := db.AlertKind(strings.ToLower(strings.TrimSpace(value)))
+	switch kind {
+	case db.AlertKindPriceAbove, db.AlertKindPriceBelow, db.AlertKindPositionDrawdown, db.AlertKindChange24h:
+		return kind, true
+	default:
+		return "", false
+	}
+}
+
+func validateAlertFields(threshold *float64, cooldownSec *int, hysteresisPct *float64) string {
+	if threshold != nil && *threshold <= 0 {
+		return "threshold must be greater than 0"
+	}
+	if cooldownSec != nil && *cooldownSec < 0 {
+		return "cooldown_sec must be greater than or equal to 0"
+	}
+	if hysteresisPct != nil && (*hysteresisPct < 0 || *hysteresisPct >= 1) {
+		return "hysteresis_pct must be between 0 and 1"
+	}
+	return ""
+}
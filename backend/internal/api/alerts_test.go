@@ -0,0 +1,119 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"asset-tracker/internal/auth"
+	"asset-tracker/internal/db"
+)
+
+func TestAPICreateAlertDefaults(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	body := []byte(`{"asset_id":1,"kind":"price_above","threshold":100000}`)
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/alerts", "good", body))
+
+	if res.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", res.Code)
+	}
+	if len(store.insertedAlerts) != 1 {
+		t.Fatalf("expected 1 insert, got %d", len(store.insertedAlerts))
+	}
+	alert := store.insertedAlerts[0]
+	if alert.UserID != "user-1" || alert.Kind != db.AlertKindPriceAbove || alert.Threshold != 100000 {
+		t.Fatalf("unexpected alert: %+v", alert)
+	}
+	if alert.CooldownSec != defaultAlertCooldownSec || alert.HysteresisPct != defaultAlertHysteresisPct || !alert.Enabled {
+		t.Fatalf("expected defaults to be applied, got %+v", alert)
+	}
+}
+
+func TestAPICreateAlertUnknownAsset(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{insertAlertErr: db.ErrAssetNotFound}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	body := []byte(`{"asset_id":999,"kind":"price_above","threshold":100}`)
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/alerts", "good", body))
+
+	if res.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", res.Code)
+	}
+}
+
+func TestAPICreateAlertValidation(t *testing.T) {
+	t.Parallel()
+
+	for _, body := range []string{
+		`{"asset_id":1,"kind":"moon","threshold":1}`,
+		`{"asset_id":0,"kind":"price_above","threshold":1}`,
+		`{"asset_id":1,"kind":"price_above","threshold":0}`,
+		`{"asset_id":1,"kind":"price_above","threshold":1,"hysteresis_pct":1.5}`,
+		`{"asset_id":1,"kind":"price_above","threshold":1,"cooldown_sec":-1}`,
+	} {
+		store := &mockStore{}
+		router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+		res := httptest.NewRecorder()
+
+		router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/alerts", "good", []byte(body)))
+		if res.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", body, res.Code)
+		}
+		if len(store.insertedAlerts) != 0 {
+			t.Fatalf("expected no inserts for %s", body)
+		}
+	}
+}
+
+func TestAPIUpdateAlertNotFound(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{alertFound: false}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodPatch, "/api/v1/alerts/3", "good", []byte(`{"enabled":false}`)))
+	if res.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", res.Code)
+	}
+	if store.alertPatch.Enabled == nil || *store.alertPatch.Enabled {
+		t.Fatalf("expected enabled=false patch, got %+v", store.alertPatch)
+	}
+}
+
+func TestAPIListAlertEvents(t *testing.T) {
+	t.Parallel()
+
+	triggeredAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := &mockStore{alertEvents: []db.AlertEvent{{
+		ID: 5, AlertID: 3, AssetID: 1, Kind: db.AlertKindPriceAbove, Threshold: 100, ObservedValue: 101, Price: 101, TriggeredAt: triggeredAt,
+	}}}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodGet, "/api/v1/alerts/events?limit=500", "good", nil))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.Code)
+	}
+	if store.alertEventsMax != 200 {
+		t.Fatalf("expected limit to be clamped to 200, got %d", store.alertEventsMax)
+	}
+
+	var got []alertEventResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got) != 1 || got[0].AlertID != 3 || got[0].TriggeredAt != "2026-03-01T12:00:00Z" {
+		t.Fatalf("unexpected events response: %+v", got)
+	}
+}
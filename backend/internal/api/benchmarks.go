@@ -0,0 +1,324 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"asset-tracker/internal/db"
+)
+
+const maxBenchmarks = 10
+
+const defaultBenchmarkRange = "30d"
+
+type benchmarkRange struct {
+	Span     time.Duration
+	Step     time.Duration
+	Interval string
+}
+
+var benchmarkRanges = map[string]benchmarkRange{
+	"1d":  {Span: 24 * time.Hour, Step: time.Hour, Interval: "1h"},
+	"7d":  {Span: 7 * 24 * time.Hour, Step: time.Hour, Interval: "1h"},
+	"30d": {Span: 30 * 24 * time.Hour, Step: 24 * time.Hour, Interval: "1d"},
+	"90d": {Span: 90 * 24 * time.Hour, Step: 24 * time.Hour, Interval: "1d"},
+	"1y":  {Span: 365 * 24 * time.Hour, Step: 24 * time.Hour, Interval: "1d"},
+}
+
+type benchmarksBody struct {
+	AssetIDs []int64 `json:"asset_ids"`
+}
+
+type performancePoint struct {
+	At    string   `json:"at"`
+	Value *float64 `json:"value"`
+}
+
+type portfolioSeries struct {
+	Points []performancePoint `json:"points"`
+}
+
+type benchmarkSeries struct {
+	AssetID int64              `json:"asset_id"`
+	Symbol  string             `json:"symbol"`
+	Name    string             `json:"name"`
+	Points  []performancePoint `json:"points"`
+}
+
+type portfolioBenchmarkResponse struct {
+	Range      string            `json:"range"`
+	Interval   string            `json:"interval"`
+	Portfolio  portfolioSeries   `json:"portfolio"`
+	Benchmarks []benchmarkSeries `json:"benchmarks"`
+}
+
+func (s *Server) handleListBenchmarks(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	assetIDs, err := s.DB.ListBenchmarks(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load benchmarks")
+		return
+	}
+	if assetIDs == nil {
+		assetIDs = []int64{}
+	}
+
+	writeJSON(w, http.StatusOK, benchmarksBody{AssetIDs: assetIDs})
+}
+
+// handleReplaceBenchmarks replaces every benchmark of the user; an empty list
+// clears them.
+func (s *Server) handleReplaceBenchmarks(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	var req benchmarksBody
+	if err := decodeJSONBody(r, &req); err != nil || req.AssetIDs == nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if len(req.AssetIDs) > maxBenchmarks {
+		writeError(w, http.StatusBadRequest, "asset_ids must hold at most 10 entries")
+		return
+	}
+	seen := make(map[int64]bool, len(req.AssetIDs))
+	for _, assetID := range req.AssetIDs {
+		if assetID <= 0 {
+			writeError(w, http.StatusBadRequest, "asset_ids must be greater than 0")
+			return
+		}
+		if seen[assetID] {
+			writeError(w, http.StatusBadRequest, "asset_ids must not repeat an asset")
+			return
+		}
+		seen[assetID] = true
+	}
+
+	err := s.DB.ReplaceBenchmarks(r.Context(), userID, req.AssetIDs)
+	switch {
+	case errors.Is(err, db.ErrAssetNotFound):
+		writeError(w, http.StatusBadRequest, "asset_ids must reference existing assets")
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "failed to save benchmarks")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, benchmarksBody{AssetIDs: req.AssetIDs})
+}
+
+func (s *Server) handlePortfolioBenchmark(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	rangeName := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("range")))
+	if rangeName == "" {
+		rangeName = defaultBenchmarkRange
+	}
+	window, ok := benchmarkRanges[rangeName]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "range must be one of 1d, 7d, 30d, 90d or 1y")
+		return
+	}
+
+	benchmarkIDs, err := s.DB.ListBenchmarks(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load benchmarks")
+		return
+	}
+	lots, err := s.DB.ListLotsByUser(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load lots")
+		return
+	}
+
+	assetIDs := append([]int64(nil), benchmarkIDs...)
+	seen := make(map[int64]bool, len(benchmarkIDs)+len(lots))
+	for _, assetID := range benchmarkIDs {
+		seen[assetID] = true
+	}
+	for _, lot := range lots {
+		if !seen[lot.AssetID] {
+			seen[lot.AssetID] = true
+			assetIDs = append(assetIDs, lot.AssetID)
+		}
+	}
+
+	times := benchmarkTimes(time.Now().UTC(), window)
+	points, err := s.DB.ListPriceSeries(r.Context(), assetIDs, times)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load price history")
+		return
+	}
+	prices := pricesByTime(points, times)
+
+	assets, err := s.DB.ListAssetsByIDs(r.Context(), benchmarkIDs)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load assets")
+		return
+	}
+	assetMap := make(map[int64]db.Asset, len(assets))
+	for _, asset := range assets {
+		assetMap[asset.ID] = asset
+	}
+
+	portfolio := portfolioPerformance(times, lots, prices)
+	start := performanceStart(portfolio)
+	response := portfolioBenchmarkResponse{
+		Range:      rangeName,
+		Interval:   window.Interval,
+		Portfolio:  portfolioSeries{Points: newPerformancePoints(times, portfolio)},
+		Benchmarks: make([]benchmarkSeries, 0, len(benchmarkIDs)),
+	}
+	for _, assetID := range benchmarkIDs {
+		asset := assetMap[assetID]
+		series := prices[assetID]
+		if series == nil {
+			series = make([]sql.NullFloat64, len(times))
+		}
+		response.Benchmarks = append(response.Benchmarks, benchmarkSeries{
+			AssetID: assetID,
+			Symbol:  asset.Symbol,
+			Name:    asset.Name,
+			Points:  newPerformancePoints(times, benchmarkPerformance(series, start)),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// benchmarkTimes returns the points of window ending at now, aligned to its
+// step.
+func benchmarkTimes(now time.Time, window benchmarkRange) []time.Time {
+	end := now.Truncate(window.Step)
+	start := end.Add(-window.Span)
+	times := make([]time.Time, 0, window.Span/window.Step+1)
+	for at := start; !at.After(end); at = at.Add(window.Step) {
+		times = append(times, at)
+	}
+	return times
+}
+
+// pricesByTime lines points up with times, per asset.
+func pricesByTime(points []db.PricePoint, times []time.Time) map[int64][]sql.NullFloat64 {
+	index := make(map[int64]int, len(times))
+	for i, at := range times {
+		index[at.UnixNano()] = i
+	}
+	prices := make(map[int64][]sql.NullFloat64)
+	for _, point := range points {
+		i, ok := index[point.At.UnixNano()]
+		if !ok {
+			continue
+		}
+		series, ok := prices[point.AssetID]
+		if !ok {
+			series = make([]sql.NullFloat64, len(times))
+			prices[point.AssetID] = series
+		}
+		series[i] = point.Price
+	}
+	return prices
+}
+
+// performanceStart returns the index of the portfolio's first value, or 0
+// when it has none.
+func performanceStart(portfolio []*float64) int {
+	for i, value := range portfolio {
+		if value != nil {
+			return i
+		}
+	}
+	return 0
+}
+
+// benchmarkPerformance indexes prices to 100 at the first known price at or
+// after start, so a benchmark starts where the portfolio does; points before
+// it are nil.
+func benchmarkPerformance(prices []sql.NullFloat64, start int) []*float64 {
+	values := make([]*float64, len(prices))
+	var base float64
+	for i, price := range prices {
+		if i < start || !price.Valid || price.Float64 <= 0 {
+			continue
+		}
+		if base == 0 {
+			base = price.Float64
+		}
+		value := 100 * price.Float64 / base
+		values[i] = &value
+	}
+	return values
+}
+
+// portfolioPerformance chains the return of each period into an index that
+// starts at 100 at the first point where the user holds a priced asset. A
+// period's return values the quantities held at its start at both ends, so
+// lots bought during the period are not counted as gains. Assets without a
+// price at either end are left out of that period.
+func portfolioPerformance(times []time.Time, lots []db.Lot, prices map[int64][]sql.NullFloat64) []*float64 {
+	values := make([]*float64, len(times))
+	var index float64
+	var previous map[int64]float64
+	for i := range times {
+		quantities := make(map[int64]float64)
+		for _, lot := range lots {
+			if !lot.PurchasedAt.After(times[i]) {
+				quantities[lot.AssetID] += lot.Quantity
+			}
+		}
+
+		if index == 0 {
+			var value float64
+			for assetID, quantity := range quantities {
+				if series := prices[assetID]; series != nil && series[i].Valid {
+					value += quantity * series[i].Float64
+				}
+			}
+			if value > 0 {
+				index = 100
+			}
+		} else {
+			var start, end float64
+			for assetID, quantity := range previous {
+				series := prices[assetID]
+				if series == nil || !series[i-1].Valid || !series[i].Valid {
+					continue
+				}
+				start += quantity * series[i-1].Float64
+				end += quantity * series[i].Float64
+			}
+			if start > 0 {
+				index *= end / start
+			}
+		}
+		if index > 0 {
+			value := index
+			values[i] = &value
+		}
+		previous = quantities
+	}
+	return values
+}
+
+func newPerformancePoints(times []time.Time, values []*float64) []performancePoint {
+	points := make([]performancePoint, 0, len(times))
+	for i, at := range times {
+		points = append(points, performancePoint{At: at.Format(time.RFC3339), Value: values[i]})
+	}
+	return points
+}
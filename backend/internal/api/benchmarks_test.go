@@ -0,0 +1,157 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"asset-tracker/internal/auth"
+	"asset-tracker/internal/db"
+)
+
+func TestAPIReplaceBenchmarks(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodPut, "/api/v1/benchmarks", "good", []byte(`{"asset_ids":[1,7]}`)))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", res.Code, res.Body.String())
+	}
+	if len(store.savedBenchmarks) != 2 || store.savedBenchmarks[0] != 1 || store.savedBenchmarks[1] != 7 {
+		t.Fatalf("unexpected saved benchmarks: %v", store.savedBenchmarks)
+	}
+
+	cases := []struct {
+		body string
+		err  error
+	}{
+		{body: `{}`},
+		{body: `{"asset_ids":[0]}`},
+		{body: `{"asset_ids":[1,1]}`},
+		{body: `{"asset_ids":[1,2,3,4,5,6,7,8,9,10,11]}`},
+		{body: `{"asset_ids":[99]}`, err: db.ErrAssetNotFound},
+	}
+	for _, tc := range cases {
+		store := &mockStore{benchmarksErr: tc.err}
+		router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+		res := httptest.NewRecorder()
+
+		router.ServeHTTP(res, newRequest(t, http.MethodPut, "/api/v1/benchmarks", "good", []byte(tc.body)))
+		if res.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", tc.body, res.Code)
+		}
+	}
+}
+
+func TestAPIPortfolioBenchmark(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	store := &mockStore{
+		benchmarks: []int64{7, 8},
+		assetsByID: map[int64]db.Asset{7: {ID: 7, Symbol: "SPY", Name: "S&P 500 ETF"}},
+		lots: []db.Lot{
+			{AssetID: 1, Quantity: 2, PurchasedAt: now.AddDate(0, -1, 0)},
+		},
+		priceSeries: map[int64][]float64{
+			1: {10, 11, 12, 12, 12, 12, 12, 15},
+			7: {0, 400, 0, 0, 0, 0, 0, 440},
+		},
+	}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodGet, "/api/v1/portfolio/benchmark?range=7d", "good", nil))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", res.Code, res.Body.String())
+	}
+	var got portfolioBenchmarkResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.Range != "7d" || got.Interval != "1h" || len(store.priceSeriesTimes) != 169 {
+		t.Fatalf("unexpected window: %s %s with %d points", got.Range, got.Interval, len(store.priceSeriesTimes))
+	}
+	if len(got.Portfolio.Points) != 169 || got.Portfolio.Points[0].Value == nil || *got.Portfolio.Points[0].Value != 100 || math.Abs(*got.Portfolio.Points[1].Value-110) > 1e-9 {
+		t.Fatalf("unexpected portfolio points: %+v", got.Portfolio.Points[:2])
+	}
+	if len(got.Benchmarks) != 2 || got.Benchmarks[0].Symbol != "SPY" {
+		t.Fatalf("unexpected benchmarks: %+v", got.Benchmarks)
+	}
+	if got.Benchmarks[0].Points[0].Value != nil || *got.Benchmarks[0].Points[1].Value != 100 || math.Abs(*got.Benchmarks[0].Points[7].Value-110) > 1e-9 {
+		t.Fatalf("unexpected benchmark points: %+v", got.Benchmarks[0].Points[:8])
+	}
+	for _, point := range got.Benchmarks[1].Points {
+		if point.Value != nil {
+			t.Fatalf("expected unpriced benchmark to have no values, got %+v", point)
+		}
+	}
+
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, newRequest(t, http.MethodGet, "/api/v1/portfolio/benchmark?range=5y", "good", nil))
+	if res.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", res.Code)
+	}
+}
+
+func TestPortfolioPerformanceIgnoresNewLots(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	times := []time.Time{start, start.AddDate(0, 0, 1), start.AddDate(0, 0, 2), start.AddDate(0, 0, 3)}
+	lots := []db.Lot{
+		{AssetID: 1, Quantity: 1, PurchasedAt: start.AddDate(0, 0, 1)},
+		{AssetID: 2, Quantity: 10, PurchasedAt: start.AddDate(0, 0, 2)},
+	}
+	price := func(v float64) sql.NullFloat64 { return sql.NullFloat64{Float64: v, Valid: true} }
+	prices := map[int64][]sql.NullFloat64{
+		1: {price(100), price(100), price(120), price(120)},
+		2: {{}, price(5), price(5), price(10)},
+	}
+
+	values := portfolioPerformance(times, lots, prices)
+	if values[0] != nil {
+		t.Fatalf("expected no value before the first lot, got %v", *values[0])
+	}
+	// 120/100, then (120 + 100) / (120 + 50).
+	want := []float64{100, 120, 120.0 * 220 / 170}
+	for i, expected := range want {
+		if values[i+1] == nil || math.Abs(*values[i+1]-expected) > 1e-9 {
+			t.Fatalf("unexpected value at %d: %v, want %v", i+1, values[i+1], expected)
+		}
+	}
+}
+
+func TestBenchmarkStartsWithPortfolio(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	times := []time.Time{start, start.AddDate(0, 0, 1), start.AddDate(0, 0, 2), start.AddDate(0, 0, 3)}
+	lots := []db.Lot{{AssetID: 1, Quantity: 1, PurchasedAt: start.AddDate(0, 0, 2)}}
+	price := func(v float64) sql.NullFloat64 { return sql.NullFloat64{Float64: v, Valid: true} }
+	prices := map[int64][]sql.NullFloat64{
+		1: {price(50), price(50), price(50), price(60)},
+		7: {price(100), price(110), price(120), price(132)},
+	}
+
+	portfolio := portfolioPerformance(times, lots, prices)
+	benchmark := benchmarkPerformance(prices[7], performanceStart(portfolio))
+	for i := range 2 {
+		if portfolio[i] != nil || benchmark[i] != nil {
+			t.Fatalf("expected no values before the first lot at %d, got %v and %v", i, portfolio[i], benchmark[i])
+		}
+	}
+	if *portfolio[2] != 100 || *benchmark[2] != 100 {
+		t.Fatalf("expected both series to start at 100, got %v and %v", *portfolio[2], *benchmark[2])
+	}
+	if math.Abs(*portfolio[3]-120) > 1e-9 || math.Abs(*benchmark[3]-110) > 1e-9 {
+		t.Fatalf("unexpected last values: %v and %v", *portfolio[3], *benchmark[3])
+	}
+}
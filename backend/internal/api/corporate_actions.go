@@ -0,0 +1,229 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"asset-tracker/internal/db"
+)
+
+type corporateActionResponse struct {
+	ID             int64    `json:"id"`
+	AssetID        int64    `json:"asset_id"`
+	Kind           string   `json:"kind"`
+	Ratio          *float64 `json:"ratio"`
+	NewSymbol      *string  `json:"new_symbol"`
+	PreviousSymbol *string  `json:"previous_symbol"`
+	EffectiveAt    string   `json:"effective_at"`
+	Notes          *string  `json:"notes"`
+	CreatedAt      string   `json:"created_at"`
+	AppliedAt      *string  `json:"applied_at"`
+}
+
+type createCorporateActionRequest struct {
+	AssetID     int64    `json:"asset_id"`
+	Kind        string   `json:"kind"`
+	Ratio       *float64 `json:"ratio"`
+	NewSymbol   *string  `json:"new_symbol"`
+	EffectiveAt string   `json:"effective_at"`
+	Notes       *string  `json:"notes"`
+}
+
+type createCorporateActionResponse struct {
+	ID int64 `json:"id"`
+}
+
+type applyCorporateActionResponse struct {
+	AdjustedLots int64 `json:"adjusted_lots"`
+}
+
+func (s *Server) handleListCorporateActions(w http.ResponseWriter, r *http.Request) {
+	var assetID int64
+	if raw := strings.TrimSpace(r.URL.Query().Get("asset_id")); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, "asset_id must be a positive integer")
+			return
+		}
+		assetID = parsed
+	}
+
+	limit := 50
+	if rawLimit := strings.TrimSpace(r.URL.Query().Get("limit")); rawLimit != "" {
+		parsedLimit, err := strconv.Atoi(rawLimit)
+		if err != nil || parsedLimit <= 0 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		if parsedLimit > 200 {
+			parsedLimit = 200
+		}
+		limit = parsedLimit
+	}
+
+	actions, err := s.DB.ListCorporateActions(r.Context(), assetID, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load corporate actions")
+		return
+	}
+
+	response := make([]corporateActionResponse, 0, len(actions))
+	for _, action := range actions {
+		item := corporateActionResponse{
+			ID:          action.ID,
+			AssetID:     action.AssetID,
+			Kind:        string(action.Kind),
+			EffectiveAt: action.EffectiveAt.UTC().Format(time.RFC3339),
+			CreatedAt:   action.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		if action.Ratio.Valid {
+			item.Ratio = &action.Ratio.Float64
+		}
+		if action.NewSymbol.Valid {
+			item.NewSymbol = &action.NewSymbol.String
+		}
+		if action.PreviousSymbol.Valid {
+			item.PreviousSymbol = &action.PreviousSymbol.String
+		}
+		if action.Notes.Valid {
+			item.Notes = &action.Notes.String
+		}
+		if action.AppliedAt.Valid {
+			appliedAt := action.AppliedAt.Time.UTC().Format(time.RFC3339)
+			item.AppliedAt = &appliedAt
+		}
+		response = append(response, item)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleCreateCorporateAction(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	var req createCorporateActionRequest
+	if err := decodeJSONBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	action, message := parseCorporateAction(req)
+	if message != "" {
+		writeError(w, http.StatusBadRequest, message)
+		return
+	}
+	action.CreatedBy = userID
+
+	id, err := s.DB.InsertCorporateAction(r.Context(), action)
+	switch {
+	case errors.Is(err, db.ErrAssetNotFound):
+		writeError(w, http.StatusBadRequest, "asset_id does not reference an existing asset")
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "failed to create corporate action")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, createCorporateActionResponse{ID: id})
+}
+
+func (s *Server) handleApplyCorporateAction(w http.ResponseWriter, r *http.Request) {
+	actionID, err := parseIDParam(r, "actionID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid corporate action id")
+		return
+	}
+
+	adjusted, err := s.DB.ApplyCorporateAction(r.Context(), actionID)
+	switch {
+	case errors.Is(err, db.ErrCorporateActionNotFound):
+		writeError(w, http.StatusNotFound, "corporate action not found")
+		return
+	case errors.Is(err, db.ErrCorporateActionApplied):
+		writeError(w, http.StatusConflict, "corporate action has already been applied")
+		return
+	case errors.Is(err, db.ErrAssetSymbolTaken):
+		writeError(w, http.StatusConflict, "new_symbol is already used by another asset of this type")
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "failed to apply corporate action")
+		return
+	}
+
+	// Deleted lots are rescaled too, but their owners saw them go already.
+	for _, lot := range adjusted {
+		if lot.Deleted {
+			continue
+		}
+		s.publishWebhookEvent(r.Context(), lot.UserID, db.WebhookEventLotUpdated, lotWebhookPayload{
+			LotID:    lot.LotID,
+			Quantity: &lot.Quantity,
+			UnitCost: &lot.UnitCost,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, applyCorporateActionResponse{AdjustedLots: int64(len(adjusted))})
+}
+
+// parseCorporateAction validates req. Ratios are new units per old unit, so
+// a split must be above 1 and a reverse split below 1.
+func parseCorporateAction(req createCorporateActionRequest) (db.CorporateAction, string) {
+	if req.AssetID <= 0 {
+		return db.CorporateAction{}, "asset_id must be greater than 0"
+	}
+	effectiveAt, err := parseTimestamp(req.EffectiveAt)
+	if err != nil {
+		return db.CorporateAction{}, "effective_at must be RFC3339 or YYYY-MM-DD"
+	}
+
+	action := db.CorporateAction{
+		AssetID:     req.AssetID,
+		Kind:        db.CorporateActionKind(strings.ToLower(strings.TrimSpace(req.Kind))),
+		EffectiveAt: effectiveAt,
+	}
+	if req.Notes != nil {
+		if notes := strings.TrimSpace(*req.Notes); notes != "" {
+			action.Notes = sql.NullString{String: notes, Valid: true}
+		}
+	}
+
+	switch action.Kind {
+	case db.CorporateActionTickerChange:
+		if req.Ratio != nil {
+			return db.CorporateAction{}, "ratio is not allowed for ticker_change"
+		}
+		if req.NewSymbol == nil || strings.TrimSpace(*req.NewSymbol) == "" {
+			return db.CorporateAction{}, "new_symbol is required for ticker_change"
+		}
+		action.NewSymbol = sql.NullString{String: strings.ToUpper(strings.TrimSpace(*req.NewSymbol)), Valid: true}
+		return action, ""
+	case db.CorporateActionSplit, db.CorporateActionReverseSplit, db.CorporateActionRedenomination:
+		if req.NewSymbol != nil {
+			return db.CorporateAction{}, "new_symbol is only allowed for ticker_change"
+		}
+		if req.Ratio == nil || *req.Ratio <= 0 {
+			return db.CorporateAction{}, "ratio must be greater than 0"
+		}
+		ratio := *req.Ratio
+		switch {
+		case action.Kind == db.CorporateActionSplit && ratio <= 1:
+			return db.CorporateAction{}, "ratio must be greater than 1 for a split"
+		case action.Kind == db.CorporateActionReverseSplit && ratio >= 1:
+			return db.CorporateAction{}, "ratio must be less than 1 for a reverse_split"
+		case ratio == 1:
+			return db.CorporateAction{}, "ratio must not be 1"
+		}
+		action.Ratio = sql.NullFloat64{Float64: ratio, Valid: true}
+		return action, ""
+	default:
+		return db.CorporateAction{}, "kind must be split, reverse_split, ticker_change, or redenomination"
+	}
+}
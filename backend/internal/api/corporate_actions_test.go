@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"asset-tracker/internal/auth"
+	"asset-tracker/internal/db"
+	"github.com/go-chi/chi/v5"
+)
+
+func newAdminAPIRouter(store Store, userID string) http.Handler {
+	r := chi.NewRouter()
+	server := NewServer(store, mockVerifier{claims: auth.Claims{Subject: userID}})
+	server.AdminUserIDs = []string{"admin-1"}
+	server.Mount(r)
+	return r
+}
+
+func TestAPIAdminRoutesRequireAdmin(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	router := newAdminAPIRouter(store, "user-1")
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/admin/corporate-actions/1/apply", "good", nil))
+	if res.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", res.Code)
+	}
+	if store.appliedActionID != 0 {
+		t.Fatal("expected no store call for a non-admin")
+	}
+}
+
+func TestAPICreateCorporateActionSplit(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	router := newAdminAPIRouter(store, "admin-1")
+	res := httptest.NewRecorder()
+
+	body := []byte(`{"asset_id":3,"kind":"split","ratio":4,"effective_at":"2026-06-10","notes":"4-for-1"}`)
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/admin/corporate-actions", "good", body))
+	if res.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", res.Code, res.Body.String())
+	}
+	got := store.insertedCorporateAction
+	if got.AssetID != 3 || got.Kind != db.CorporateActionSplit || got.Ratio.Float64 != 4 || got.CreatedBy != "admin-1" {
+		t.Fatalf("unexpected inserted action: %+v", got)
+	}
+	if !got.EffectiveAt.Equal(time.Date(2026, 6, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected effective_at: %s", got.EffectiveAt)
+	}
+}
+
+func TestAPICreateCorporateActionValidation(t *testing.T) {
+	t.Parallel()
+
+	cases := []string{
+		`{"asset_id":3,"kind":"split","ratio":0.5,"effective_at":"2026-06-10"}`,
+		`{"asset_id":3,"kind":"reverse_split","ratio":10,"effective_at":"2026-06-10"}`,
+		`{"asset_id":3,"kind":"redenomination","ratio":1,"effective_at":"2026-06-10"}`,
+		`{"asset_id":3,"kind":"ticker_change","effective_at":"2026-06-10"}`,
+		`{"asset_id":3,"kind":"ticker_change","ratio":2,"new_symbol":"META","effective_at":"2026-06-10"}`,
+		`{"asset_id":3,"kind":"merger","ratio":2,"effective_at":"2026-06-10"}`,
+		`{"asset_id":3,"kind":"split","ratio":2,"effective_at":"soon"}`,
+	}
+	for _, body := range cases {
+		store := &mockStore{}
+		router := newAdminAPIRouter(store, "admin-1")
+		res := httptest.NewRecorder()
+
+		router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/admin/corporate-actions", "good", []byte(body)))
+		if res.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", body, res.Code)
+		}
+	}
+}
+
+func TestAPIApplyCorporateAction(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{adjustedLots: []db.CorporateActionAdjustment{
+		{LotID: 1, UserID: "user-1", Quantity: 20, UnitCost: 5},
+		{LotID: 2, UserID: "user-2", Quantity: 4, UnitCost: 50},
+		{LotID: 3, UserID: "user-2", Quantity: 2, UnitCost: 50, Deleted: true},
+	}}
+	router := newAdminAPIRouter(store, "admin-1")
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/admin/corporate-actions/4/apply", "good", nil))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.Code)
+	}
+	var got applyCorporateActionResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if store.appliedActionID != 4 || got.AdjustedLots != 3 {
+		t.Fatalf("unexpected apply result: action=%d response=%+v", store.appliedActionID, got)
+	}
+	if len(store.webhookEvents) != 2 || store.webhookEvents[0] != db.WebhookEventLotUpdated || store.webhookEvents[1] != db.WebhookEventLotUpdated {
+		t.Fatalf("expected lot.updated for each adjusted live lot, got %v", store.webhookEvents)
+	}
+}
+
+func TestAPIApplyCorporateActionTwice(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{applyActionErr: db.ErrCorporateActionApplied}
+	router := newAdminAPIRouter(store, "admin-1")
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/admin/corporate-actions/4/apply", "good", nil))
+	if res.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", res.Code)
+	}
+}
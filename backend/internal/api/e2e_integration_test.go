@@ -31,6 +31,14 @@ type e2eLotResponse struct {
 	UnitCost float64 `json:"unit_cost"`
 }
 
+type e2eLotPage struct {
+	Items []e2eLotResponse `json:"items"`
+}
+
+type e2eLotEvent struct {
+	Type string `json:"type"`
+}
+
 type e2ePositionResponse struct {
 	AssetID  int64   `json:"asset_id"`
 	TotalQty float64 `json:"total_qty"`
@@ -125,8 +133,9 @@ func TestAPIE2EHappyPath(t *testing.T) {
 	if lotsRes.StatusCode != http.StatusOK {
 		t.Fatalf("expected list lots status 200, got %d", lotsRes.StatusCode)
 	}
-	var lots []e2eLotResponse
-	decodeJSON(t, lotsRes, &lots)
+	var lotsPage e2eLotPage
+	decodeJSON(t, lotsRes, &lotsPage)
+	lots := lotsPage.Items
 	if len(lots) != 1 {
 		t.Fatalf("expected one lot after create, got %d", len(lots))
 	}
@@ -158,8 +167,9 @@ func TestAPIE2EHappyPath(t *testing.T) {
 	if updatedLotsRes.StatusCode != http.StatusOK {
 		t.Fatalf("expected updated list lots status 200, got %d", updatedLotsRes.StatusCode)
 	}
-	var updatedLots []e2eLotResponse
-	decodeJSON(t, updatedLotsRes, &updatedLots)
+	var updatedPage e2eLotPage
+	decodeJSON(t, updatedLotsRes, &updatedPage)
+	updatedLots := updatedPage.Items
 	if len(updatedLots) != 1 || updatedLots[0].Quantity != 3 || updatedLots[0].UnitCost != 100 {
 		t.Fatalf("unexpected updated lot payload: %+v", updatedLots)
 	}
@@ -173,10 +183,36 @@ func TestAPIE2EHappyPath(t *testing.T) {
 	if finalLotsRes.StatusCode != http.StatusOK {
 		t.Fatalf("expected final list lots status 200, got %d", finalLotsRes.StatusCode)
 	}
-	var finalLots []e2eLotResponse
-	decodeJSON(t, finalLotsRes, &finalLots)
-	if len(finalLots) != 0 {
-		t.Fatalf("expected zero lots after delete, got %d", len(finalLots))
+	var finalPage e2eLotPage
+	decodeJSON(t, finalLotsRes, &finalPage)
+	if len(finalPage.Items) != 0 {
+		t.Fatalf("expected zero lots after delete, got %d", len(finalPage.Items))
+	}
+
+	restoreRes := doRequest(t, apiServer.Client(), http.MethodPost, updateURL+"/restore", validToken, nil)
+	if restoreRes.StatusCode != http.StatusOK {
+		t.Fatalf("expected restore status 200, got %d", restoreRes.StatusCode)
+	}
+	var restored e2eLotResponse
+	decodeJSON(t, restoreRes, &restored)
+	if restored.ID != created.ID || restored.Quantity != 3 {
+		t.Fatalf("unexpected restored lot: %+v", restored)
+	}
+
+	historyRes := doRequest(t, apiServer.Client(), http.MethodGet, updateURL+"/history", validToken, nil)
+	if historyRes.StatusCode != http.StatusOK {
+		t.Fatalf("expected history status 200, got %d", historyRes.StatusCode)
+	}
+	var history []e2eLotEvent
+	decodeJSON(t, historyRes, &history)
+	wantHistory := []string{db.LotEventCreated, db.LotEventUpdated, db.LotEventDeleted, db.LotEventRestored}
+	if len(history) != len(wantHistory) {
+		t.Fatalf("expected %d history events, got %+v", len(wantHistory), history)
+	}
+	for i, want := range wantHistory {
+		if history[i].Type != want {
+			t.Fatalf("history[%d]: expected %s, got %s", i, want, history[i].Type)
+		}
 	}
 
 	if authCalls < 7 {
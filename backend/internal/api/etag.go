@@ -0,0 +1,100 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"asset-tracker/internal/db"
+)
+
+type lotPreconditionFailedResponse struct {
+	Error   string      `json:"error"`
+	Current lotResponse `json:"current"`
+}
+
+// lotETag is the strong ETag of a lot: its quoted revision.
+func lotETag(revision int64) string {
+	return strconv.Quote(strconv.FormatInt(revision, 10))
+}
+
+// ifMatchRevision returns the lot revision required by If-Match: 0 when the
+// header is absent or "*", and -1, which matches nothing, when no tag in it
+// is a lot revision. If-Match uses strong comparison, so weak tags never
+// match.
+func ifMatchRevision(r *http.Request) int64 {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return 0
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return 0
+		}
+		if strings.HasPrefix(tag, "W/") {
+			continue
+		}
+		unquoted, err := strconv.Unquote(tag)
+		if err != nil {
+			continue
+		}
+		if revision, err := strconv.ParseInt(unquoted, 10, 64); err == nil && revision > 0 {
+			return revision
+		}
+	}
+	return -1
+}
+
+func (s *Server) writeLotPreconditionFailed(w http.ResponseWriter, r *http.Request, current db.Lot) {
+	items, err := s.lotResponses(r.Context(), []db.Lot{current})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load assets")
+		return
+	}
+	w.Header().Set("ETag", lotETag(current.Revision))
+	writeJSON(w, http.StatusPreconditionFailed, lotPreconditionFailedResponse{
+		Error:   "lot has been modified",
+		Current: items[0],
+	})
+}
+
+// writeJSONWithETag writes payload with a weak ETag derived from its encoding
+// and answers 304 when the request's If-None-Match already names it.
+func writeJSONWithETag(w http.ResponseWriter, r *http.Request, statusCode int, payload any) {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(payload); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to encode response")
+		return
+	}
+
+	sum := sha256.Sum256(body.Bytes())
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+
+	if etagListMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(body.Bytes())
+}
+
+// etagListMatches applies the weak comparison used by If-None-Match.
+func etagListMatches(header string, etag string) bool {
+	want := strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"asset-tracker/internal/auth"
+	"asset-tracker/internal/db"
+)
+
+func TestAPIUpdateLotIfMatchConflict(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{updatedFound: true, lotRevision: 5}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	req := newRequest(t, http.MethodPatch, "/api/v1/lots/7", "good", []byte(`{"quantity":2,"unit_cost":10,"purchased_at":"2026-02-16"}`))
+	req.Header.Set("If-Match", `"4"`)
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412, got %d", res.Code)
+	}
+	if store.expectedRevision != 4 {
+		t.Fatalf("expected revision 4 to be checked, got %d", store.expectedRevision)
+	}
+	if got := res.Header().Get("ETag"); got != `"5"` {
+		t.Fatalf("expected current ETag \"5\", got %q", got)
+	}
+	var got lotPreconditionFailedResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.Current.ID != 7 || got.Current.Revision != 5 {
+		t.Fatalf("expected current representation, got %+v", got.Current)
+	}
+}
+
+func TestAPIUpdateLotIfMatchSuccessReturnsNewETag(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{updatedFound: true, lotRevision: 5}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	req := newRequest(t, http.MethodPatch, "/api/v1/lots/7", "good", []byte(`{"quantity":2,"unit_cost":10,"purchased_at":"2026-02-16"}`))
+	req.Header.Set("If-Match", `"5"`)
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", res.Code)
+	}
+	if got := res.Header().Get("ETag"); got != `"6"` {
+		t.Fatalf("expected new ETag \"6\", got %q", got)
+	}
+}
+
+func TestAPIUpdateLotWeakIfMatchFails(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{updatedFound: true, lotRevision: 5}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	req := newRequest(t, http.MethodPatch, "/api/v1/lots/7", "good", []byte(`{"quantity":2,"unit_cost":10,"purchased_at":"2026-02-16"}`))
+	req.Header.Set("If-Match", `W/"5"`)
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 for a weak If-Match, got %d", res.Code)
+	}
+	if store.expectedRevision != -1 {
+		t.Fatalf("expected no revision to match, got %d", store.expectedRevision)
+	}
+}
+
+func TestAPIDeleteLotMalformedIfMatchFails(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{deletedFound: true, lotRevision: 2}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	req := newRequest(t, http.MethodDelete, "/api/v1/lots/7", "good", nil)
+	req.Header.Set("If-Match", `"abc"`)
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412, got %d", res.Code)
+	}
+}
+
+func TestAPIListPositionsIfNoneMatch(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{
+		positions:  []db.Position{{AssetID: 10, TotalQty: 1, AvgCost: 100}},
+		assetsByID: map[int64]db.Asset{10: {ID: 10, Symbol: "BTC", Name: "Bitcoin", Type: db.AssetTypeCrypto}},
+	}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, newRequest(t, http.MethodGet, "/api/v1/positions", "good", nil))
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with ETag, got %d %q", first.Code, etag)
+	}
+
+	req := newRequest(t, http.MethodGet, "/api/v1/positions", "good", nil)
+	req.Header.Set("If-None-Match", etag)
+	second := httptest.NewRecorder()
+	router.ServeHTTP(second, req)
+	if second.Code != http.StatusNotModified || second.Body.Len() != 0 {
+		t.Fatalf("expected empty 304, got %d with %d bytes", second.Code, second.Body.Len())
+	}
+
+	store.positions[0].TotalQty = 2
+	req = newRequest(t, http.MethodGet, "/api/v1/positions", "good", nil)
+	req.Header.Set("If-None-Match", etag)
+	third := httptest.NewRecorder()
+	router.ServeHTTP(third, req)
+	if third.Code != http.StatusOK {
+		t.Fatalf("expected 200 after change, got %d", third.Code)
+	}
+}
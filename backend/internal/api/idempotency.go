@@ -0,0 +1,169 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"asset-tracker/internal/db"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	idempotencyKeyTTL         = 24 * time.Hour
+	idempotencyStaleAfter     = time.Minute
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentBodyBytes    = 1 << 20
+)
+
+// idempotentReplayHeaders are the response headers stored and replayed with
+// the body, besides Content-Type: a replayed update still tells the client
+// the lot's new revision.
+var idempotentReplayHeaders = []string{"ETag", "Location"}
+
+// idempotencyMiddleware makes POST, PATCH and DELETE requests that carry an
+// Idempotency-Key header safe to retry. The first response for a key is
+// stored for idempotencyKeyTTL and replayed verbatim, with the headers in
+// idempotentReplayHeaders; a retry with a
+// different method, path or body is rejected with 422, and a retry that
+// arrives while the original is still running gets 409. Server errors are not
+// stored so the client can retry them.
+func (s *Server) idempotencyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimSpace(r.Header.Get(idempotencyKeyHeader))
+		if key == "" || !isIdempotentMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			writeError(w, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		userID := userIDFromContext(r.Context())
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user context")
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBodyBytes+1))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if len(body) > maxIdempotentBodyBytes {
+			writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		stored, reserved, err := s.DB.ReserveIdempotencyKey(r.Context(), db.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			Method:      r.Method,
+			Path:        r.URL.Path,
+			RequestHash: hashIdempotentRequest(r.Method, r.URL.Path, body),
+		}, idempotencyKeyTTL, idempotencyStaleAfter)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to check idempotency key")
+			return
+		}
+
+		if !reserved {
+			switch {
+			case stored.RequestHash != hashIdempotentRequest(r.Method, r.URL.Path, body):
+				writeError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+			case !stored.StatusCode.Valid:
+				writeError(w, http.StatusConflict, "a request with this Idempotency-Key is still in progress")
+			default:
+				replayIdempotentResponse(w, stored)
+			}
+			return
+		}
+
+		recorder := &idempotencyRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+
+		// The client may have gone away; the outcome must still be recorded.
+		ctx := context.WithoutCancel(r.Context())
+		status := recorder.statusCode()
+		if status >= http.StatusInternalServerError {
+			if err := s.DB.ReleaseIdempotencyKey(ctx, userID, key); err != nil {
+				slog.Warn("failed to release idempotency key", "user_id", userID, "error", err)
+			}
+			return
+		}
+		headers := make(map[string]string, len(idempotentReplayHeaders))
+		for _, name := range idempotentReplayHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+		if err := s.DB.CompleteIdempotencyKey(ctx, userID, key, status, recorder.Header().Get("Content-Type"), headers, recorder.body.Bytes()); err != nil {
+			slog.Warn("failed to store idempotent response", "user_id", userID, "error", err)
+		}
+	})
+}
+
+func isIdempotentMethod(method string) bool {
+	return method == http.MethodPost || method == http.MethodPatch || method == http.MethodDelete
+}
+
+func hashIdempotentRequest(method, path string, body []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(method))
+	sum.Write([]byte{0})
+	sum.Write([]byte(path))
+	sum.Write([]byte{0})
+	sum.Write(body)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+func replayIdempotentResponse(w http.ResponseWriter, stored db.IdempotencyKey) {
+	if stored.ResponseContentType.Valid {
+		w.Header().Set("Content-Type", stored.ResponseContentType.String)
+	}
+	for _, name := range idempotentReplayHeaders {
+		if value, ok := stored.ResponseHeaders[name]; ok {
+			w.Header().Set(name, value)
+		}
+	}
+	w.Header().Set(idempotencyReplayedHeader, "true")
+	w.WriteHeader(int(stored.StatusCode.Int32))
+	_, _ = w.Write(stored.ResponseBody)
+}
+
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *idempotencyRecorder) WriteHeader(statusCode int) {
+	if r.status == 0 {
+		r.status = statusCode
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *idempotencyRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *idempotencyRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
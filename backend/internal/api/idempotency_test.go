@@ -0,0 +1,121 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"asset-tracker/internal/auth"
+	"asset-tracker/internal/db"
+)
+
+func newIdempotentRequest(t *testing.T, method, path, key string, body []byte) *http.Request {
+	t.Helper()
+	req := newRequest(t, method, path, "good", body)
+	req.Header.Set(idempotencyKeyHeader, key)
+	return req
+}
+
+func TestAPIIdempotencyReplaysCreateLot(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{insertLotID: 42}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	body := []byte(`{"asset_id":1,"quantity":0.25,"unit_cost":38000,"purchased_at":"2026-02-16"}`)
+
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, newIdempotentRequest(t, http.MethodPost, "/api/v1/lots", "retry-1", body))
+	second := httptest.NewRecorder()
+	router.ServeHTTP(second, newIdempotentRequest(t, http.MethodPost, "/api/v1/lots", "retry-1", body))
+
+	if first.Code != http.StatusCreated || second.Code != http.StatusCreated {
+		t.Fatalf("expected 201 twice, got %d and %d", first.Code, second.Code)
+	}
+	if len(store.insertedLots) != 1 {
+		t.Fatalf("expected a single insert, got %d", len(store.insertedLots))
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Fatalf("expected replayed body %q, got %q", first.Body.String(), second.Body.String())
+	}
+	if second.Header().Get(idempotencyReplayedHeader) != "true" {
+		t.Fatal("expected replay header on second response")
+	}
+}
+
+func TestAPIIdempotencyReplaysETag(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{updatedFound: true, lotRevision: 5}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	body := []byte(`{"quantity":2}`)
+
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, newIdempotentRequest(t, http.MethodPatch, "/api/v1/lots/7", "patch-1", body))
+	second := httptest.NewRecorder()
+	router.ServeHTTP(second, newIdempotentRequest(t, http.MethodPatch, "/api/v1/lots/7", "patch-1", body))
+
+	if first.Code != http.StatusNoContent || second.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 twice, got %d and %d", first.Code, second.Code)
+	}
+	if second.Header().Get(idempotencyReplayedHeader) != "true" {
+		t.Fatal("expected replay header on second response")
+	}
+	if etag := first.Header().Get("ETag"); etag != `"6"` || second.Header().Get("ETag") != etag {
+		t.Fatalf("expected replayed ETag %q, got %q", etag, second.Header().Get("ETag"))
+	}
+}
+
+func TestAPIIdempotencyRejectsDifferentBody(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, newIdempotentRequest(t, http.MethodPost, "/api/v1/lots", "k", []byte(`{"asset_id":1,"quantity":1,"unit_cost":1,"purchased_at":"2026-02-16"}`)))
+	second := httptest.NewRecorder()
+	router.ServeHTTP(second, newIdempotentRequest(t, http.MethodPost, "/api/v1/lots", "k", []byte(`{"asset_id":1,"quantity":2,"unit_cost":1,"purchased_at":"2026-02-16"}`)))
+
+	if second.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", second.Code)
+	}
+	if len(store.insertedLots) != 1 {
+		t.Fatalf("expected a single insert, got %d", len(store.insertedLots))
+	}
+}
+
+func TestAPIIdempotencyInProgressConflict(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{idempotencyKeys: map[string]db.IdempotencyKey{
+		"user-1/k": {UserID: "user-1", Key: "k", RequestHash: hashIdempotentRequest(http.MethodDelete, "/api/v1/lots/3", nil)},
+	}}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newIdempotentRequest(t, http.MethodDelete, "/api/v1/lots/3", "k", nil))
+	if res.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", res.Code)
+	}
+	if store.deletedLotID != 0 {
+		t.Fatal("expected delete not to run")
+	}
+}
+
+func TestAPIIdempotencyReleasesKeyOnServerError(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{insertLotErr: errors.New("boom")}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	body := []byte(`{"asset_id":1,"quantity":1,"unit_cost":1,"purchased_at":"2026-02-16"}`)
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, newIdempotentRequest(t, http.MethodPost, "/api/v1/lots", "k", body))
+	if res.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", res.Code)
+	}
+	if _, ok := store.idempotencyKeys["user-1/k"]; ok {
+		t.Fatal("expected key to be released after a server error")
+	}
+}
@@ -0,0 +1,334 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"asset-tracker/internal/db"
+)
+
+const (
+	defaultIncomeCurrency = "USD"
+
+	reinvestCostBasisZero      = "zero"
+	reinvestCostBasisFairValue = "fair_value"
+)
+
+var incomeCurrencyPattern = regexp.MustCompile(`^[A-Z0-9]{2,10}$`)
+
+type incomeResponse struct {
+	ID         int64   `json:"id"`
+	AssetID    int64   `json:"asset_id"`
+	Kind       string  `json:"kind"`
+	Amount     float64 `json:"amount"`
+	Currency   string  `json:"currency"`
+	ReceivedAt string  `json:"received_at"`
+	LotID      *int64  `json:"lot_id"`
+	Notes      *string `json:"notes"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+type reinvestRequest struct {
+	Quantity  float64 `json:"quantity"`
+	CostBasis string  `json:"cost_basis"`
+}
+
+type createIncomeRequest struct {
+	AssetID    int64            `json:"asset_id"`
+	Kind       string           `json:"kind"`
+	Amount     float64          `json:"amount"`
+	Currency   string           `json:"currency"`
+	ReceivedAt string           `json:"received_at"`
+	Notes      *string          `json:"notes"`
+	Reinvest   *reinvestRequest `json:"reinvest"`
+}
+
+type createIncomeResponse struct {
+	ID    int64  `json:"id"`
+	LotID *int64 `json:"lot_id"`
+}
+
+type updateIncomeRequest struct {
+	Kind       *string  `json:"kind"`
+	Amount     *float64 `json:"amount"`
+	Currency   *string  `json:"currency"`
+	ReceivedAt *string  `json:"received_at"`
+	Notes      *string  `json:"notes"`
+}
+
+func (s *Server) handleListIncome(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	var assetID int64
+	if raw := strings.TrimSpace(r.URL.Query().Get("asset_id")); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, "asset_id must be a positive integer")
+			return
+		}
+		assetID = parsed
+	}
+
+	limit := 50
+	if rawLimit := strings.TrimSpace(r.URL.Query().Get("limit")); rawLimit != "" {
+		parsedLimit, err := strconv.Atoi(rawLimit)
+		if err != nil || parsedLimit <= 0 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		if parsedLimit > 200 {
+			parsedLimit = 200
+		}
+		limit = parsedLimit
+	}
+
+	records, err := s.DB.ListIncomeForUser(r.Context(), userID, assetID, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load income")
+		return
+	}
+
+	response := make([]incomeResponse, 0, len(records))
+	for _, record := range records {
+		item := incomeResponse{
+			ID:         record.ID,
+			AssetID:    record.AssetID,
+			Kind:       string(record.Kind),
+			Amount:     record.Amount,
+			Currency:   record.Currency,
+			ReceivedAt: record.ReceivedAt.UTC().Format(time.RFC3339),
+			CreatedAt:  record.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		if record.LotID.Valid {
+			item.LotID = &record.LotID.Int64
+		}
+		if record.Notes.Valid {
+			item.Notes = &record.Notes.String
+		}
+		response = append(response, item)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleCreateIncome(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	var req createIncomeRequest
+	if err := decodeJSONBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	income, reinvest, message := parseIncome(req)
+	if message != "" {
+		writeError(w, http.StatusBadRequest, message)
+		return
+	}
+	income.UserID = userID
+
+	stored, err := s.DB.InsertIncome(r.Context(), income, reinvest)
+	switch {
+	case errors.Is(err, db.ErrAssetNotFound):
+		writeError(w, http.StatusBadRequest, "asset_id must reference an existing asset")
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "failed to create income")
+		return
+	}
+
+	response := createIncomeResponse{ID: stored.ID}
+	if stored.LotID.Valid {
+		response.LotID = &stored.LotID.Int64
+		s.publishWebhookEvent(r.Context(), userID, db.WebhookEventLotCreated, lotWebhookPayload{
+			LotID:       stored.LotID.Int64,
+			AssetID:     stored.AssetID,
+			Quantity:    &reinvest.Quantity,
+			UnitCost:    &reinvest.UnitCost,
+			PurchasedAt: stored.ReceivedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	writeJSON(w, http.StatusCreated, response)
+}
+
+func (s *Server) handleUpdateIncome(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	incomeID, err := parseIDParam(r, "incomeID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid income id")
+		return
+	}
+
+	var req updateIncomeRequest
+	if err := decodeJSONBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var patch db.IncomePatch
+	if req.Kind != nil {
+		kind, ok := normalizeIncomeKind(*req.Kind)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "kind must be dividend, staking_reward, interest, or airdrop")
+			return
+		}
+		patch.Kind = &kind
+	}
+	if req.Amount != nil {
+		if *req.Amount <= 0 {
+			writeError(w, http.StatusBadRequest, "amount must be greater than 0")
+			return
+		}
+		patch.Amount = req.Amount
+	}
+	if req.Currency != nil {
+		currency, ok := normalizeIncomeCurrency(*req.Currency)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "currency must be 2 to 10 letters or digits")
+			return
+		}
+		patch.Currency = &currency
+	}
+	if req.ReceivedAt != nil {
+		receivedAt, err := parseTimestamp(*req.ReceivedAt)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "received_at must be RFC3339 or YYYY-MM-DD")
+			return
+		}
+		patch.ReceivedAt = &receivedAt
+	}
+	if req.Notes != nil {
+		notes := strings.TrimSpace(*req.Notes)
+		patch.Notes = &notes
+	}
+
+	updated, err := s.DB.UpdateIncomeForUser(r.Context(), userID, incomeID, patch)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update income")
+		return
+	}
+	if !updated {
+		writeError(w, http.StatusNotFound, "income not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleDeleteIncome(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	incomeID, err := parseIDParam(r, "incomeID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid income id")
+		return
+	}
+
+	deleted, err := s.DB.DeleteIncomeForUser(r.Context(), userID, incomeID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete income")
+		return
+	}
+	if !deleted {
+		writeError(w, http.StatusNotFound, "income not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseIncome validates req. A reinvested zero-cost lot has a unit cost of 0;
+// a fair-value lot costs the amount received, which must then be in USD.
+func parseIncome(req createIncomeRequest) (db.Income, *db.Lot, string) {
+	if req.AssetID <= 0 {
+		return db.Income{}, nil, "asset_id must be greater than 0"
+	}
+	kind, ok := normalizeIncomeKind(req.Kind)
+	if !ok {
+		return db.Income{}, nil, "kind must be dividend, staking_reward, interest, or airdrop"
+	}
+	if req.Amount <= 0 {
+		return db.Income{}, nil, "amount must be greater than 0"
+	}
+	currency := defaultIncomeCurrency
+	if strings.TrimSpace(req.Currency) != "" {
+		currency, ok = normalizeIncomeCurrency(req.Currency)
+		if !ok {
+			return db.Income{}, nil, "currency must be 2 to 10 letters or digits"
+		}
+	}
+	receivedAt, err := parseTimestamp(req.ReceivedAt)
+	if err != nil {
+		return db.Income{}, nil, "received_at must be RFC3339 or YYYY-MM-DD"
+	}
+
+	income := db.Income{
+		AssetID:    req.AssetID,
+		Kind:       kind,
+		Amount:     req.Amount,
+		Currency:   currency,
+		ReceivedAt: receivedAt,
+	}
+	if req.Notes != nil {
+		if notes := strings.TrimSpace(*req.Notes); notes != "" {
+			income.Notes = sql.NullString{String: notes, Valid: true}
+		}
+	}
+	if req.Reinvest == nil {
+		return income, nil, ""
+	}
+
+	if req.Reinvest.Quantity <= 0 {
+		return db.Income{}, nil, "reinvest.quantity must be greater than 0"
+	}
+	reinvest := &db.Lot{Quantity: req.Reinvest.Quantity}
+	switch strings.ToLower(strings.TrimSpace(req.Reinvest.CostBasis)) {
+	case reinvestCostBasisZero:
+	case reinvestCostBasisFairValue:
+		if currency != defaultIncomeCurrency {
+			return db.Income{}, nil, "reinvest.cost_basis fair_value requires a USD amount"
+		}
+		reinvest.UnitCost = req.Amount / req.Reinvest.Quantity
+	default:
+		return db.Income{}, nil, "reinvest.cost_basis must be zero or fair_value"
+	}
+	return income, reinvest, ""
+}
+
+func normalizeIncomeKind(value string) (db.IncomeKind, bool) {
+	kind := db.IncomeKind(strings.ToLower(strings.TrimSpace(value)))
+	switch kind {
+	case db.IncomeKindDividend, db.IncomeKindStakingReward, db.IncomeKindInterest, db.IncomeKindAirdrop:
+		return kind, true
+	default:
+		return "", false
+	}
+}
+
+func normalizeIncomeCurrency(value string) (string, bool) {
+	currency := strings.ToUpper(strings.TrimSpace(value))
+	return currency, incomeCurrencyPattern.MatchString(currency)
+}
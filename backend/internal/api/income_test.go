@@ -0,0 +1,171 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"asset-tracker/internal/auth"
+	"asset-tracker/internal/db"
+)
+
+func TestAPIListIncome(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{incomeRecords: []db.Income{
+		{ID: 1, AssetID: 3, Kind: db.IncomeKindDividend, Amount: 4.2, Currency: "USD", ReceivedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, AssetID: 5, Kind: db.IncomeKindStakingReward, Amount: 0.01, Currency: "ETH", LotID: sql.NullInt64{Int64: 8, Valid: true}},
+	}}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodGet, "/api/v1/income?asset_id=3", "good", nil))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.Code)
+	}
+	var got []incomeResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got) != 2 || got[0].Kind != "dividend" || got[0].LotID != nil || got[1].LotID == nil || *got[1].LotID != 8 {
+		t.Fatalf("unexpected income: %+v", got)
+	}
+}
+
+func TestAPICreateIncome(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	body := []byte(`{"asset_id":3,"kind":"Dividend","amount":4.2,"received_at":"2026-03-01","notes":" Q1 "}`)
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/income", "good", body))
+	if res.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", res.Code, res.Body.String())
+	}
+	inserted := store.insertedIncome
+	if inserted.UserID != "user-1" || inserted.Kind != db.IncomeKindDividend || inserted.Currency != "USD" || inserted.Notes.String != "Q1" {
+		t.Fatalf("unexpected inserted income: %+v", inserted)
+	}
+	if store.reinvestedLot != nil {
+		t.Fatalf("expected no reinvested lot, got %+v", store.reinvestedLot)
+	}
+	if len(store.webhookEvents) != 0 {
+		t.Fatalf("expected no webhook events, got %v", store.webhookEvents)
+	}
+}
+
+func TestAPICreateIncomeReinvested(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		body     string
+		unitCost float64
+	}{
+		{body: `{"asset_id":5,"kind":"staking_reward","amount":0.01,"currency":"eth","received_at":"2026-03-01","reinvest":{"quantity":0.01,"cost_basis":"zero"}}`, unitCost: 0},
+		{body: `{"asset_id":5,"kind":"staking_reward","amount":30,"received_at":"2026-03-01","reinvest":{"quantity":0.01,"cost_basis":"fair_value"}}`, unitCost: 3000},
+	}
+
+	for _, tc := range cases {
+		store := &mockStore{}
+		router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+		res := httptest.NewRecorder()
+
+		router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/income", "good", []byte(tc.body)))
+		if res.Code != http.StatusCreated {
+			t.Fatalf("expected 201 for %s, got %d: %s", tc.body, res.Code, res.Body.String())
+		}
+		var got createIncomeResponse
+		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if got.ID != 21 || got.LotID == nil || *got.LotID != 100 {
+			t.Fatalf("unexpected response: %+v", got)
+		}
+		if store.reinvestedLot == nil || store.reinvestedLot.Quantity != 0.01 || store.reinvestedLot.UnitCost != tc.unitCost {
+			t.Fatalf("unexpected reinvested lot for %s: %+v", tc.body, store.reinvestedLot)
+		}
+		if len(store.webhookEvents) != 1 || store.webhookEvents[0] != db.WebhookEventLotCreated {
+			t.Fatalf("expected lot.created webhook event, got %v", store.webhookEvents)
+		}
+	}
+}
+
+func TestAPICreateIncomeErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		body string
+		err  error
+		code int
+	}{
+		{body: `{"asset_id":3,"kind":"bonus","amount":1,"received_at":"2026-03-01"}`, code: http.StatusBadRequest},
+		{body: `{"asset_id":3,"kind":"interest","amount":0,"received_at":"2026-03-01"}`, code: http.StatusBadRequest},
+		{body: `{"asset_id":3,"kind":"interest","amount":1,"currency":"US-D","received_at":"2026-03-01"}`, code: http.StatusBadRequest},
+		{body: `{"asset_id":3,"kind":"interest","amount":1,"received_at":"yesterday"}`, code: http.StatusBadRequest},
+		{body: `{"asset_id":3,"kind":"airdrop","amount":1,"currency":"ETH","received_at":"2026-03-01","reinvest":{"quantity":1,"cost_basis":"fair_value"}}`, code: http.StatusBadRequest},
+		{body: `{"asset_id":3,"kind":"airdrop","amount":1,"received_at":"2026-03-01","reinvest":{"quantity":1,"cost_basis":"market"}}`, code: http.StatusBadRequest},
+		{body: `{"asset_id":3,"kind":"airdrop","amount":1,"received_at":"2026-03-01","reinvest":{"quantity":0,"cost_basis":"zero"}}`, code: http.StatusBadRequest},
+		{body: `{"asset_id":99,"kind":"dividend","amount":1,"received_at":"2026-03-01"}`, err: db.ErrAssetNotFound, code: http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		store := &mockStore{insertIncomeErr: tc.err}
+		router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+		res := httptest.NewRecorder()
+
+		router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/income", "good", []byte(tc.body)))
+		if res.Code != tc.code {
+			t.Fatalf("expected %d for %s, got %d", tc.code, tc.body, res.Code)
+		}
+	}
+}
+
+func TestAPIUpdateIncome(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{incomeFound: true}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	body := []byte(`{"amount":5,"currency":"eur","notes":""}`)
+	router.ServeHTTP(res, newRequest(t, http.MethodPatch, "/api/v1/income/21", "good", body))
+	if res.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", res.Code, res.Body.String())
+	}
+	patch := store.incomePatch
+	if patch.Amount == nil || *patch.Amount != 5 || patch.Currency == nil || *patch.Currency != "EUR" || patch.Notes == nil || *patch.Notes != "" || patch.Kind != nil {
+		t.Fatalf("unexpected patch: %+v", patch)
+	}
+
+	store.incomeFound = false
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, newRequest(t, http.MethodPatch, "/api/v1/income/22", "good", body))
+	if res.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", res.Code)
+	}
+}
+
+func TestAPIDeleteIncome(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{incomeFound: true}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodDelete, "/api/v1/income/21", "good", nil))
+	if res.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", res.Code)
+	}
+
+	store.incomeFound = false
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, newRequest(t, http.MethodDelete, "/api/v1/income/21", "good", nil))
+	if res.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", res.Code)
+	}
+}
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"asset-tracker/internal/db"
+)
+
+const maxLotBatchOperations = 500
+
+const (
+	lotBatchStatusRolledBack = "rolled_back"
+	lotBatchStatusFailed     = "failed"
+	lotBatchStatusSkipped    = "skipped"
+)
+
+type lotBatchRequest struct {
+	Operations []lotBatchOperation `json:"operations"`
+}
+
+// lotBatchOperation fields are pointers so update operations, like PATCH
+// /lots/{lotID}, change only the fields they carry.
+type lotBatchOperation struct {
+	Op          string   `json:"op"`
+	ID          int64    `json:"id"`
+	AssetID     *int64   `json:"asset_id"`
+	Quantity    *float64 `json:"quantity"`
+	UnitCost    *float64 `json:"unit_cost"`
+	PurchasedAt *string  `json:"purchased_at"`
+	Location    *string  `json:"location"`
+	Revision    int64    `json:"revision"`
+}
+
+type lotBatchResponse struct {
+	Committed   bool             `json:"committed"`
+	FailedIndex *int             `json:"failed_index"`
+	Error       string           `json:"error,omitempty"`
+	Results     []lotBatchResult `json:"results"`
+}
+
+type lotBatchResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	Status string `json:"status"`
+	ID     int64  `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// handleLotBatch applies a list of lot creates, updates and deletes in a
+// single transaction. Any failing operation rolls back the whole batch and is
+// reported by index with status 422, or 412 when an operation's revision is
+// stale.
+func (s *Server) handleLotBatch(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	var req lotBatchRequest
+	if err := decodeJSONBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if len(req.Operations) == 0 {
+		writeError(w, http.StatusBadRequest, "operations must not be empty")
+		return
+	}
+	if len(req.Operations) > maxLotBatchOperations {
+		writeError(w, http.StatusBadRequest, "operations must contain at most 500 entries")
+		return
+	}
+
+	ops := make([]db.LotOperation, 0, len(req.Operations))
+	for i, item := range req.Operations {
+		op, message := parseLotBatchOperation(userID, item)
+		if message != "" {
+			writeJSON(w, http.StatusUnprocessableEntity, failedLotBatchResponse(req.Operations, i, message))
+			return
+		}
+		ops = append(ops, op)
+	}
+
+	results, err := s.DB.ApplyLotBatch(r.Context(), userID, ops)
+	if err != nil {
+		var batchErr *db.LotBatchError
+		if !errors.As(err, &batchErr) {
+			writeError(w, http.StatusInternalServerError, "failed to apply lot batch")
+			return
+		}
+		switch {
+		case errors.Is(batchErr.Err, db.ErrLotNotFound):
+			writeJSON(w, http.StatusUnprocessableEntity, failedLotBatchResponse(req.Operations, batchErr.Index, "lot not found"))
+		case errors.Is(batchErr.Err, db.ErrLotRevisionMismatch):
+			writeJSON(w, http.StatusPreconditionFailed, failedLotBatchResponse(req.Operations, batchErr.Index, "lot has been modified"))
+		case errors.Is(batchErr.Err, db.ErrAssetNotFound):
+			writeJSON(w, http.StatusUnprocessableEntity, failedLotBatchResponse(req.Operations, batchErr.Index, "asset not found"))
+		default:
+			writeJSON(w, http.StatusInternalServerError, failedLotBatchResponse(req.Operations, batchErr.Index, "failed to apply operation"))
+		}
+		return
+	}
+
+	response := lotBatchResponse{Committed: true, Results: make([]lotBatchResult, 0, len(results))}
+	for i, result := range results {
+		response.Results = append(response.Results, lotBatchResult{
+			Index:  i,
+			Op:     string(result.Kind),
+			Status: lotBatchSuccessStatus(result.Kind),
+			ID:     result.LotID,
+		})
+		s.publishLotBatchEvent(r, userID, ops[i], result)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func parseLotBatchOperation(userID string, item lotBatchOperation) (db.LotOperation, string) {
+	kind := db.LotOperationKind(item.Op)
+	if item.Revision < 0 {
+		return db.LotOperation{}, "revision must be greater than 0"
+	}
+
+	switch kind {
+	case db.LotOperationCreate:
+		if item.ID != 0 || item.Revision != 0 {
+			return db.LotOperation{}, "id and revision must not be set for create"
+		}
+		if item.AssetID == nil || item.Quantity == nil || item.UnitCost == nil || item.PurchasedAt == nil {
+			return db.LotOperation{}, "create requires asset_id, quantity, unit_cost, and purchased_at"
+		}
+		patch, message := parseLotPatch(item.AssetID, item.Quantity, item.UnitCost, item.PurchasedAt)
+		if message != "" {
+			return db.LotOperation{}, message
+		}
+		var location sql.NullString
+		if item.Location != nil {
+			value, ok := normalizeLocation(*item.Location)
+			if !ok {
+				return db.LotOperation{}, "location must be 1 to 100 characters"
+			}
+			location = sql.NullString{String: value, Valid: true}
+		}
+		return db.LotOperation{Kind: kind, Lot: db.Lot{
+			UserID:      userID,
+			AssetID:     *patch.AssetID,
+			Quantity:    *patch.Quantity,
+			UnitCost:    *patch.UnitCost,
+			PurchasedAt: *patch.PurchasedAt,
+			Location:    location,
+		}}, ""
+	case db.LotOperationUpdate:
+		if item.ID <= 0 {
+			return db.LotOperation{}, "id must be greater than 0"
+		}
+		if item.AssetID == nil && item.Quantity == nil && item.UnitCost == nil && item.PurchasedAt == nil && item.Location == nil {
+			return db.LotOperation{}, "update must set at least one field"
+		}
+		patch, message := parseLotPatch(item.AssetID, item.Quantity, item.UnitCost, item.PurchasedAt)
+		if message != "" {
+			return db.LotOperation{}, message
+		}
+		if item.Location != nil {
+			// As with PATCH /lots/{lotID}, an empty location clears the label.
+			location, ok := normalizeLocation(*item.Location)
+			if !ok && location != "" {
+				return db.LotOperation{}, "location must be 1 to 100 characters"
+			}
+			patch.Location = &location
+		}
+		return db.LotOperation{Kind: kind, Lot: db.Lot{ID: item.ID, UserID: userID, Revision: item.Revision}, Patch: patch}, ""
+	case db.LotOperationDelete:
+		if item.ID <= 0 {
+			return db.LotOperation{}, "id must be greater than 0"
+		}
+		return db.LotOperation{Kind: kind, Lot: db.Lot{ID: item.ID, UserID: userID, Revision: item.Revision}}, ""
+	default:
+		return db.LotOperation{}, "op must be create, update, or delete"
+	}
+}
+
+func failedLotBatchResponse(operations []lotBatchOperation, failedIndex int, message string) lotBatchResponse {
+	response := lotBatchResponse{
+		FailedIndex: &failedIndex,
+		Error:       message,
+		Results:     make([]lotBatchResult, 0, len(operations)),
+	}
+	for i, op := range operations {
+		result := lotBatchResult{Index: i, Op: op.Op, ID: op.ID}
+		switch {
+		case i < failedIndex:
+			result.Status = lotBatchStatusRolledBack
+		case i == failedIndex:
+			result.Status = lotBatchStatusFailed
+			result.Error = message
+		default:
+			result.Status = lotBatchStatusSkipped
+		}
+		response.Results = append(response.Results, result)
+	}
+	return response
+}
+
+func lotBatchSuccessStatus(kind db.LotOperationKind) string {
+	switch kind {
+	case db.LotOperationCreate:
+		return "created"
+	case db.LotOperationUpdate:
+		return "updated"
+	default:
+		return "deleted"
+	}
+}
+
+func (s *Server) publishLotBatchEvent(r *http.Request, userID string, op db.LotOperation, result db.LotOperationResult) {
+	switch op.Kind {
+	case db.LotOperationCreate:
+		s.publishWebhookEvent(r.Context(), userID, db.WebhookEventLotCreated, lotWebhookPayload{
+			LotID:       result.LotID,
+			AssetID:     op.Lot.AssetID,
+			Quantity:    &op.Lot.Quantity,
+			UnitCost:    &op.Lot.UnitCost,
+			PurchasedAt: op.Lot.PurchasedAt.UTC().Format(time.RFC3339),
+		})
+	case db.LotOperationUpdate:
+		payload := lotWebhookPayload{LotID: result.LotID, Quantity: op.Patch.Quantity, UnitCost: op.Patch.UnitCost}
+		if op.Patch.AssetID != nil {
+			payload.AssetID = *op.Patch.AssetID
+		}
+		if op.Patch.PurchasedAt != nil {
+			payload.PurchasedAt = op.Patch.PurchasedAt.UTC().Format(time.RFC3339)
+		}
+		s.publishWebhookEvent(r.Context(), userID, db.WebhookEventLotUpdated, payload)
+	default:
+		s.publishWebhookEvent(r.Context(), userID, db.WebhookEventLotDeleted, lotWebhookPayload{LotID: result.LotID})
+	}
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"asset-tracker/internal/auth"
+	"asset-tracker/internal/db"
+)
+
+const lotBatchBody = `{"operations":[
+	{"op":"create","asset_id":1,"quantity":0.5,"unit_cost":100,"purchased_at":"2026-02-16"},
+	{"op":"update","id":7,"quantity":2,"unit_cost":90,"purchased_at":"2026-02-10"},
+	{"op":"delete","id":8}
+]}`
+
+func TestAPILotBatchSuccess(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/lots:batch", "good", []byte(lotBatchBody)))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", res.Code, res.Body.String())
+	}
+
+	var got lotBatchResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !got.Committed || got.FailedIndex != nil || len(got.Results) != 3 {
+		t.Fatalf("unexpected response: %+v", got)
+	}
+	if got.Results[0].Status != "created" || got.Results[0].ID != 100 || got.Results[2].Status != "deleted" || got.Results[2].ID != 8 {
+		t.Fatalf("unexpected results: %+v", got.Results)
+	}
+	if len(store.batchOps) != 3 || store.batchOps[1].Lot.ID != 7 || store.batchOps[1].Patch.Quantity == nil || *store.batchOps[1].Patch.Quantity != 2 {
+		t.Fatalf("unexpected operations: %+v", store.batchOps)
+	}
+	if len(store.webhookEvents) != 3 || store.webhookEvents[1] != db.WebhookEventLotUpdated {
+		t.Fatalf("expected one webhook event per operation, got %v", store.webhookEvents)
+	}
+}
+
+func TestAPILotBatchLocation(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	body := []byte(`{"operations":[
+		{"op":"create","asset_id":1,"quantity":1,"unit_cost":10,"purchased_at":"2026-02-16","location":" Ledger "},
+		{"op":"update","id":7,"revision":2,"location":""}
+	]}`)
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/lots:batch", "good", body))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", res.Code, res.Body.String())
+	}
+	if len(store.batchOps) != 2 {
+		t.Fatalf("expected 2 operations, got %+v", store.batchOps)
+	}
+	if got := store.batchOps[0].Lot.Location; !got.Valid || got.String != "Ledger" {
+		t.Fatalf("expected created lot location Ledger, got %+v", got)
+	}
+	if got := store.batchOps[1].Patch.Location; got == nil || *got != "" {
+		t.Fatalf("expected update to clear the location, got %v", got)
+	}
+
+	store = &mockStore{}
+	router = newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res = httptest.NewRecorder()
+
+	body = []byte(`{"operations":[{"op":"create","asset_id":1,"quantity":1,"unit_cost":10,"purchased_at":"2026-02-16","location":"  "}]}`)
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/lots:batch", "good", body))
+	if res.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for a blank location, got %d", res.Code)
+	}
+	if store.batchOps != nil {
+		t.Fatal("expected no store call for an invalid location")
+	}
+}
+
+func TestAPILotBatchValidationFailureSkipsStore(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	body := []byte(`{"operations":[{"op":"delete","id":3},{"op":"update","id":4,"quantity":0,"unit_cost":1,"purchased_at":"2026-02-16"}]}`)
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/lots:batch", "good", body))
+	if res.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", res.Code)
+	}
+
+	var got lotBatchResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.Committed || got.FailedIndex == nil || *got.FailedIndex != 1 {
+		t.Fatalf("expected failed_index 1, got %+v", got)
+	}
+	if store.batchOps != nil {
+		t.Fatal("expected no store call for an invalid batch")
+	}
+}
+
+func TestAPILotBatchRollbackReportsFailedIndex(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{batchErr: &db.LotBatchError{Index: 2, Err: db.ErrLotNotFound}}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/lots:batch", "good", []byte(lotBatchBody)))
+	if res.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", res.Code)
+	}
+
+	var got lotBatchResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.FailedIndex == nil || *got.FailedIndex != 2 || got.Error != "lot not found" {
+		t.Fatalf("unexpected failure: %+v", got)
+	}
+	if got.Results[0].Status != lotBatchStatusRolledBack || got.Results[2].Status != lotBatchStatusFailed {
+		t.Fatalf("unexpected results: %+v", got.Results)
+	}
+	if len(store.webhookEvents) != 0 {
+		t.Fatalf("expected no webhook events after rollback, got %v", store.webhookEvents)
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"asset-tracker/internal/db"
+)
+
+type lotEventResponse struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	Actor     string          `json:"actor"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	CreatedAt string          `json:"created_at"`
+}
+
+func (s *Server) handleRestoreLot(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	lotID, err := parseIDParam(r, "lotID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid lot id")
+		return
+	}
+
+	lot, err := s.DB.RestoreLotForUser(r.Context(), userID, lotID, db.DeletedLotRetention)
+	switch {
+	case errors.Is(err, db.ErrLotNotFound):
+		writeError(w, http.StatusNotFound, "lot not found")
+		return
+	case errors.Is(err, db.ErrLotNotDeleted):
+		writeError(w, http.StatusConflict, "lot is not deleted")
+		return
+	case errors.Is(err, db.ErrLotRestoreExpired):
+		writeError(w, http.StatusGone, "lot can no longer be restored")
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "failed to restore lot")
+		return
+	}
+
+	s.publishWebhookEvent(r.Context(), userID, db.WebhookEventLotRestored, lotWebhookPayload{
+		LotID:       lot.ID,
+		AssetID:     lot.AssetID,
+		Quantity:    &lot.Quantity,
+		UnitCost:    &lot.UnitCost,
+		PurchasedAt: lot.PurchasedAt.UTC().Format(time.RFC3339),
+	})
+
+	response, err := s.lotResponses(r.Context(), []db.Lot{lot})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load assets")
+		return
+	}
+	w.Header().Set("ETag", lotETag(lot.Revision))
+	writeJSON(w, http.StatusOK, response[0])
+}
+
+func (s *Server) handleListLotHistory(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	lotID, err := parseIDParam(r, "lotID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid lot id")
+		return
+	}
+
+	events, err := s.DB.ListLotEventsForUser(r.Context(), userID, lotID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load lot history")
+		return
+	}
+	if len(events) == 0 {
+		writeError(w, http.StatusNotFound, "lot not found")
+		return
+	}
+
+	response := make([]lotEventResponse, 0, len(events))
+	for _, event := range events {
+		response = append(response, lotEventResponse{
+			ID:        event.ID,
+			Type:      event.Type,
+			Actor:     event.Actor,
+			Before:    rawJSONOrNull(event.Before),
+			After:     rawJSONOrNull(event.After),
+			CreatedAt: event.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func rawJSONOrNull(value []byte) json.RawMessage {
+	if len(value) == 0 {
+		return json.RawMessage("null")
+	}
+	return value
+}
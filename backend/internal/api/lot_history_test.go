@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"asset-tracker/internal/auth"
+	"asset-tracker/internal/db"
+)
+
+func TestAPIRestoreLotSuccess(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{lotRevision: 3}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/lots/55/restore", "good", nil))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", res.Code, res.Body.String())
+	}
+	if store.restoredLotID != 55 || store.restoreWindow != db.DeletedLotRetention {
+		t.Fatalf("unexpected restore args: lot=%d window=%s", store.restoredLotID, store.restoreWindow)
+	}
+	if got := res.Header().Get("ETag"); got != `"4"` {
+		t.Fatalf("expected ETag \"4\", got %q", got)
+	}
+	var got lotResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.ID != 55 || got.Revision != 4 {
+		t.Fatalf("unexpected lot: %+v", got)
+	}
+	if len(store.webhookEvents) != 1 || store.webhookEvents[0] != db.WebhookEventLotRestored {
+		t.Fatalf("expected lot.restored event, got %v", store.webhookEvents)
+	}
+}
+
+func TestAPIRestoreLotErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{db.ErrLotNotFound, http.StatusNotFound},
+		{db.ErrLotNotDeleted, http.StatusConflict},
+		{db.ErrLotRestoreExpired, http.StatusGone},
+	}
+	for _, tc := range cases {
+		store := &mockStore{restoreErr: tc.err}
+		router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+		res := httptest.NewRecorder()
+
+		router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/lots/55/restore", "good", nil))
+		if res.Code != tc.want {
+			t.Fatalf("%v: expected %d, got %d", tc.err, tc.want, res.Code)
+		}
+	}
+}
+
+func TestAPIListLotHistory(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := &mockStore{lotEvents: []db.LotEvent{
+		{ID: 1, LotID: 55, UserID: "user-1", Type: db.LotEventCreated, Actor: "user-1", After: []byte(`{"quantity":1}`), CreatedAt: createdAt},
+		{ID: 2, LotID: 55, UserID: "user-1", Type: db.LotEventDeleted, Actor: "user-1", Before: []byte(`{"quantity":1}`), After: []byte(`{"quantity":1}`), CreatedAt: createdAt},
+		{ID: 3, LotID: 55, UserID: "user-2", Type: db.LotEventCreated, Actor: "user-2", After: []byte(`{}`), CreatedAt: createdAt},
+	}}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodGet, "/api/v1/lots/55/history", "good", nil))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.Code)
+	}
+	var got []lotEventResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got) != 2 || got[0].Type != db.LotEventCreated || got[1].Type != db.LotEventDeleted {
+		t.Fatalf("unexpected history: %+v", got)
+	}
+	if string(got[0].Before) != "null" || string(got[1].Before) != `{"quantity":1}` {
+		t.Fatalf("unexpected before snapshots: %s, %s", got[0].Before, got[1].Before)
+	}
+}
+
+func TestAPIListLotHistoryNotFound(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodGet, "/api/v1/lots/55/history", "good", nil))
+	if res.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", res.Code)
+	}
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"asset-tracker/internal/db"
+)
+
+const (
+	defaultLotPageSize = 100
+	maxLotPageSize     = 500
+	defaultLotSort     = "-purchased_at"
+)
+
+// lotCursor is the opaque next_cursor of GET /lots, base64url-encoded JSON.
+// It records the sort it was issued for so it cannot be replayed against a
+// different ordering.
+type lotCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+func parseLotQuery(r *http.Request, userID string) (db.LotQuery, string, string) {
+	params := r.URL.Query()
+	query := db.LotQuery{UserID: userID, Limit: defaultLotPageSize}
+
+	if raw := strings.TrimSpace(params.Get("asset_id")); raw != "" {
+		assetID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || assetID <= 0 {
+			return db.LotQuery{}, "", "asset_id must be a positive integer"
+		}
+		query.AssetID = assetID
+	}
+
+	if raw := strings.TrimSpace(params.Get("type")); raw != "" {
+		if raw != string(db.AssetTypeCrypto) && raw != string(db.AssetTypeStock) {
+			return db.LotQuery{}, "", "type must be crypto or stock"
+		}
+		query.AssetType = db.AssetType(raw)
+	}
+
+	if raw := strings.TrimSpace(params.Get("from")); raw != "" {
+		from, err := parseTimestamp(raw)
+		if err != nil {
+			return db.LotQuery{}, "", "from must be RFC3339 or YYYY-MM-DD"
+		}
+		query.PurchasedFrom = from
+	}
+	if raw := strings.TrimSpace(params.Get("to")); raw != "" {
+		to, err := parseTimestamp(raw)
+		if err != nil {
+			return db.LotQuery{}, "", "to must be RFC3339 or YYYY-MM-DD"
+		}
+		query.PurchasedTo = to
+	}
+	if !query.PurchasedFrom.IsZero() && !query.PurchasedTo.IsZero() && !query.PurchasedTo.After(query.PurchasedFrom) {
+		return db.LotQuery{}, "", "to must be after from"
+	}
+
+	sortKey := strings.TrimSpace(params.Get("sort"))
+	if sortKey == "" {
+		sortKey = defaultLotSort
+	}
+	query.Descending = strings.HasPrefix(sortKey, "-")
+	switch db.LotSortField(strings.TrimPrefix(sortKey, "-")) {
+	case db.LotSortPurchasedAt:
+		query.SortField = db.LotSortPurchasedAt
+	case db.LotSortCreatedAt:
+		query.SortField = db.LotSortCreatedAt
+	default:
+		return db.LotQuery{}, "", "sort must be purchased_at, -purchased_at, created_at, or -created_at"
+	}
+
+	if raw := strings.TrimSpace(params.Get("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return db.LotQuery{}, "", "limit must be a positive integer"
+		}
+		if limit > maxLotPageSize {
+			limit = maxLotPageSize
+		}
+		query.Limit = limit
+	}
+
+	if raw := strings.TrimSpace(params.Get("cursor")); raw != "" {
+		cursor, ok := decodeLotCursor(raw)
+		if !ok {
+			return db.LotQuery{}, "", "invalid cursor"
+		}
+		if cursor.Sort != sortKey {
+			return db.LotQuery{}, "", "cursor does not match sort"
+		}
+		value, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return db.LotQuery{}, "", "invalid cursor"
+		}
+		query.After = &db.LotCursor{Value: value, ID: cursor.ID}
+	}
+
+	return query, sortKey, ""
+}
+
+func encodeLotCursor(sortKey string, last db.Lot) string {
+	value := last.PurchasedAt
+	if strings.TrimPrefix(sortKey, "-") == string(db.LotSortCreatedAt) {
+		value = last.CreatedAt
+	}
+	body, _ := json.Marshal(lotCursor{Sort: sortKey, Value: value.UTC().Format(time.RFC3339Nano), ID: last.ID})
+	return base64.RawURLEncoding.EncodeToString(body)
+}
+
+func decodeLotCursor(raw string) (lotCursor, bool) {
+	body, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return lotCursor{}, false
+	}
+	var cursor lotCursor
+	if err := json.Unmarshal(body, &cursor); err != nil || cursor.ID <= 0 {
+		return lotCursor{}, false
+	}
+	return cursor, true
+}
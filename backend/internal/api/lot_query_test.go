@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"asset-tracker/internal/auth"
+	"asset-tracker/internal/db"
+)
+
+func TestAPIListLotsParsesFilters(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodGet, "/api/v1/lots?asset_id=3&type=stock&from=2026-01-01&to=2026-02-01&sort=created_at&limit=25", "good", nil))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", res.Code, res.Body.String())
+	}
+
+	q := store.lotsQuery
+	if q.UserID != "user-1" || q.AssetID != 3 || q.AssetType != db.AssetTypeStock {
+		t.Fatalf("unexpected filters: %+v", q)
+	}
+	if !q.PurchasedFrom.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) || !q.PurchasedTo.Equal(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected date range: %v - %v", q.PurchasedFrom, q.PurchasedTo)
+	}
+	if q.SortField != db.LotSortCreatedAt || q.Descending {
+		t.Fatalf("expected ascending created_at sort, got %+v", q)
+	}
+	if q.Limit != 26 {
+		t.Fatalf("expected limit+1 lookahead of 26, got %d", q.Limit)
+	}
+}
+
+func TestAPIListLotsRejectsInvalidParams(t *testing.T) {
+	t.Parallel()
+
+	for _, path := range []string{
+		"/api/v1/lots?sort=quantity",
+		"/api/v1/lots?type=bond",
+		"/api/v1/lots?from=2026-02-01&to=2026-01-01",
+		"/api/v1/lots?cursor=not-a-cursor",
+		"/api/v1/lots?limit=0",
+	} {
+		router := newAPIRouter(&mockStore{}, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+		res := httptest.NewRecorder()
+
+		router.ServeHTTP(res, newRequest(t, http.MethodGet, path, "good", nil))
+		if res.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", path, res.Code)
+		}
+	}
+}
+
+func TestAPIListLotsCursorRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2026, 2, 16, 12, 0, 0, 0, time.UTC)
+	store := &mockStore{lots: []db.Lot{
+		{ID: 9, AssetID: 1, Quantity: 1, PurchasedAt: base},
+		{ID: 8, AssetID: 1, Quantity: 1, PurchasedAt: base.Add(-time.Hour)},
+		{ID: 7, AssetID: 1, Quantity: 1, PurchasedAt: base.Add(-2 * time.Hour)},
+	}}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodGet, "/api/v1/lots?limit=2", "good", nil))
+	var page lotPageResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &page); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(page.Items) != 2 || page.NextCursor == nil {
+		t.Fatalf("expected 2 items and a next_cursor, got %+v", page)
+	}
+
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, newRequest(t, http.MethodGet, "/api/v1/lots?limit=2&cursor="+*page.NextCursor, "good", nil))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.Code)
+	}
+	after := store.lotsQuery.After
+	if after == nil || after.ID != 8 || !after.Value.Equal(base.Add(-time.Hour)) {
+		t.Fatalf("expected cursor after lot 8, got %+v", after)
+	}
+
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, newRequest(t, http.MethodGet, "/api/v1/lots?sort=created_at&cursor="+*page.NextCursor, "good", nil))
+	if res.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for cursor/sort mismatch, got %d", res.Code)
+	}
+}
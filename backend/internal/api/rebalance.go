@@ -0,0 +1,294 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"asset-tracker/internal/db"
+)
+
+const (
+	rebalanceActionBuy  = "buy"
+	rebalanceActionSell = "sell"
+	rebalanceActionHold = "hold"
+)
+
+type rebalanceHolding struct {
+	AssetID  int64
+	Symbol   string
+	Type     db.AssetType
+	Quantity float64
+	Price    float64
+	Priced   bool
+}
+
+type rebalanceOptions struct {
+	Cash       float64
+	MinTrade   float64
+	AvoidSells bool
+}
+
+type rebalanceItem struct {
+	AssetID       int64   `json:"asset_id"`
+	Symbol        string  `json:"symbol"`
+	Type          string  `json:"type"`
+	Price         float64 `json:"price"`
+	Quantity      float64 `json:"quantity"`
+	Value         float64 `json:"value"`
+	CurrentWeight float64 `json:"current_weight"`
+	TargetWeight  float64 `json:"target_weight"`
+	Drift         float64 `json:"drift"`
+	Action        string  `json:"action"`
+	TradeQuantity float64 `json:"trade_quantity"`
+	TradeValue    float64 `json:"trade_value"`
+}
+
+type rebalanceResponse struct {
+	TotalValue       float64         `json:"total_value"`
+	Cash             float64         `json:"cash"`
+	CashAfter        float64         `json:"cash_after"`
+	Items            []rebalanceItem `json:"items"`
+	UnpricedAssetIDs []int64         `json:"unpriced_asset_ids"`
+}
+
+func (s *Server) handleRebalance(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	opts, message := parseRebalanceOptions(r)
+	if message != "" {
+		writeError(w, http.StatusBadRequest, message)
+		return
+	}
+
+	targets, err := s.DB.ListAllocationTargets(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load targets")
+		return
+	}
+	if len(targets) == 0 {
+		writeError(w, http.StatusConflict, "no allocation targets set; use PUT /api/v1/targets")
+		return
+	}
+
+	positions, err := s.DB.FetchPositionsForUser(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load positions")
+		return
+	}
+
+	holdings, err := s.rebalanceHoldings(r, positions, targets)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load prices")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, computeRebalance(holdings, targets, opts))
+}
+
+func parseRebalanceOptions(r *http.Request) (rebalanceOptions, string) {
+	var opts rebalanceOptions
+	query := r.URL.Query()
+	if raw := strings.TrimSpace(query.Get("cash")); raw != "" {
+		cash, err := strconv.ParseFloat(raw, 64)
+		if err != nil || cash < 0 || math.IsInf(cash, 0) {
+			return rebalanceOptions{}, "cash must be a number greater than or equal to 0"
+		}
+		opts.Cash = cash
+	}
+	if raw := strings.TrimSpace(query.Get("min_trade")); raw != "" {
+		minTrade, err := strconv.ParseFloat(raw, 64)
+		if err != nil || minTrade < 0 || math.IsInf(minTrade, 0) {
+			return rebalanceOptions{}, "min_trade must be a number greater than or equal to 0"
+		}
+		opts.MinTrade = minTrade
+	}
+	if raw := strings.TrimSpace(query.Get("avoid_sells")); raw != "" {
+		avoidSells, err := strconv.ParseBool(raw)
+		if err != nil {
+			return rebalanceOptions{}, "avoid_sells must be true or false"
+		}
+		opts.AvoidSells = avoidSells
+	}
+	return opts, ""
+}
+
+// rebalanceHoldings combines the user's positions with targeted assets they
+// do not hold yet, which are priced from prices_current.
+func (s *Server) rebalanceHoldings(r *http.Request, positions []db.Position, targets []db.AllocationTarget) ([]rebalanceHolding, error) {
+	held := make(map[int64]bool, len(positions))
+	assetIDs := make([]int64, 0, len(positions)+len(targets))
+	for _, position := range positions {
+		held[position.AssetID] = true
+		assetIDs = append(assetIDs, position.AssetID)
+	}
+	var unheld []int64
+	for _, target := range targets {
+		if target.AssetID > 0 && !held[target.AssetID] {
+			unheld = append(unheld, target.AssetID)
+			assetIDs = append(assetIDs, target.AssetID)
+		}
+	}
+
+	assets, err := s.DB.ListAssetsByIDs(r.Context(), assetIDs)
+	if err != nil {
+		return nil, err
+	}
+	assetMap := make(map[int64]db.Asset, len(assets))
+	for _, asset := range assets {
+		assetMap[asset.ID] = asset
+	}
+	prices, err := s.DB.ListCurrentPrices(r.Context(), unheld)
+	if err != nil {
+		return nil, err
+	}
+
+	holdings := make([]rebalanceHolding, 0, len(assetIDs))
+	for _, position := range positions {
+		asset := assetMap[position.AssetID]
+		holdings = append(holdings, rebalanceHolding{
+			AssetID:  position.AssetID,
+			Symbol:   asset.Symbol,
+			Type:     asset.Type,
+			Quantity: position.TotalQty,
+			Price:    position.CurrentPrice.Float64,
+			Priced:   position.CurrentPrice.Valid,
+		})
+	}
+	for _, assetID := range unheld {
+		asset := assetMap[assetID]
+		price, ok := prices[assetID]
+		holdings = append(holdings, rebalanceHolding{
+			AssetID: assetID,
+			Symbol:  asset.Symbol,
+			Type:    asset.Type,
+			Price:   price,
+			Priced:  ok,
+		})
+	}
+	return holdings, nil
+}
+
+// computeRebalance sizes the trades that move priced holdings to their target
+// weights of holdings value plus cash. Assets without a target aim for 0; a
+// type target is split across that type's untargeted holdings by current
+// value. Weight that cannot be placed stays in cash. Trades smaller than
+// MinTrade are skipped, and when buys cost more than cash plus sells they are
+// scaled down to fit.
+func computeRebalance(holdings []rebalanceHolding, targets []db.AllocationTarget, opts rebalanceOptions) rebalanceResponse {
+	response := rebalanceResponse{
+		Cash:             opts.Cash,
+		Items:            []rebalanceItem{},
+		UnpricedAssetIDs: []int64{},
+	}
+
+	var priced []rebalanceHolding
+	total := opts.Cash
+	for _, holding := range holdings {
+		if !holding.Priced || holding.Price <= 0 {
+			response.UnpricedAssetIDs = append(response.UnpricedAssetIDs, holding.AssetID)
+			continue
+		}
+		priced = append(priced, holding)
+		total += holding.Quantity * holding.Price
+	}
+	sort.Slice(priced, func(i, j int) bool { return priced[i].AssetID < priced[j].AssetID })
+	sort.Slice(response.UnpricedAssetIDs, func(i, j int) bool { return response.UnpricedAssetIDs[i] < response.UnpricedAssetIDs[j] })
+	response.TotalValue = total
+
+	weights := make(map[int64]float64, len(priced))
+	for _, target := range targets {
+		if target.AssetID > 0 {
+			weights[target.AssetID] = target.Weight
+		}
+	}
+	for _, target := range targets {
+		if target.AssetType == "" {
+			continue
+		}
+		var groupValue float64
+		for _, holding := range priced {
+			if _, ok := weights[holding.AssetID]; !ok && holding.Type == target.AssetType {
+				groupValue += holding.Quantity * holding.Price
+			}
+		}
+		if groupValue <= 0 {
+			continue
+		}
+		shares := make(map[int64]float64)
+		for _, holding := range priced {
+			if _, ok := weights[holding.AssetID]; !ok && holding.Type == target.AssetType {
+				shares[holding.AssetID] = target.Weight * holding.Quantity * holding.Price / groupValue
+			}
+		}
+		for assetID, weight := range shares {
+			weights[assetID] = weight
+		}
+	}
+
+	var buys, sells float64
+	for _, holding := range priced {
+		value := holding.Quantity * holding.Price
+		item := rebalanceItem{
+			AssetID:      holding.AssetID,
+			Symbol:       holding.Symbol,
+			Type:         string(holding.Type),
+			Price:        holding.Price,
+			Quantity:     holding.Quantity,
+			Value:        value,
+			TargetWeight: weights[holding.AssetID],
+			Action:       rebalanceActionHold,
+		}
+		if total > 0 {
+			item.CurrentWeight = value / total
+		}
+		item.Drift = item.CurrentWeight - item.TargetWeight
+
+		delta := item.TargetWeight*total - value
+		switch {
+		case math.Abs(delta) < math.Max(opts.MinTrade, weightTolerance*total):
+		case delta > 0:
+			item.Action = rebalanceActionBuy
+			item.TradeValue = delta
+			buys += delta
+		case !opts.AvoidSells:
+			item.Action = rebalanceActionSell
+			item.TradeValue = -delta
+			sells -= delta
+		}
+		response.Items = append(response.Items, item)
+	}
+
+	if budget := opts.Cash + sells; buys > budget {
+		scale := budget / buys
+		buys = 0
+		for i := range response.Items {
+			item := &response.Items[i]
+			if item.Action != rebalanceActionBuy {
+				continue
+			}
+			item.TradeValue *= scale
+			if item.TradeValue < opts.MinTrade || item.TradeValue <= 0 {
+				item.Action = rebalanceActionHold
+				item.TradeValue = 0
+				continue
+			}
+			buys += item.TradeValue
+		}
+	}
+
+	for i := range response.Items {
+		item := &response.Items[i]
+		if item.Action != rebalanceActionHold {
+			item.TradeQuantity = item.TradeValue / item.Price
+		}
+	}
+	response.CashAfter = opts.Cash + sells - buys
+	return response
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"asset-tracker/internal/auth"
+	"asset-tracker/internal/db"
+)
+
+func rebalanceItemFor(t *testing.T, response rebalanceResponse, assetID int64) rebalanceItem {
+	t.Helper()
+
+	for _, item := range response.Items {
+		if item.AssetID == assetID {
+			return item
+		}
+	}
+	t.Fatalf("no rebalance item for asset %d in %+v", assetID, response.Items)
+	return rebalanceItem{}
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestComputeRebalanceAssetAndTypeTargets(t *testing.T) {
+	t.Parallel()
+
+	holdings := []rebalanceHolding{
+		{AssetID: 1, Type: db.AssetTypeCrypto, Quantity: 1, Price: 6000, Priced: true},
+		{AssetID: 2, Type: db.AssetTypeStock, Quantity: 10, Price: 200, Priced: true},
+		{AssetID: 3, Type: db.AssetTypeStock, Quantity: 10, Price: 200, Priced: true},
+		{AssetID: 4, Type: db.AssetTypeCrypto, Quantity: 5, Priced: false},
+	}
+	targets := []db.AllocationTarget{
+		{AssetID: 1, Weight: 0.5},
+		{AssetType: db.AssetTypeStock, Weight: 0.5},
+	}
+
+	got := computeRebalance(holdings, targets, rebalanceOptions{})
+	if !approxEqual(got.TotalValue, 10000) || len(got.UnpricedAssetIDs) != 1 || got.UnpricedAssetIDs[0] != 4 {
+		t.Fatalf("unexpected totals: %+v", got)
+	}
+
+	btc := rebalanceItemFor(t, got, 1)
+	if btc.Action != rebalanceActionSell || !approxEqual(btc.TradeValue, 1000) || !approxEqual(btc.TradeQuantity, 1.0/6) || !approxEqual(btc.Drift, 0.1) {
+		t.Fatalf("unexpected crypto item: %+v", btc)
+	}
+	for _, assetID := range []int64{2, 3} {
+		stock := rebalanceItemFor(t, got, assetID)
+		if stock.Action != rebalanceActionBuy || !approxEqual(stock.TargetWeight, 0.25) || !approxEqual(stock.TradeQuantity, 2.5) {
+			t.Fatalf("unexpected stock item: %+v", stock)
+		}
+	}
+	if !approxEqual(got.CashAfter, 0) {
+		t.Fatalf("expected all proceeds reinvested, got cash_after %v", got.CashAfter)
+	}
+}
+
+func TestComputeRebalanceAvoidSellsScalesBuys(t *testing.T) {
+	t.Parallel()
+
+	holdings := []rebalanceHolding{
+		{AssetID: 1, Type: db.AssetTypeCrypto, Quantity: 1, Price: 900, Priced: true},
+		{AssetID: 2, Type: db.AssetTypeStock, Quantity: 0, Price: 50, Priced: true},
+	}
+	targets := []db.AllocationTarget{{AssetID: 2, Weight: 0.5}}
+
+	got := computeRebalance(holdings, targets, rebalanceOptions{Cash: 100, AvoidSells: true})
+	if item := rebalanceItemFor(t, got, 1); item.Action != rebalanceActionHold || item.TradeValue != 0 {
+		t.Fatalf("expected no sell, got %+v", item)
+	}
+	if item := rebalanceItemFor(t, got, 2); item.Action != rebalanceActionBuy || !approxEqual(item.TradeValue, 100) || !approxEqual(item.TradeQuantity, 2) {
+		t.Fatalf("expected buys limited to cash, got %+v", item)
+	}
+	if !approxEqual(got.CashAfter, 0) {
+		t.Fatalf("expected cash to be spent, got %v", got.CashAfter)
+	}
+}
+
+func TestComputeRebalanceMinTrade(t *testing.T) {
+	t.Parallel()
+
+	holdings := []rebalanceHolding{
+		{AssetID: 1, Type: db.AssetTypeCrypto, Quantity: 1, Price: 510, Priced: true},
+		{AssetID: 2, Type: db.AssetTypeCrypto, Quantity: 1, Price: 490, Priced: true},
+	}
+	targets := []db.AllocationTarget{{AssetID: 1, Weight: 0.5}, {AssetID: 2, Weight: 0.5}}
+
+	got := computeRebalance(holdings, targets, rebalanceOptions{MinTrade: 25})
+	for _, item := range got.Items {
+		if item.Action != rebalanceActionHold {
+			t.Fatalf("expected trades under min_trade to be skipped, got %+v", item)
+		}
+	}
+}
+
+func TestAPIRebalance(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{
+		positions: []db.Position{{AssetID: 1, TotalQty: 2, CurrentPrice: sql.NullFloat64{Float64: 500, Valid: true}}},
+		assetsByID: map[int64]db.Asset{
+			1: {ID: 1, Symbol: "BTC", Type: db.AssetTypeCrypto},
+			2: {ID: 2, Symbol: "VOO", Type: db.AssetTypeStock},
+		},
+		targets: []db.AllocationTarget{{AssetID: 1, Weight: 0.5}, {AssetID: 2, Weight: 0.5}},
+		prices:  map[int64]float64{2: 100},
+	}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodGet, "/api/v1/rebalance?cash=1000", "good", nil))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", res.Code, res.Body.String())
+	}
+	var got rebalanceResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	voo := rebalanceItemFor(t, got, 2)
+	if voo.Symbol != "VOO" || voo.Action != rebalanceActionBuy || !approxEqual(voo.TradeQuantity, 10) {
+		t.Fatalf("unexpected item for unheld target: %+v", voo)
+	}
+	if btc := rebalanceItemFor(t, got, 1); btc.Action != rebalanceActionHold {
+		t.Fatalf("expected BTC on target, got %+v", btc)
+	}
+}
+
+func TestAPIRebalanceErrors(t *testing.T) {
+	t.Parallel()
+
+	router := newAPIRouter(&mockStore{}, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, newRequest(t, http.MethodGet, "/api/v1/rebalance", "good", nil))
+	if res.Code != http.StatusConflict {
+		t.Fatalf("expected 409 without targets, got %d", res.Code)
+	}
+
+	for _, query := range []string{"cash=-1", "min_trade=abc", "avoid_sells=maybe"} {
+		res = httptest.NewRecorder()
+		router.ServeHTTP(res, newRequest(t, http.MethodGet, "/api/v1/rebalance?"+query, "good", nil))
+		if res.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", query, res.Code)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
@@ -8,9 +9,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"asset-tracker/internal/auth"
 	"asset-tracker/internal/db"
@@ -21,20 +24,64 @@ import (
 type Server struct {
 	DB       Store
 	Verifier auth.Verifier
+	// AdminUserIDs may use the /admin routes.
+	AdminUserIDs []string
 }
 
 type Store interface {
 	FetchPositionsForUser(ctx context.Context, userID string) ([]db.Position, error)
-	ListLotsByUser(ctx context.Context, userID string) ([]db.Lot, error)
+	ListLots(ctx context.Context, query db.LotQuery) ([]db.Lot, error)
 	InsertLot(ctx context.Context, lot db.Lot) (int64, error)
-	UpdateLotForUser(ctx context.Context, userID string, lotID int64, quantity float64, unitCost float64, purchasedAt time.Time) (bool, error)
-	DeleteLotForUser(ctx context.Context, userID string, lotID int64) (bool, error)
+	UpdateLotForUser(ctx context.Context, userID string, lotID int64, patch db.LotPatch, expectedRevision int64) (db.Lot, error)
+	DeleteLotForUser(ctx context.Context, userID string, lotID int64, expectedRevision int64) (db.Lot, error)
+	RestoreLotForUser(ctx context.Context, userID string, lotID int64, window time.Duration) (db.Lot, error)
+	ListLotEventsForUser(ctx context.Context, userID string, lotID int64) ([]db.LotEvent, error)
 	SearchAssets(ctx context.Context, query string, assetType string, limit int) ([]db.Asset, error)
 	ListAssetsByIDs(ctx context.Context, ids []int64) ([]db.Asset, error)
+	ListAlertsByUser(ctx context.Context, userID string) ([]db.Alert, error)
+	InsertAlert(ctx context.Context, alert db.Alert) (int64, error)
+	UpdateAlertForUser(ctx context.Context, userID string, alertID int64, patch db.AlertPatch) (bool, error)
+	DeleteAlertForUser(ctx context.Context, userID string, alertID int64) (bool, error)
+	ListAlertEventsByUser(ctx context.Context, userID string, limit int) ([]db.AlertEvent, error)
+	ListWatchlistsByUser(ctx context.Context, userID string) ([]db.Watchlist, error)
+	InsertWatchlist(ctx context.Context, watchlist db.Watchlist) (int64, error)
+	UpdateWatchlistForUser(ctx context.Context, userID string, watchlistID int64, patch db.WatchlistPatch) error
+	DeleteWatchlistForUser(ctx context.Context, userID string, watchlistID int64) (bool, error)
+	AddWatchlistAsset(ctx context.Context, userID string, watchlistID int64, assetID int64) error
+	RemoveWatchlistAsset(ctx context.Context, userID string, watchlistID int64, assetID int64) (bool, error)
+	InsertCorporateAction(ctx context.Context, action db.CorporateAction) (int64, error)
+	ListCorporateActions(ctx context.Context, assetID int64, limit int) ([]db.CorporateAction, error)
+	ApplyCorporateAction(ctx context.Context, actionID int64) ([]db.CorporateActionAdjustment, error)
+	ListIncomeForUser(ctx context.Context, userID string, assetID int64, limit int) ([]db.Income, error)
+	InsertIncome(ctx context.Context, income db.Income, reinvest *db.Lot) (db.Income, error)
+	UpdateIncomeForUser(ctx context.Context, userID string, incomeID int64, patch db.IncomePatch) (bool, error)
+	DeleteIncomeForUser(ctx context.Context, userID string, incomeID int64) (bool, error)
+	TransferLots(ctx context.Context, transfer db.Transfer) (db.Transfer, error)
+	ListTransfersForUser(ctx context.Context, userID string, limit int) ([]db.Transfer, error)
+	ListAllocationTargets(ctx context.Context, userID string) ([]db.AllocationTarget, error)
+	ReplaceAllocationTargets(ctx context.Context, userID string, targets []db.AllocationTarget) error
+	ListCurrentPrices(ctx context.Context, assetIDs []int64) (map[int64]float64, error)
+	ListBenchmarks(ctx context.Context, userID string) ([]int64, error)
+	ReplaceBenchmarks(ctx context.Context, userID string, assetIDs []int64) error
+	ListPriceSeries(ctx context.Context, assetIDs []int64, times []time.Time) ([]db.PricePoint, error)
+	ListLotsByUser(ctx context.Context, userID string) ([]db.Lot, error)
+	ListWebhooksByUser(ctx context.Context, userID string) ([]db.Webhook, error)
+	InsertWebhook(ctx context.Context, webhook db.Webhook) (int64, error)
+	UpdateWebhookForUser(ctx context.Context, userID string, webhookID int64, patch db.WebhookPatch) (bool, error)
+	DeleteWebhookForUser(ctx context.Context, userID string, webhookID int64) (bool, error)
+	ListWebhookDeliveriesForUser(ctx context.Context, userID string, webhookID int64, limit int) ([]db.WebhookDelivery, error)
+	EnqueueWebhookEvent(ctx context.Context, userID string, eventType string, payload any) error
+	EnqueueWebhookDeliveries(ctx context.Context, webhookIDs []int64, userID string, eventType string, payload any) (int64, error)
+	ApplyLotBatch(ctx context.Context, userID string, ops []db.LotOperation) ([]db.LotOperationResult, error)
+	ReserveIdempotencyKey(ctx context.Context, key db.IdempotencyKey, ttl time.Duration, staleAfter time.Duration) (db.IdempotencyKey, bool, error)
+	CompleteIdempotencyKey(ctx context.Context, userID string, key string, statusCode int, contentType string, headers map[string]string, body []byte) error
+	ReleaseIdempotencyKey(ctx context.Context, userID string, key string) error
 }
 
 type contextKey string
 
+const maxLocationLength = 100
+
 const userIDContextKey contextKey = "userID"
 
 func NewServer(store Store, verifier auth.Verifier) *Server {
@@ -45,12 +92,51 @@ func (s *Server) Mount(r chi.Router) {
 	r.Route("/api/v1", func(r chi.Router) {
 		r.Use(telemetry.APIRequestMetricsMiddleware)
 		r.Use(s.authMiddleware)
+		r.Use(s.idempotencyMiddleware)
 		r.Get("/positions", s.handleListPositions)
 		r.Get("/lots", s.handleListLots)
 		r.Post("/lots", s.handleCreateLot)
+		r.Post("/lots:batch", s.handleLotBatch)
 		r.Patch("/lots/{lotID}", s.handleUpdateLot)
 		r.Delete("/lots/{lotID}", s.handleDeleteLot)
+		r.Post("/lots/{lotID}/restore", s.handleRestoreLot)
+		r.Get("/lots/{lotID}/history", s.handleListLotHistory)
 		r.Get("/assets/search", s.handleSearchAssets)
+		r.Get("/alerts", s.handleListAlerts)
+		r.Post("/alerts", s.handleCreateAlert)
+		r.Get("/alerts/events", s.handleListAlertEvents)
+		r.Patch("/alerts/{alertID}", s.handleUpdateAlert)
+		r.Delete("/alerts/{alertID}", s.handleDeleteAlert)
+		r.Get("/watchlists", s.handleListWatchlists)
+		r.Post("/watchlists", s.handleCreateWatchlist)
+		r.Patch("/watchlists/{watchlistID}", s.handleUpdateWatchlist)
+		r.Delete("/watchlists/{watchlistID}", s.handleDeleteWatchlist)
+		r.Put("/watchlists/{watchlistID}/assets/{assetID}", s.handleAddWatchlistAsset)
+		r.Delete("/watchlists/{watchlistID}/assets/{assetID}", s.handleRemoveWatchlistAsset)
+		r.Get("/income", s.handleListIncome)
+		r.Post("/income", s.handleCreateIncome)
+		r.Patch("/income/{incomeID}", s.handleUpdateIncome)
+		r.Delete("/income/{incomeID}", s.handleDeleteIncome)
+		r.Get("/transfers", s.handleListTransfers)
+		r.Post("/transfers", s.handleCreateTransfer)
+		r.Get("/targets", s.handleListTargets)
+		r.Put("/targets", s.handleReplaceTargets)
+		r.Get("/rebalance", s.handleRebalance)
+		r.Get("/benchmarks", s.handleListBenchmarks)
+		r.Put("/benchmarks", s.handleReplaceBenchmarks)
+		r.Get("/portfolio/benchmark", s.handlePortfolioBenchmark)
+		r.Get("/webhooks", s.handleListWebhooks)
+		r.Post("/webhooks", s.handleCreateWebhook)
+		r.Patch("/webhooks/{webhookID}", s.handleUpdateWebhook)
+		r.Delete("/webhooks/{webhookID}", s.handleDeleteWebhook)
+		r.Post("/webhooks/{webhookID}/test", s.handleTestWebhook)
+		r.Get("/webhooks/{webhookID}/deliveries", s.handleListWebhookDeliveries)
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(s.adminMiddleware)
+			r.Get("/corporate-actions", s.handleListCorporateActions)
+			r.Post("/corporate-actions", s.handleCreateCorporateAction)
+			r.Post("/corporate-actions/{actionID}/apply", s.handleApplyCorporateAction)
+		})
 	})
 }
 
@@ -73,6 +159,16 @@ func (s *Server) authMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+func (s *Server) adminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !slices.Contains(s.AdminUserIDs, userIDFromContext(r.Context())) {
+			writeError(w, http.StatusForbidden, "admin access required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func userIDFromContext(ctx context.Context) string {
 	userID, _ := ctx.Value(userIDContextKey).(string)
 	return strings.TrimSpace(userID)
@@ -112,15 +208,76 @@ func decodeJSONBody(r *http.Request, dst any) error {
 	return nil
 }
 
+type mergePatchError string
+
+func (e mergePatchError) Error() string {
+	return string(e)
+}
+
+// decodeMergePatch decodes a JSON merge patch into dst, whose fields must be
+// pointers. An empty patch is rejected. Fields named in clearable are string
+// fields that can be removed: a null there is decoded as the empty string,
+// which clears them. Other fields are required, so null is rejected for them
+// instead of being treated as a removal.
+func decodeMergePatch(r *http.Request, dst any, clearable ...string) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return mergePatchError("request body must set at least one field")
+	}
+	cleared := false
+	for name, raw := range fields {
+		if string(bytes.TrimSpace(raw)) != "null" {
+			continue
+		}
+		if !slices.Contains(clearable, name) {
+			return mergePatchError(name + " cannot be null")
+		}
+		fields[name] = json.RawMessage(`""`)
+		cleared = true
+	}
+	if cleared {
+		if body, err = json.Marshal(fields); err != nil {
+			return err
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(dst)
+}
+
+func isMergePatchContentType(value string) bool {
+	mediaType, _, _ := strings.Cut(value, ";")
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case "", "application/json", "application/merge-patch+json":
+		return true
+	default:
+		return false
+	}
+}
+
 type positionResponse struct {
-	AssetID      int64    `json:"asset_id"`
-	Symbol       string   `json:"symbol"`
-	Name         string   `json:"name"`
-	Type         string   `json:"type"`
-	TotalQty     float64  `json:"total_qty"`
-	AvgCost      float64  `json:"avg_cost"`
-	CurrentPrice *float64 `json:"current_price"`
-	UnrealizedPL *float64 `json:"unrealized_pl"`
+	AssetID        int64    `json:"asset_id"`
+	Symbol         string   `json:"symbol"`
+	Name           string   `json:"name"`
+	Type           string   `json:"type"`
+	TotalQty       float64  `json:"total_qty"`
+	AvgCost        float64  `json:"avg_cost"`
+	CurrentPrice   *float64 `json:"current_price"`
+	UnrealizedPL   *float64 `json:"unrealized_pl"`
+	IncomeReceived float64  `json:"income_received"`
+	Change24hPct   *float64 `json:"change_24h_pct"`
+	Volume24h      *float64 `json:"volume_24h"`
+	MarketCap      *float64 `json:"market_cap"`
+	PriceAsOf      *string  `json:"price_as_of"`
 }
 
 func (s *Server) handleListPositions(w http.ResponseWriter, r *http.Request) {
@@ -146,14 +303,22 @@ func (s *Server) handleListPositions(w http.ResponseWriter, r *http.Request) {
 	for _, position := range positions {
 		asset := assetMap[position.AssetID]
 		item := positionResponse{
-			AssetID:      position.AssetID,
-			Symbol:       asset.Symbol,
-			Name:         asset.Name,
-			Type:         string(asset.Type),
-			TotalQty:     position.TotalQty,
-			AvgCost:      position.AvgCost,
-			CurrentPrice: nullFloatToPtr(position.CurrentPrice),
-			UnrealizedPL: nullFloatToPtr(position.UnrealizedPL),
+			AssetID:        position.AssetID,
+			Symbol:         asset.Symbol,
+			Name:           asset.Name,
+			Type:           string(asset.Type),
+			TotalQty:       position.TotalQty,
+			AvgCost:        position.AvgCost,
+			CurrentPrice:   nullFloatToPtr(position.CurrentPrice),
+			UnrealizedPL:   nullFloatToPtr(position.UnrealizedPL),
+			IncomeReceived: position.IncomeReceived,
+			Change24hPct:   nullFloatToPtr(position.Change24hPct),
+			Volume24h:      nullFloatToPtr(position.Volume24h),
+			MarketCap:      nullFloatToPtr(position.MarketCap),
+		}
+		if position.PriceAsOf.Valid {
+			asOf := position.PriceAsOf.Time.UTC().Format(time.RFC3339)
+			item.PriceAsOf = &asOf
 		}
 		if item.Symbol == "" {
 			item.Symbol = fmt.Sprintf("#%d", position.AssetID)
@@ -167,7 +332,7 @@ func (s *Server) handleListPositions(w http.ResponseWriter, r *http.Request) {
 		response = append(response, item)
 	}
 
-	writeJSON(w, http.StatusOK, response)
+	writeJSONWithETag(w, r, http.StatusOK, response)
 }
 
 type lotResponse struct {
@@ -179,25 +344,19 @@ type lotResponse struct {
 	Quantity    float64 `json:"quantity"`
 	UnitCost    float64 `json:"unit_cost"`
 	PurchasedAt string  `json:"purchased_at"`
+	Location    *string `json:"location"`
+	Revision    int64   `json:"revision"`
 }
 
-func (s *Server) handleListLots(w http.ResponseWriter, r *http.Request) {
-	userID := userIDFromContext(r.Context())
-	if userID == "" {
-		writeError(w, http.StatusUnauthorized, "missing user context")
-		return
-	}
-
-	lots, err := s.DB.ListLotsByUser(r.Context(), userID)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to load lots")
-		return
-	}
+type lotPageResponse struct {
+	Items      []lotResponse `json:"items"`
+	NextCursor *string       `json:"next_cursor"`
+}
 
-	assetMap, err := s.loadAssetMapForLots(r.Context(), lots)
+func (s *Server) lotResponses(ctx context.Context, lots []db.Lot) ([]lotResponse, error) {
+	assetMap, err := s.loadAssetMapForLots(ctx, lots)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to load assets")
-		return
+		return nil, err
 	}
 
 	response := make([]lotResponse, 0, len(lots))
@@ -212,6 +371,10 @@ func (s *Server) handleListLots(w http.ResponseWriter, r *http.Request) {
 			Quantity:    lot.Quantity,
 			UnitCost:    lot.UnitCost,
 			PurchasedAt: lot.PurchasedAt.UTC().Format(time.RFC3339),
+			Revision:    lot.Revision,
+		}
+		if lot.Location.Valid {
+			item.Location = &lot.Location.String
 		}
 		if item.Symbol == "" {
 			item.Symbol = fmt.Sprintf("#%d", lot.AssetID)
@@ -224,8 +387,45 @@ func (s *Server) handleListLots(w http.ResponseWriter, r *http.Request) {
 		}
 		response = append(response, item)
 	}
+	return response, nil
+}
 
-	writeJSON(w, http.StatusOK, response)
+func (s *Server) handleListLots(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	query, sortKey, message := parseLotQuery(r, userID)
+	if message != "" {
+		writeError(w, http.StatusBadRequest, message)
+		return
+	}
+
+	// Fetch one extra row to learn whether another page follows.
+	pageSize := query.Limit
+	query.Limit++
+	lots, err := s.DB.ListLots(r.Context(), query)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load lots")
+		return
+	}
+
+	var nextCursor *string
+	if len(lots) > pageSize {
+		lots = lots[:pageSize]
+		cursor := encodeLotCursor(sortKey, lots[len(lots)-1])
+		nextCursor = &cursor
+	}
+
+	response, err := s.lotResponses(r.Context(), lots)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load assets")
+		return
+	}
+
+	writeJSONWithETag(w, r, http.StatusOK, lotPageResponse{Items: response, NextCursor: nextCursor})
 }
 
 type createLotRequest struct {
@@ -233,6 +433,7 @@ type createLotRequest struct {
 	Quantity    float64 `json:"quantity"`
 	UnitCost    float64 `json:"unit_cost"`
 	PurchasedAt string  `json:"purchased_at"`
+	Location    *string `json:"location"`
 }
 
 type createLotResponse struct {
@@ -261,13 +462,18 @@ func (s *Server) handleCreateLot(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "asset_id must be greater than 0")
 		return
 	}
-	if req.Quantity <= 0 {
-		writeError(w, http.StatusBadRequest, "quantity must be greater than 0")
+	if message := validateLotValues(req.Quantity, req.UnitCost); message != "" {
+		writeError(w, http.StatusBadRequest, message)
 		return
 	}
-	if req.UnitCost < 0 {
-		writeError(w, http.StatusBadRequest, "unit_cost must be greater than or equal to 0")
-		return
+	var location sql.NullString
+	if req.Location != nil {
+		value, ok := normalizeLocation(*req.Location)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "location must be 1 to 100 characters")
+			return
+		}
+		location = sql.NullString{String: value, Valid: true}
 	}
 
 	id, err := s.DB.InsertLot(r.Context(), db.Lot{
@@ -276,19 +482,32 @@ func (s *Server) handleCreateLot(w http.ResponseWriter, r *http.Request) {
 		Quantity:    req.Quantity,
 		UnitCost:    req.UnitCost,
 		PurchasedAt: purchasedAt,
+		Location:    location,
 	})
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to create lot")
 		return
 	}
 
+	s.publishWebhookEvent(r.Context(), userID, db.WebhookEventLotCreated, lotWebhookPayload{
+		LotID:       id,
+		AssetID:     req.AssetID,
+		Quantity:    &req.Quantity,
+		UnitCost:    &req.UnitCost,
+		PurchasedAt: purchasedAt.UTC().Format(time.RFC3339),
+	})
+
 	writeJSON(w, http.StatusCreated, createLotResponse{ID: id})
 }
 
+// updateLotRequest is a JSON merge patch (RFC 7396): absent fields keep their
+// stored value. An empty location clears the label.
 type updateLotRequest struct {
-	Quantity    float64 `json:"quantity"`
-	UnitCost    float64 `json:"unit_cost"`
-	PurchasedAt string  `json:"purchased_at"`
+	AssetID     *int64   `json:"asset_id"`
+	Quantity    *float64 `json:"quantity"`
+	UnitCost    *float64 `json:"unit_cost"`
+	PurchasedAt *string  `json:"purchased_at"`
+	Location    *string  `json:"location"`
 }
 
 func (s *Server) handleUpdateLot(w http.ResponseWriter, r *http.Request) {
@@ -304,36 +523,72 @@ func (s *Server) handleUpdateLot(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !isMergePatchContentType(r.Header.Get("Content-Type")) {
+		writeError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/merge-patch+json or application/json")
+		return
+	}
+
 	var req updateLotRequest
-	if err := decodeJSONBody(r, &req); err != nil {
+	if err := decodeMergePatch(r, &req, "location"); err != nil {
+		var patchErr mergePatchError
+		if errors.As(err, &patchErr) {
+			writeError(w, http.StatusBadRequest, patchErr.Error())
+			return
+		}
 		writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
-	purchasedAt, err := parseTimestamp(req.PurchasedAt)
-	if err != nil {
-		writeError(w, http.StatusBadRequest, "purchased_at must be RFC3339 or YYYY-MM-DD")
+	patch, message := parseLotPatch(req.AssetID, req.Quantity, req.UnitCost, req.PurchasedAt)
+	if message != "" {
+		writeError(w, http.StatusBadRequest, message)
 		return
 	}
-	if req.Quantity <= 0 {
-		writeError(w, http.StatusBadRequest, "quantity must be greater than 0")
-		return
+	if req.Location != nil {
+		location, ok := normalizeLocation(*req.Location)
+		if !ok && location != "" {
+			writeError(w, http.StatusBadRequest, "location must be 1 to 100 characters")
+			return
+		}
+		patch.Location = &location
 	}
-	if req.UnitCost < 0 {
-		writeError(w, http.StatusBadRequest, "unit_cost must be greater than or equal to 0")
-		return
+	if patch.AssetID != nil {
+		assets, err := s.DB.ListAssetsByIDs(r.Context(), []int64{*patch.AssetID})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to load assets")
+			return
+		}
+		if len(assets) == 0 {
+			writeError(w, http.StatusBadRequest, "asset_id does not reference an existing asset")
+			return
+		}
 	}
 
-	updated, err := s.DB.UpdateLotForUser(r.Context(), userID, lotID, req.Quantity, req.UnitCost, purchasedAt)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to update lot")
-		return
-	}
-	if !updated {
+	lot, err := s.DB.UpdateLotForUser(r.Context(), userID, lotID, patch, ifMatchRevision(r))
+	switch {
+	case errors.Is(err, db.ErrLotNotFound):
 		writeError(w, http.StatusNotFound, "lot not found")
 		return
+	case errors.Is(err, db.ErrLotRevisionMismatch):
+		s.writeLotPreconditionFailed(w, r, lot)
+		return
+	case errors.Is(err, db.ErrAssetNotFound):
+		writeError(w, http.StatusBadRequest, "asset_id does not reference an existing asset")
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "failed to update lot")
+		return
 	}
 
+	s.publishWebhookEvent(r.Context(), userID, db.WebhookEventLotUpdated, lotWebhookPayload{
+		LotID:       lot.ID,
+		AssetID:     lot.AssetID,
+		Quantity:    &lot.Quantity,
+		UnitCost:    &lot.UnitCost,
+		PurchasedAt: lot.PurchasedAt.UTC().Format(time.RFC3339),
+	})
+
+	w.Header().Set("ETag", lotETag(lot.Revision))
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -350,16 +605,21 @@ func (s *Server) handleDeleteLot(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	deleted, err := s.DB.DeleteLotForUser(r.Context(), userID, lotID)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to delete lot")
-		return
-	}
-	if !deleted {
+	lot, err := s.DB.DeleteLotForUser(r.Context(), userID, lotID, ifMatchRevision(r))
+	switch {
+	case errors.Is(err, db.ErrLotNotFound):
 		writeError(w, http.StatusNotFound, "lot not found")
 		return
+	case errors.Is(err, db.ErrLotRevisionMismatch):
+		s.writeLotPreconditionFailed(w, r, lot)
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "failed to delete lot")
+		return
 	}
 
+	s.publishWebhookEvent(r.Context(), userID, db.WebhookEventLotDeleted, lotWebhookPayload{LotID: lotID})
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -410,6 +670,45 @@ func (s *Server) handleSearchAssets(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, response)
 }
 
+func normalizeLocation(value string) (string, bool) {
+	location := strings.TrimSpace(value)
+	length := utf8.RuneCountInString(location)
+	return location, length > 0 && length <= maxLocationLength
+}
+
+func validateLotValues(quantity float64, unitCost float64) string {
+	if quantity <= 0 {
+		return "quantity must be greater than 0"
+	}
+	if unitCost < 0 {
+		return "unit_cost must be greater than or equal to 0"
+	}
+	return ""
+}
+
+// parseLotPatch validates the provided lot fields and converts them to a
+// db.LotPatch; nil inputs stay nil.
+func parseLotPatch(assetID *int64, quantity *float64, unitCost *float64, purchasedAt *string) (db.LotPatch, string) {
+	patch := db.LotPatch{AssetID: assetID, Quantity: quantity, UnitCost: unitCost}
+	if assetID != nil && *assetID <= 0 {
+		return db.LotPatch{}, "asset_id must be greater than 0"
+	}
+	if quantity != nil && *quantity <= 0 {
+		return db.LotPatch{}, "quantity must be greater than 0"
+	}
+	if unitCost != nil && *unitCost < 0 {
+		return db.LotPatch{}, "unit_cost must be greater than or equal to 0"
+	}
+	if purchasedAt != nil {
+		parsed, err := parseTimestamp(*purchasedAt)
+		if err != nil {
+			return db.LotPatch{}, "purchased_at must be RFC3339 or YYYY-MM-DD"
+		}
+		patch.PurchasedAt = &parsed
+	}
+	return patch, ""
+}
+
 func parseIDParam(r *http.Request, key string) (int64, error) {
 	value := strings.TrimSpace(chi.URLParam(r, key))
 	parsed, err := strconv.ParseInt(value, 10, 64)
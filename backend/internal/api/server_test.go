@@ -37,9 +37,10 @@ type mockStore struct {
 	positionsErr error
 	positionsUID string
 
-	lots    []db.Lot
-	lotsErr error
-	lotsUID string
+	lots      []db.Lot
+	lotsErr   error
+	lotsUID   string
+	lotsQuery db.LotQuery
 
 	assetsByID   map[int64]db.Asset
 	listIDsErr   error
@@ -56,17 +57,85 @@ type mockStore struct {
 	updatedQuantity    float64
 	updatedUnitCost    float64
 	updatedPurchasedAt time.Time
+	updatedPatch       db.LotPatch
 
 	deletedFound  bool
 	deleteErr     error
 	deletedLotID  int64
 	deletedUserID string
 
+	lotRevision      int64
+	expectedRevision int64
+
+	restoreErr    error
+	restoreWindow time.Duration
+	restoredLotID int64
+	lotEvents     []db.LotEvent
+
 	searchAssets []db.Asset
 	searchErr    error
 	searchQuery  string
 	searchType   string
 	searchLimit  int
+
+	alerts         []db.Alert
+	insertedAlerts []db.Alert
+	insertAlertErr error
+	alertPatch     db.AlertPatch
+	alertFound     bool
+	alertEvents    []db.AlertEvent
+	alertEventsMax int
+
+	watchlists          []db.Watchlist
+	insertedWatchlists  []db.Watchlist
+	watchlistPatch      db.WatchlistPatch
+	watchlistErr        error
+	watchlistFound      bool
+	watchlistAddedAsset int64
+
+	corporateActions        []db.CorporateAction
+	insertedCorporateAction db.CorporateAction
+	appliedActionID         int64
+	applyActionErr          error
+	adjustedLots            []db.CorporateActionAdjustment
+
+	incomeRecords   []db.Income
+	insertedIncome  db.Income
+	reinvestedLot   *db.Lot
+	insertIncomeErr error
+	incomePatch     db.IncomePatch
+	incomeFound     bool
+
+	transfers    []db.Transfer
+	transfer     db.Transfer
+	transferLegs []db.TransferLeg
+	transferErr  error
+
+	targets      []db.AllocationTarget
+	savedTargets []db.AllocationTarget
+	targetsErr   error
+	prices       map[int64]float64
+
+	benchmarks       []int64
+	savedBenchmarks  []int64
+	benchmarksErr    error
+	priceSeries      map[int64][]float64
+	priceSeriesTimes []time.Time
+
+	webhooks          []db.Webhook
+	insertedWebhooks  []db.Webhook
+	webhookPatch      db.WebhookPatch
+	webhookFound      bool
+	webhookDeliveries []db.WebhookDelivery
+	webhookEvents     []string
+	webhookEventErr   error
+	queuedWebhookIDs  []int64
+	queuedEventType   string
+
+	idempotencyKeys map[string]db.IdempotencyKey
+
+	batchOps []db.LotOperation
+	batchErr error
 }
 
 func (m *mockStore) FetchPositionsForUser(ctx context.Context, userID string) ([]db.Position, error) {
@@ -77,8 +146,9 @@ func (m *mockStore) FetchPositionsForUser(ctx context.Context, userID string) ([
 	return m.positions, nil
 }
 
-func (m *mockStore) ListLotsByUser(ctx context.Context, userID string) ([]db.Lot, error) {
-	m.lotsUID = userID
+func (m *mockStore) ListLots(ctx context.Context, query db.LotQuery) ([]db.Lot, error) {
+	m.lotsUID = query.UserID
+	m.lotsQuery = query
 	if m.lotsErr != nil {
 		return nil, m.lotsErr
 	}
@@ -96,25 +166,76 @@ func (m *mockStore) InsertLot(ctx context.Context, lot db.Lot) (int64, error) {
 	return m.insertLotID, nil
 }
 
-func (m *mockStore) UpdateLotForUser(ctx context.Context, userID string, lotID int64, quantity float64, unitCost float64, purchasedAt time.Time) (bool, error) {
+func (m *mockStore) UpdateLotForUser(ctx context.Context, userID string, lotID int64, patch db.LotPatch, expectedRevision int64) (db.Lot, error) {
 	m.updatedUserID = userID
 	m.updatedLotID = lotID
-	m.updatedQuantity = quantity
-	m.updatedUnitCost = unitCost
-	m.updatedPurchasedAt = purchasedAt
+	m.updatedPatch = patch
+	m.expectedRevision = expectedRevision
 	if m.updateErr != nil {
-		return false, m.updateErr
+		return db.Lot{}, m.updateErr
 	}
-	return m.updatedFound, nil
+	if !m.updatedFound {
+		return db.Lot{}, db.ErrLotNotFound
+	}
+	current := db.Lot{ID: lotID, UserID: userID, AssetID: 1, Quantity: 1, Revision: m.lotRevision}
+	if expectedRevision != 0 && expectedRevision != m.lotRevision {
+		return current, db.ErrLotRevisionMismatch
+	}
+
+	updated := current
+	if patch.AssetID != nil {
+		updated.AssetID = *patch.AssetID
+	}
+	if patch.Quantity != nil {
+		m.updatedQuantity = *patch.Quantity
+		updated.Quantity = *patch.Quantity
+	}
+	if patch.UnitCost != nil {
+		m.updatedUnitCost = *patch.UnitCost
+		updated.UnitCost = *patch.UnitCost
+	}
+	if patch.PurchasedAt != nil {
+		m.updatedPurchasedAt = *patch.PurchasedAt
+		updated.PurchasedAt = *patch.PurchasedAt
+	}
+	updated.Revision++
+	return updated, nil
 }
 
-func (m *mockStore) DeleteLotForUser(ctx context.Context, userID string, lotID int64) (bool, error) {
+func (m *mockStore) DeleteLotForUser(ctx context.Context, userID string, lotID int64, expectedRevision int64) (db.Lot, error) {
 	m.deletedUserID = userID
 	m.deletedLotID = lotID
+	m.expectedRevision = expectedRevision
 	if m.deleteErr != nil {
-		return false, m.deleteErr
+		return db.Lot{}, m.deleteErr
+	}
+	if !m.deletedFound {
+		return db.Lot{}, db.ErrLotNotFound
+	}
+	current := db.Lot{ID: lotID, UserID: userID, AssetID: 1, Quantity: 1, Revision: m.lotRevision}
+	if expectedRevision != 0 && expectedRevision != m.lotRevision {
+		return current, db.ErrLotRevisionMismatch
 	}
-	return m.deletedFound, nil
+	return current, nil
+}
+
+func (m *mockStore) RestoreLotForUser(ctx context.Context, userID string, lotID int64, window time.Duration) (db.Lot, error) {
+	m.restoreWindow = window
+	if m.restoreErr != nil {
+		return db.Lot{}, m.restoreErr
+	}
+	m.restoredLotID = lotID
+	return db.Lot{ID: lotID, UserID: userID, AssetID: 1, Quantity: 1, Revision: m.lotRevision + 1}, nil
+}
+
+func (m *mockStore) ListLotEventsForUser(ctx context.Context, userID string, lotID int64) ([]db.LotEvent, error) {
+	var out []db.LotEvent
+	for _, event := range m.lotEvents {
+		if event.UserID == userID && event.LotID == lotID {
+			out = append(out, event)
+		}
+	}
+	return out, nil
 }
 
 func (m *mockStore) SearchAssets(ctx context.Context, query string, assetType string, limit int) ([]db.Asset, error) {
@@ -147,6 +268,260 @@ func (m *mockStore) ListAssetsByIDs(ctx context.Context, ids []int64) ([]db.Asse
 	return out, nil
 }
 
+func (m *mockStore) ListAlertsByUser(ctx context.Context, userID string) ([]db.Alert, error) {
+	return m.alerts, nil
+}
+
+func (m *mockStore) InsertAlert(ctx context.Context, alert db.Alert) (int64, error) {
+	if m.insertAlertErr != nil {
+		return 0, m.insertAlertErr
+	}
+	m.insertedAlerts = append(m.insertedAlerts, alert)
+	return int64(len(m.insertedAlerts)), nil
+}
+
+func (m *mockStore) UpdateAlertForUser(ctx context.Context, userID string, alertID int64, patch db.AlertPatch) (bool, error) {
+	m.alertPatch = patch
+	return m.alertFound, nil
+}
+
+func (m *mockStore) DeleteAlertForUser(ctx context.Context, userID string, alertID int64) (bool, error) {
+	return m.alertFound, nil
+}
+
+func (m *mockStore) ListAlertEventsByUser(ctx context.Context, userID string, limit int) ([]db.AlertEvent, error) {
+	m.alertEventsMax = limit
+	return m.alertEvents, nil
+}
+
+func (m *mockStore) ListWatchlistsByUser(ctx context.Context, userID string) ([]db.Watchlist, error) {
+	return m.watchlists, nil
+}
+
+func (m *mockStore) InsertWatchlist(ctx context.Context, watchlist db.Watchlist) (int64, error) {
+	if m.watchlistErr != nil {
+		return 0, m.watchlistErr
+	}
+	m.insertedWatchlists = append(m.insertedWatchlists, watchlist)
+	return int64(len(m.insertedWatchlists)), nil
+}
+
+func (m *mockStore) UpdateWatchlistForUser(ctx context.Context, userID string, watchlistID int64, patch db.WatchlistPatch) error {
+	m.watchlistPatch = patch
+	if m.watchlistErr != nil {
+		return m.watchlistErr
+	}
+	if !m.watchlistFound {
+		return db.ErrWatchlistNotFound
+	}
+	return nil
+}
+
+func (m *mockStore) DeleteWatchlistForUser(ctx context.Context, userID string, watchlistID int64) (bool, error) {
+	return m.watchlistFound, nil
+}
+
+func (m *mockStore) AddWatchlistAsset(ctx context.Context, userID string, watchlistID int64, assetID int64) error {
+	if m.watchlistErr != nil {
+		return m.watchlistErr
+	}
+	if !m.watchlistFound {
+		return db.ErrWatchlistNotFound
+	}
+	m.watchlistAddedAsset = assetID
+	return nil
+}
+
+func (m *mockStore) RemoveWatchlistAsset(ctx context.Context, userID string, watchlistID int64, assetID int64) (bool, error) {
+	return m.watchlistFound, nil
+}
+
+func (m *mockStore) InsertCorporateAction(ctx context.Context, action db.CorporateAction) (int64, error) {
+	m.insertedCorporateAction = action
+	return 9, nil
+}
+
+func (m *mockStore) ListCorporateActions(ctx context.Context, assetID int64, limit int) ([]db.CorporateAction, error) {
+	return m.corporateActions, nil
+}
+
+func (m *mockStore) ApplyCorporateAction(ctx context.Context, actionID int64) ([]db.CorporateActionAdjustment, error) {
+	m.appliedActionID = actionID
+	if m.applyActionErr != nil {
+		return nil, m.applyActionErr
+	}
+	return m.adjustedLots, nil
+}
+
+func (m *mockStore) ListIncomeForUser(ctx context.Context, userID string, assetID int64, limit int) ([]db.Income, error) {
+	return m.incomeRecords, nil
+}
+
+func (m *mockStore) InsertIncome(ctx context.Context, income db.Income, reinvest *db.Lot) (db.Income, error) {
+	m.insertedIncome = income
+	m.reinvestedLot = reinvest
+	if m.insertIncomeErr != nil {
+		return db.Income{}, m.insertIncomeErr
+	}
+	income.ID = 21
+	if reinvest != nil {
+		income.LotID = sql.NullInt64{Int64: 100, Valid: true}
+	}
+	return income, nil
+}
+
+func (m *mockStore) UpdateIncomeForUser(ctx context.Context, userID string, incomeID int64, patch db.IncomePatch) (bool, error) {
+	m.incomePatch = patch
+	return m.incomeFound, nil
+}
+
+func (m *mockStore) DeleteIncomeForUser(ctx context.Context, userID string, incomeID int64) (bool, error) {
+	return m.incomeFound, nil
+}
+
+func (m *mockStore) TransferLots(ctx context.Context, transfer db.Transfer) (db.Transfer, error) {
+	m.transfer = transfer
+	if m.transferErr != nil {
+		return db.Transfer{}, m.transferErr
+	}
+	transfer.ID = 31
+	if m.transferLegs != nil {
+		transfer.Legs = m.transferLegs
+	}
+	return transfer, nil
+}
+
+func (m *mockStore) ListTransfersForUser(ctx context.Context, userID string, limit int) ([]db.Transfer, error) {
+	return m.transfers, nil
+}
+
+func (m *mockStore) ListAllocationTargets(ctx context.Context, userID string) ([]db.AllocationTarget, error) {
+	return m.targets, nil
+}
+
+func (m *mockStore) ReplaceAllocationTargets(ctx context.Context, userID string, targets []db.AllocationTarget) error {
+	m.savedTargets = targets
+	return m.targetsErr
+}
+
+func (m *mockStore) ListCurrentPrices(ctx context.Context, assetIDs []int64) (map[int64]float64, error) {
+	prices := make(map[int64]float64)
+	for _, id := range assetIDs {
+		if price, ok := m.prices[id]; ok {
+			prices[id] = price
+		}
+	}
+	return prices, nil
+}
+
+func (m *mockStore) ListBenchmarks(ctx context.Context, userID string) ([]int64, error) {
+	return m.benchmarks, nil
+}
+
+func (m *mockStore) ReplaceBenchmarks(ctx context.Context, userID string, assetIDs []int64) error {
+	m.savedBenchmarks = assetIDs
+	return m.benchmarksErr
+}
+
+// ListPriceSeries treats a zero in priceSeries as a missing price.
+func (m *mockStore) ListPriceSeries(ctx context.Context, assetIDs []int64, times []time.Time) ([]db.PricePoint, error) {
+	m.priceSeriesTimes = times
+	var points []db.PricePoint
+	for _, assetID := range assetIDs {
+		series := m.priceSeries[assetID]
+		for i, at := range times {
+			point := db.PricePoint{AssetID: assetID, At: at}
+			if i < len(series) && series[i] != 0 {
+				point.Price = sql.NullFloat64{Float64: series[i], Valid: true}
+			}
+			points = append(points, point)
+		}
+	}
+	return points, nil
+}
+
+func (m *mockStore) ListLotsByUser(ctx context.Context, userID string) ([]db.Lot, error) {
+	return m.lots, nil
+}
+
+func (m *mockStore) ListWebhooksByUser(ctx context.Context, userID string) ([]db.Webhook, error) {
+	return m.webhooks, nil
+}
+
+func (m *mockStore) InsertWebhook(ctx context.Context, webhook db.Webhook) (int64, error) {
+	m.insertedWebhooks = append(m.insertedWebhooks, webhook)
+	return int64(len(m.insertedWebhooks)), nil
+}
+
+func (m *mockStore) UpdateWebhookForUser(ctx context.Context, userID string, webhookID int64, patch db.WebhookPatch) (bool, error) {
+	m.webhookPatch = patch
+	return m.webhookFound, nil
+}
+
+func (m *mockStore) DeleteWebhookForUser(ctx context.Context, userID string, webhookID int64) (bool, error) {
+	return m.webhookFound, nil
+}
+
+func (m *mockStore) ListWebhookDeliveriesForUser(ctx context.Context, userID string, webhookID int64, limit int) ([]db.WebhookDelivery, error) {
+	return m.webhookDeliveries, nil
+}
+
+func (m *mockStore) EnqueueWebhookEvent(ctx context.Context, userID string, eventType string, payload any) error {
+	m.webhookEvents = append(m.webhookEvents, eventType)
+	return m.webhookEventErr
+}
+
+func (m *mockStore) EnqueueWebhookDeliveries(ctx context.Context, webhookIDs []int64, userID string, eventType string, payload any) (int64, error) {
+	m.queuedWebhookIDs = append(m.queuedWebhookIDs, webhookIDs...)
+	m.queuedEventType = eventType
+	if !m.webhookFound {
+		return 0, nil
+	}
+	return int64(len(webhookIDs)), nil
+}
+
+func (m *mockStore) ApplyLotBatch(ctx context.Context, userID string, ops []db.LotOperation) ([]db.LotOperationResult, error) {
+	m.batchOps = append([]db.LotOperation(nil), ops...)
+	if m.batchErr != nil {
+		return nil, m.batchErr
+	}
+	results := make([]db.LotOperationResult, 0, len(ops))
+	for i, op := range ops {
+		id := op.Lot.ID
+		if op.Kind == db.LotOperationCreate {
+			id = int64(100 + i)
+		}
+		results = append(results, db.LotOperationResult{Kind: op.Kind, LotID: id})
+	}
+	return results, nil
+}
+
+func (m *mockStore) ReserveIdempotencyKey(ctx context.Context, key db.IdempotencyKey, ttl time.Duration, staleAfter time.Duration) (db.IdempotencyKey, bool, error) {
+	if m.idempotencyKeys == nil {
+		m.idempotencyKeys = map[string]db.IdempotencyKey{}
+	}
+	if existing, ok := m.idempotencyKeys[key.UserID+"/"+key.Key]; ok {
+		return existing, false, nil
+	}
+	m.idempotencyKeys[key.UserID+"/"+key.Key] = key
+	return key, true, nil
+}
+
+func (m *mockStore) CompleteIdempotencyKey(ctx context.Context, userID string, key string, statusCode int, contentType string, headers map[string]string, body []byte) error {
+	stored := m.idempotencyKeys[userID+"/"+key]
+	stored.StatusCode = sql.NullInt32{Int32: int32(statusCode), Valid: true}
+	stored.ResponseContentType = sql.NullString{String: contentType, Valid: contentType != ""}
+	stored.ResponseHeaders = headers
+	stored.ResponseBody = append([]byte(nil), body...)
+	m.idempotencyKeys[userID+"/"+key] = stored
+	return nil
+}
+
+func (m *mockStore) ReleaseIdempotencyKey(ctx context.Context, userID string, key string) error {
+	delete(m.idempotencyKeys, userID+"/"+key)
+	return nil
+}
+
 func newAPIRouter(store Store, verifier auth.Verifier) http.Handler {
 	r := chi.NewRouter()
 	NewServer(store, verifier).Mount(r)
@@ -194,12 +569,16 @@ func TestAPIListPositionsSuccess(t *testing.T) {
 
 	store := &mockStore{
 		positions: []db.Position{{
-			UserID:       "user-1",
-			AssetID:      10,
-			TotalQty:     1.5,
-			AvgCost:      100,
-			CurrentPrice: sql.NullFloat64{Float64: 150, Valid: true},
-			UnrealizedPL: sql.NullFloat64{Float64: 75, Valid: true},
+			UserID:         "user-1",
+			AssetID:        10,
+			TotalQty:       1.5,
+			AvgCost:        100,
+			CurrentPrice:   sql.NullFloat64{Float64: 150, Valid: true},
+			UnrealizedPL:   sql.NullFloat64{Float64: 75, Valid: true},
+			IncomeReceived: 12.5,
+			Change24hPct:   sql.NullFloat64{Float64: -1.25, Valid: true},
+			MarketCap:      sql.NullFloat64{Float64: 1.2e12, Valid: true},
+			PriceAsOf:      sql.NullTime{Time: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC), Valid: true},
 		}},
 		assetsByID: map[int64]db.Asset{
 			10: {ID: 10, Symbol: "BTC", Name: "Bitcoin", Type: db.AssetTypeCrypto},
@@ -229,6 +608,15 @@ func TestAPIListPositionsSuccess(t *testing.T) {
 	if got[0].CurrentPrice == nil || *got[0].CurrentPrice != 150 {
 		t.Fatalf("unexpected current_price: %+v", got[0].CurrentPrice)
 	}
+	if got[0].IncomeReceived != 12.5 {
+		t.Fatalf("expected income_received 12.5, got %v", got[0].IncomeReceived)
+	}
+	if got[0].Change24hPct == nil || *got[0].Change24hPct != -1.25 || got[0].MarketCap == nil || got[0].Volume24h != nil {
+		t.Fatalf("unexpected market data: %+v", got[0])
+	}
+	if got[0].PriceAsOf == nil || *got[0].PriceAsOf != "2026-10-18T12:00:00Z" {
+		t.Fatalf("unexpected price_as_of: %v", got[0].PriceAsOf)
+	}
 }
 
 func TestAPICreateLotValidation(t *testing.T) {
@@ -354,10 +742,11 @@ func TestAPIListLotsSuccess(t *testing.T) {
 		t.Fatalf("expected user id user-1, got %q", store.lotsUID)
 	}
 
-	var got []lotResponse
-	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+	var page lotPageResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &page); err != nil {
 		t.Fatalf("failed to decode response: %v", err)
 	}
+	got := page.Items
 	if len(got) != 1 {
 		t.Fatalf("expected 1 lot, got %d", len(got))
 	}
@@ -367,6 +756,9 @@ func TestAPIListLotsSuccess(t *testing.T) {
 	if got[0].PurchasedAt != purchasedAt.Format(time.RFC3339) {
 		t.Fatalf("unexpected purchased_at: %q", got[0].PurchasedAt)
 	}
+	if page.NextCursor != nil {
+		t.Fatalf("expected no next_cursor, got %q", *page.NextCursor)
+	}
 }
 
 func TestAPIListLotsStoreError(t *testing.T) {
@@ -498,6 +890,124 @@ func TestAPIUpdateLotStoreError(t *testing.T) {
 	}
 }
 
+func TestAPIUpdateLotPartialPatch(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{updatedFound: true}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	req := newRequest(t, http.MethodPatch, "/api/v1/lots/55", "good", []byte(`{"unit_cost":41000}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", res.Code, res.Body.String())
+	}
+	patch := store.updatedPatch
+	if patch.UnitCost == nil || *patch.UnitCost != 41000 {
+		t.Fatalf("expected unit_cost in patch, got %+v", patch)
+	}
+	if patch.AssetID != nil || patch.Quantity != nil || patch.PurchasedAt != nil || patch.Location != nil {
+		t.Fatalf("expected absent fields to stay nil, got %+v", patch)
+	}
+}
+
+func TestAPIUpdateLotMovesAsset(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{
+		updatedFound: true,
+		assetsByID:   map[int64]db.Asset{2: {ID: 2, Symbol: "ETH", Type: db.AssetTypeCrypto}},
+	}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodPatch, "/api/v1/lots/55", "good", []byte(`{"asset_id":2}`)))
+
+	if res.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", res.Code, res.Body.String())
+	}
+	if store.updatedPatch.AssetID == nil || *store.updatedPatch.AssetID != 2 {
+		t.Fatalf("expected asset_id 2 in patch, got %+v", store.updatedPatch)
+	}
+}
+
+func TestAPIUpdateLotUnknownAsset(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{updatedFound: true}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodPatch, "/api/v1/lots/55", "good", []byte(`{"asset_id":99}`)))
+
+	if res.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", res.Code)
+	}
+	if store.updatedLotID != 0 {
+		t.Fatal("expected no update for an unknown asset")
+	}
+}
+
+func TestAPIUpdateLotRejectsNullAndEmptyPatch(t *testing.T) {
+	t.Parallel()
+
+	for _, body := range []string{`{"quantity":null}`, `{}`} {
+		store := &mockStore{updatedFound: true}
+		router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+		res := httptest.NewRecorder()
+
+		router.ServeHTTP(res, newRequest(t, http.MethodPatch, "/api/v1/lots/55", "good", []byte(body)))
+
+		if res.Code != http.StatusBadRequest {
+			t.Fatalf("body %s: expected 400, got %d", body, res.Code)
+		}
+		if store.updatedLotID != 0 {
+			t.Fatalf("body %s: expected no update", body)
+		}
+	}
+}
+
+func TestAPIUpdateLotNullLocationClearsIt(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{updatedFound: true}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	req := newRequest(t, http.MethodPatch, "/api/v1/lots/55", "good", []byte(`{"location":null,"quantity":3}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", res.Code, res.Body.String())
+	}
+	patch := store.updatedPatch
+	if patch.Location == nil || *patch.Location != "" {
+		t.Fatalf("expected null location to clear it, got %+v", patch)
+	}
+	if patch.Quantity == nil || *patch.Quantity != 3 {
+		t.Fatalf("expected quantity in patch, got %+v", patch)
+	}
+}
+
+func TestAPIUpdateLotUnsupportedContentType(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{updatedFound: true}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	req := newRequest(t, http.MethodPatch, "/api/v1/lots/55", "good", []byte(`{"quantity":1}`))
+	req.Header.Set("Content-Type", "text/plain")
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected 415, got %d", res.Code)
+	}
+}
+
 func TestAPIDeleteLotSuccess(t *testing.T) {
 	t.Parallel()
 
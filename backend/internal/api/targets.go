@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"asset-tracker/internal/db"
+)
+
+const maxAllocationTargets = 200
+
+// weightTolerance absorbs float rounding when target weights are summed.
+const weightTolerance = 1e-9
+
+type allocationTargetItem struct {
+	AssetID   int64   `json:"asset_id,omitempty"`
+	AssetType string  `json:"asset_type,omitempty"`
+	Weight    float64 `json:"weight"`
+}
+
+type allocationTargetsBody struct {
+	Targets []allocationTargetItem `json:"targets"`
+}
+
+func (s *Server) handleListTargets(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	targets, err := s.DB.ListAllocationTargets(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load targets")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAllocationTargetsBody(targets))
+}
+
+// handleReplaceTargets replaces every target of the user; an empty list
+// clears them.
+func (s *Server) handleReplaceTargets(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	var req allocationTargetsBody
+	if err := decodeJSONBody(r, &req); err != nil || req.Targets == nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	targets, message := parseAllocationTargets(req.Targets)
+	if message != "" {
+		writeError(w, http.StatusBadRequest, message)
+		return
+	}
+
+	err := s.DB.ReplaceAllocationTargets(r.Context(), userID, targets)
+	switch {
+	case errors.Is(err, db.ErrAssetNotFound):
+		writeError(w, http.StatusBadRequest, "asset_id must reference an existing asset")
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "failed to save targets")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAllocationTargetsBody(targets))
+}
+
+// parseAllocationTargets validates items. Each names either an asset or an
+// asset type, at most once, and the weights add up to at most 1.
+func parseAllocationTargets(items []allocationTargetItem) ([]db.AllocationTarget, string) {
+	if len(items) > maxAllocationTargets {
+		return nil, "targets must hold at most 200 entries"
+	}
+
+	seenAssets := make(map[int64]bool, len(items))
+	seenTypes := make(map[db.AssetType]bool, 2)
+	targets := make([]db.AllocationTarget, 0, len(items))
+	var total float64
+	for _, item := range items {
+		if item.Weight <= 0 || item.Weight > 1 {
+			return nil, "weight must be greater than 0 and at most 1"
+		}
+		target := db.AllocationTarget{Weight: item.Weight}
+		assetType := strings.ToLower(strings.TrimSpace(item.AssetType))
+		switch {
+		case item.AssetID != 0 && assetType != "":
+			return nil, "each target must set either asset_id or asset_type, not both"
+		case item.AssetID > 0:
+			if seenAssets[item.AssetID] {
+				return nil, "targets must not repeat an asset_id"
+			}
+			seenAssets[item.AssetID] = true
+			target.AssetID = item.AssetID
+		case assetType == string(db.AssetTypeCrypto) || assetType == string(db.AssetTypeStock):
+			if seenTypes[db.AssetType(assetType)] {
+				return nil, "targets must not repeat an asset_type"
+			}
+			seenTypes[db.AssetType(assetType)] = true
+			target.AssetType = db.AssetType(assetType)
+		default:
+			return nil, "each target must set a positive asset_id or an asset_type of crypto or stock"
+		}
+		total += item.Weight
+		targets = append(targets, target)
+	}
+	if total > 1+weightTolerance {
+		return nil, "weights must add up to at most 1"
+	}
+	return targets, ""
+}
+
+func newAllocationTargetsBody(targets []db.AllocationTarget) allocationTargetsBody {
+	body := allocationTargetsBody{Targets: make([]allocationTargetItem, 0, len(targets))}
+	for _, target := range targets {
+		body.Targets = append(body.Targets, allocationTargetItem{
+			AssetID:   target.AssetID,
+			AssetType: string(target.AssetType),
+			Weight:    target.Weight,
+		})
+	}
+	return body
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"asset-tracker/internal/auth"
+	"asset-tracker/internal/db"
+)
+
+func TestAPIReplaceTargets(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	body := []byte(`{"targets":[{"asset_id":1,"weight":0.5},{"asset_type":"Stock","weight":0.4}]}`)
+	router.ServeHTTP(res, newRequest(t, http.MethodPut, "/api/v1/targets", "good", body))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", res.Code, res.Body.String())
+	}
+	if len(store.savedTargets) != 2 || store.savedTargets[0].AssetID != 1 || store.savedTargets[1].AssetType != db.AssetTypeStock {
+		t.Fatalf("unexpected saved targets: %+v", store.savedTargets)
+	}
+
+	var got allocationTargetsBody
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got.Targets) != 2 || got.Targets[1].AssetType != "stock" || got.Targets[1].AssetID != 0 {
+		t.Fatalf("unexpected response: %+v", got)
+	}
+}
+
+func TestAPIReplaceTargetsErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		body string
+		err  error
+	}{
+		{body: `{}`},
+		{body: `{"targets":[{"asset_id":1,"weight":0}]}`},
+		{body: `{"targets":[{"asset_id":1,"weight":0.7},{"asset_id":2,"weight":0.4}]}`},
+		{body: `{"targets":[{"asset_id":1,"weight":0.2},{"asset_id":1,"weight":0.2}]}`},
+		{body: `{"targets":[{"asset_id":1,"asset_type":"crypto","weight":0.2}]}`},
+		{body: `{"targets":[{"asset_type":"bond","weight":0.2}]}`},
+		{body: `{"targets":[{"asset_id":99,"weight":0.2}]}`, err: db.ErrAssetNotFound},
+	}
+
+	for _, tc := range cases {
+		store := &mockStore{targetsErr: tc.err}
+		router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+		res := httptest.NewRecorder()
+
+		router.ServeHTTP(res, newRequest(t, http.MethodPut, "/api/v1/targets", "good", []byte(tc.body)))
+		if res.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", tc.body, res.Code)
+		}
+	}
+}
+
+func TestAPIListTargets(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{targets: []db.AllocationTarget{{AssetType: db.AssetTypeCrypto, Weight: 0.3}}}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodGet, "/api/v1/targets", "good", nil))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.Code)
+	}
+	var got allocationTargetsBody
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got.Targets) != 1 || got.Targets[0].AssetType != "crypto" || got.Targets[0].Weight != 0.3 {
+		t.Fatalf("unexpected targets: %+v", got)
+	}
+}
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"asset-tracker/internal/db"
+)
+
+type transferLegResponse struct {
+	SourceLotID      int64   `json:"source_lot_id"`
+	DestinationLotID int64   `json:"destination_lot_id"`
+	FromLocation     *string `json:"from_location"`
+	Quantity         float64 `json:"quantity"`
+	ReceivedQuantity float64 `json:"received_quantity"`
+}
+
+type transferResponse struct {
+	ID            int64                 `json:"id"`
+	AssetID       int64                 `json:"asset_id"`
+	FromLocation  *string               `json:"from_location"`
+	ToLocation    string                `json:"to_location"`
+	Quantity      float64               `json:"quantity"`
+	FeeQuantity   float64               `json:"fee_quantity"`
+	TransferredAt string                `json:"transferred_at"`
+	Notes         *string               `json:"notes"`
+	CreatedAt     string                `json:"created_at"`
+	Legs          []transferLegResponse `json:"legs"`
+}
+
+type transferLotRequest struct {
+	LotID    int64    `json:"lot_id"`
+	Quantity *float64 `json:"quantity"`
+}
+
+type createTransferRequest struct {
+	AssetID       int64                `json:"asset_id"`
+	FromLocation  *string              `json:"from_location"`
+	ToLocation    string               `json:"to_location"`
+	Quantity      *float64             `json:"quantity"`
+	Lots          []transferLotRequest `json:"lots"`
+	FeeQuantity   float64              `json:"fee_quantity"`
+	TransferredAt string               `json:"transferred_at"`
+	Notes         *string              `json:"notes"`
+}
+
+func (s *Server) handleListTransfers(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	limit := 50
+	if rawLimit := strings.TrimSpace(r.URL.Query().Get("limit")); rawLimit != "" {
+		parsedLimit, err := strconv.Atoi(rawLimit)
+		if err != nil || parsedLimit <= 0 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		if parsedLimit > 200 {
+			parsedLimit = 200
+		}
+		limit = parsedLimit
+	}
+
+	transfers, err := s.DB.ListTransfersForUser(r.Context(), userID, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load transfers")
+		return
+	}
+
+	response := make([]transferResponse, 0, len(transfers))
+	for _, transfer := range transfers {
+		response = append(response, newTransferResponse(transfer))
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleCreateTransfer(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	var req createTransferRequest
+	if err := decodeJSONBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	transfer, message := parseTransfer(req)
+	if message != "" {
+		writeError(w, http.StatusBadRequest, message)
+		return
+	}
+	transfer.UserID = userID
+
+	stored, err := s.DB.TransferLots(r.Context(), transfer)
+	switch {
+	case errors.Is(err, db.ErrLotNotFound):
+		writeError(w, http.StatusBadRequest, "lots must reference existing lots of the asset")
+		return
+	case errors.Is(err, db.ErrTransferSameLocation):
+		writeError(w, http.StatusBadRequest, "a lot is already at to_location")
+		return
+	case errors.Is(err, db.ErrTransferFeeTooLarge):
+		writeError(w, http.StatusBadRequest, "fee_quantity must be less than the transferred quantity")
+		return
+	case errors.Is(err, db.ErrAssetNotFound):
+		writeError(w, http.StatusBadRequest, "asset_id must reference an existing asset")
+		return
+	case errors.Is(err, db.ErrInsufficientQuantity):
+		writeError(w, http.StatusConflict, "not enough quantity to transfer")
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "failed to transfer lots")
+		return
+	}
+
+	for i := range stored.Legs {
+		s.publishTransferLegEvents(r, userID, stored.AssetID, &stored.Legs[i])
+	}
+
+	writeJSON(w, http.StatusCreated, newTransferResponse(stored))
+}
+
+// publishTransferLegEvents reports a relabelled lot as updated, and a partly
+// moved one as a reduced source lot plus a created destination lot.
+func (s *Server) publishTransferLegEvents(r *http.Request, userID string, assetID int64, leg *db.TransferLeg) {
+	if leg.DestinationLotID == leg.SourceLotID {
+		s.publishWebhookEvent(r.Context(), userID, db.WebhookEventLotUpdated, lotWebhookPayload{
+			LotID:    leg.SourceLotID,
+			Quantity: &leg.ReceivedQuantity,
+			UnitCost: &leg.UnitCost,
+		})
+		return
+	}
+	s.publishWebhookEvent(r.Context(), userID, db.WebhookEventLotUpdated, lotWebhookPayload{
+		LotID:    leg.SourceLotID,
+		Quantity: &leg.SourceQuantity,
+	})
+	s.publishWebhookEvent(r.Context(), userID, db.WebhookEventLotCreated, lotWebhookPayload{
+		LotID:       leg.DestinationLotID,
+		AssetID:     assetID,
+		Quantity:    &leg.ReceivedQuantity,
+		UnitCost:    &leg.UnitCost,
+		PurchasedAt: leg.PurchasedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// parseTransfer validates req. A transfer either draws quantity FIFO from
+// from_location or names specific lots, never both.
+func parseTransfer(req createTransferRequest) (db.Transfer, string) {
+	if req.AssetID <= 0 {
+		return db.Transfer{}, "asset_id must be greater than 0"
+	}
+	toLocation, ok := normalizeLocation(req.ToLocation)
+	if !ok {
+		return db.Transfer{}, "to_location must be 1 to 100 characters"
+	}
+	if req.FeeQuantity < 0 {
+		return db.Transfer{}, "fee_quantity must be greater than or equal to 0"
+	}
+
+	transfer := db.Transfer{
+		AssetID:       req.AssetID,
+		ToLocation:    toLocation,
+		FeeQuantity:   req.FeeQuantity,
+		TransferredAt: time.Now().UTC(),
+	}
+	if strings.TrimSpace(req.TransferredAt) != "" {
+		transferredAt, err := parseTimestamp(req.TransferredAt)
+		if err != nil {
+			return db.Transfer{}, "transferred_at must be RFC3339 or YYYY-MM-DD"
+		}
+		transfer.TransferredAt = transferredAt
+	}
+	if req.Notes != nil {
+		if notes := strings.TrimSpace(*req.Notes); notes != "" {
+			transfer.Notes = sql.NullString{String: notes, Valid: true}
+		}
+	}
+
+	if len(req.Lots) > 0 {
+		if req.Quantity != nil || req.FromLocation != nil {
+			return db.Transfer{}, "quantity and from_location cannot be combined with lots"
+		}
+		seen := make(map[int64]bool, len(req.Lots))
+		for _, item := range req.Lots {
+			if item.LotID <= 0 {
+				return db.Transfer{}, "lots[].lot_id must be greater than 0"
+			}
+			if seen[item.LotID] {
+				return db.Transfer{}, "lots must not repeat a lot_id"
+			}
+			seen[item.LotID] = true
+			leg := db.TransferLeg{SourceLotID: item.LotID}
+			if item.Quantity != nil {
+				if *item.Quantity <= 0 {
+					return db.Transfer{}, "lots[].quantity must be greater than 0"
+				}
+				leg.Quantity = *item.Quantity
+			}
+			transfer.Legs = append(transfer.Legs, leg)
+		}
+		return transfer, ""
+	}
+
+	if req.Quantity == nil || *req.Quantity <= 0 {
+		return db.Transfer{}, "quantity must be greater than 0 when lots are not given"
+	}
+	if req.FeeQuantity >= *req.Quantity {
+		return db.Transfer{}, "fee_quantity must be less than quantity"
+	}
+	transfer.Quantity = *req.Quantity
+	if req.FromLocation != nil && strings.TrimSpace(*req.FromLocation) != "" {
+		fromLocation, ok := normalizeLocation(*req.FromLocation)
+		if !ok {
+			return db.Transfer{}, "from_location must be 1 to 100 characters"
+		}
+		if fromLocation == toLocation {
+			return db.Transfer{}, "from_location and to_location must differ"
+		}
+		transfer.FromLocation = sql.NullString{String: fromLocation, Valid: true}
+	}
+	return transfer, ""
+}
+
+func newTransferResponse(transfer db.Transfer) transferResponse {
+	response := transferResponse{
+		ID:            transfer.ID,
+		AssetID:       transfer.AssetID,
+		ToLocation:    transfer.ToLocation,
+		Quantity:      transfer.Quantity,
+		FeeQuantity:   transfer.FeeQuantity,
+		TransferredAt: transfer.TransferredAt.UTC().Format(time.RFC3339),
+		CreatedAt:     transfer.CreatedAt.UTC().Format(time.RFC3339),
+		Legs:          make([]transferLegResponse, 0, len(transfer.Legs)),
+	}
+	if transfer.FromLocation.Valid {
+		response.FromLocation = &transfer.FromLocation.String
+	}
+	if transfer.Notes.Valid {
+		response.Notes = &transfer.Notes.String
+	}
+	for _, leg := range transfer.Legs {
+		item := transferLegResponse{
+			SourceLotID:      leg.SourceLotID,
+			DestinationLotID: leg.DestinationLotID,
+			Quantity:         leg.Quantity,
+			ReceivedQuantity: leg.ReceivedQuantity,
+		}
+		if leg.FromLocation.Valid {
+			item.FromLocation = &leg.FromLocation.String
+		}
+		response.Legs = append(response.Legs, item)
+	}
+	return response
+}
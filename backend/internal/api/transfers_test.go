@@ -0,0 +1,158 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"asset-tracker/internal/auth"
+	"asset-tracker/internal/db"
+)
+
+func TestAPILotLocation(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{insertLotID: 99, updatedFound: true}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	body := []byte(`{"asset_id":1,"quantity":0.25,"unit_cost":38000,"purchased_at":"2026-02-16","location":" Coinbase "}`)
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/lots", "good", body))
+	if res.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", res.Code, res.Body.String())
+	}
+	if got := store.insertedLots[0].Location; !got.Valid || got.String != "Coinbase" {
+		t.Fatalf("unexpected inserted location: %+v", got)
+	}
+
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, newRequest(t, http.MethodPatch, "/api/v1/lots/55", "good", []byte(`{"location":""}`)))
+	if res.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", res.Code, res.Body.String())
+	}
+	if store.updatedPatch.Location == nil || *store.updatedPatch.Location != "" {
+		t.Fatalf("expected location to be cleared, got %+v", store.updatedPatch)
+	}
+}
+
+func TestAPICreateTransferFIFO(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	body := []byte(`{"asset_id":1,"from_location":"Coinbase","to_location":"Ledger","quantity":0.5,"fee_quantity":0.001,"transferred_at":"2026-03-01"}`)
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/transfers", "good", body))
+	if res.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", res.Code, res.Body.String())
+	}
+	transfer := store.transfer
+	if transfer.UserID != "user-1" || transfer.FromLocation.String != "Coinbase" || transfer.ToLocation != "Ledger" || transfer.Quantity != 0.5 || transfer.FeeQuantity != 0.001 || len(transfer.Legs) != 0 {
+		t.Fatalf("unexpected transfer: %+v", transfer)
+	}
+
+	var got transferResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.ID != 31 || got.FromLocation == nil || *got.FromLocation != "Coinbase" || got.Legs == nil {
+		t.Fatalf("unexpected response: %+v", got)
+	}
+}
+
+func TestAPICreateTransferSpecificLots(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{transferLegs: []db.TransferLeg{
+		{SourceLotID: 3, DestinationLotID: 9, Quantity: 0.2, ReceivedQuantity: 0.2, SourceQuantity: 0.8, UnitCost: 100},
+		{SourceLotID: 4, DestinationLotID: 4, Quantity: 1, ReceivedQuantity: 1, UnitCost: 120},
+	}}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	body := []byte(`{"asset_id":1,"to_location":"Ledger","lots":[{"lot_id":3,"quantity":0.2},{"lot_id":4}]}`)
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/transfers", "good", body))
+	if res.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", res.Code, res.Body.String())
+	}
+	legs := store.transfer.Legs
+	if len(legs) != 2 || legs[0].SourceLotID != 3 || legs[0].Quantity != 0.2 || legs[1].SourceLotID != 4 || legs[1].Quantity != 0 {
+		t.Fatalf("unexpected legs: %+v", legs)
+	}
+	if store.transfer.FromLocation.Valid {
+		t.Fatalf("expected no from_location, got %+v", store.transfer.FromLocation)
+	}
+	want := []string{db.WebhookEventLotUpdated, db.WebhookEventLotCreated, db.WebhookEventLotUpdated}
+	if !slices.Equal(store.webhookEvents, want) {
+		t.Fatalf("expected webhook events %v, got %v", want, store.webhookEvents)
+	}
+}
+
+func TestAPICreateTransferErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		body string
+		err  error
+		code int
+	}{
+		{body: `{"asset_id":1,"quantity":1}`, code: http.StatusBadRequest},
+		{body: `{"asset_id":1,"to_location":"Ledger"}`, code: http.StatusBadRequest},
+		{body: `{"asset_id":1,"from_location":"Ledger","to_location":"Ledger","quantity":1}`, code: http.StatusBadRequest},
+		{body: `{"asset_id":1,"to_location":"Ledger","quantity":1,"fee_quantity":1}`, code: http.StatusBadRequest},
+		{body: `{"asset_id":1,"to_location":"Ledger","quantity":1,"lots":[{"lot_id":3}]}`, code: http.StatusBadRequest},
+		{body: `{"asset_id":1,"to_location":"Ledger","lots":[{"lot_id":3},{"lot_id":3}]}`, code: http.StatusBadRequest},
+		{body: `{"asset_id":1,"to_location":"Ledger","lots":[{"lot_id":3,"quantity":0}]}`, code: http.StatusBadRequest},
+		{body: `{"asset_id":1,"to_location":"Ledger","quantity":5}`, err: db.ErrInsufficientQuantity, code: http.StatusConflict},
+		{body: `{"asset_id":1,"to_location":"Ledger","lots":[{"lot_id":9}]}`, err: db.ErrLotNotFound, code: http.StatusBadRequest},
+		{body: `{"asset_id":1,"to_location":"Ledger","lots":[{"lot_id":9}]}`, err: db.ErrTransferSameLocation, code: http.StatusBadRequest},
+		{body: `{"asset_id":1,"to_location":"Ledger","lots":[{"lot_id":9}],"fee_quantity":2}`, err: db.ErrTransferFeeTooLarge, code: http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		store := &mockStore{transferErr: tc.err}
+		router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+		res := httptest.NewRecorder()
+
+		router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/transfers", "good", []byte(tc.body)))
+		if res.Code != tc.code {
+			t.Fatalf("expected %d for %s (%v), got %d", tc.code, tc.body, tc.err, res.Code)
+		}
+	}
+}
+
+func TestAPIListTransfers(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{transfers: []db.Transfer{{
+		ID:            31,
+		AssetID:       1,
+		ToLocation:    "Ledger",
+		Quantity:      0.5,
+		FeeQuantity:   0.001,
+		TransferredAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		Legs: []db.TransferLeg{
+			{SourceLotID: 3, DestinationLotID: 3, FromLocation: sql.NullString{String: "Coinbase", Valid: true}, Quantity: 0.3, ReceivedQuantity: 0.2994},
+			{SourceLotID: 4, DestinationLotID: 12, Quantity: 0.2, ReceivedQuantity: 0.1996},
+		},
+	}}}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodGet, "/api/v1/transfers", "good", nil))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.Code)
+	}
+	var got []transferResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got) != 1 || len(got[0].Legs) != 2 || got[0].Legs[0].FromLocation == nil || got[0].Legs[1].FromLocation != nil || got[0].Legs[1].DestinationLotID != 12 {
+		t.Fatalf("unexpected transfers: %+v", got)
+	}
+}
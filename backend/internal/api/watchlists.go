@@ -0,0 +1,281 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"asset-tracker/internal/db"
+)
+
+const maxWatchlistNameLength = 100
+
+type watchlistResponse struct {
+	ID        int64   `json:"id"`
+	Name      string  `json:"name"`
+	AssetIDs  []int64 `json:"asset_ids"`
+	CreatedAt string  `json:"created_at"`
+}
+
+type createWatchlistRequest struct {
+	Name     string  `json:"name"`
+	AssetIDs []int64 `json:"asset_ids"`
+}
+
+type createWatchlistResponse struct {
+	ID int64 `json:"id"`
+}
+
+type updateWatchlistRequest struct {
+	Name     *string `json:"name"`
+	AssetIDs []int64 `json:"asset_ids"`
+}
+
+func (s *Server) handleListWatchlists(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	watchlists, err := s.DB.ListWatchlistsByUser(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load watchlists")
+		return
+	}
+
+	response := make([]watchlistResponse, 0, len(watchlists))
+	for _, watchlist := range watchlists {
+		assetIDs := watchlist.AssetIDs
+		if assetIDs == nil {
+			assetIDs = []int64{}
+		}
+		response = append(response, watchlistResponse{
+			ID:        watchlist.ID,
+			Name:      watchlist.Name,
+			AssetIDs:  assetIDs,
+			CreatedAt: watchlist.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleCreateWatchlist(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	var req createWatchlistRequest
+	if err := decodeJSONBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	name, ok := normalizeWatchlistName(req.Name)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "name must be 1 to 100 characters")
+		return
+	}
+	assetIDs, message := normalizeWatchlistAssetIDs(req.AssetIDs)
+	if message != "" {
+		writeError(w, http.StatusBadRequest, message)
+		return
+	}
+
+	id, err := s.DB.InsertWatchlist(r.Context(), db.Watchlist{UserID: userID, Name: name, AssetIDs: assetIDs})
+	switch {
+	case errors.Is(err, db.ErrWatchlistNameTaken):
+		writeError(w, http.StatusConflict, "a watchlist with this name already exists")
+		return
+	case errors.Is(err, db.ErrAssetNotFound):
+		writeError(w, http.StatusBadRequest, "asset_ids must reference existing assets")
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "failed to create watchlist")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, createWatchlistResponse{ID: id})
+}
+
+func (s *Server) handleUpdateWatchlist(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	watchlistID, err := parseIDParam(r, "watchlistID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid watchlist id")
+		return
+	}
+
+	var req updateWatchlistRequest
+	if err := decodeJSONBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var patch db.WatchlistPatch
+	if req.Name != nil {
+		name, ok := normalizeWatchlistName(*req.Name)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "name must be 1 to 100 characters")
+			return
+		}
+		patch.Name = &name
+	}
+	if req.AssetIDs != nil {
+		assetIDs, message := normalizeWatchlistAssetIDs(req.AssetIDs)
+		if message != "" {
+			writeError(w, http.StatusBadRequest, message)
+			return
+		}
+		patch.AssetIDs = assetIDs
+	}
+
+	err = s.DB.UpdateWatchlistForUser(r.Context(), userID, watchlistID, patch)
+	switch {
+	case errors.Is(err, db.ErrWatchlistNotFound):
+		writeError(w, http.StatusNotFound, "watchlist not found")
+		return
+	case errors.Is(err, db.ErrWatchlistNameTaken):
+		writeError(w, http.StatusConflict, "a watchlist with this name already exists")
+		return
+	case errors.Is(err, db.ErrAssetNotFound):
+		writeError(w, http.StatusBadRequest, "asset_ids must reference existing assets")
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "failed to update watchlist")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleDeleteWatchlist(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	watchlistID, err := parseIDParam(r, "watchlistID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid watchlist id")
+		return
+	}
+
+	deleted, err := s.DB.DeleteWatchlistForUser(r.Context(), userID, watchlistID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete watchlist")
+		return
+	}
+	if !deleted {
+		writeError(w, http.StatusNotFound, "watchlist not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleAddWatchlistAsset(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	watchlistID, err := parseIDParam(r, "watchlistID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid watchlist id")
+		return
+	}
+	assetID, err := parseIDParam(r, "assetID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid asset id")
+		return
+	}
+
+	err = s.DB.AddWatchlistAsset(r.Context(), userID, watchlistID, assetID)
+	switch {
+	case errors.Is(err, db.ErrWatchlistNotFound):
+		writeError(w, http.StatusNotFound, "watchlist not found")
+		return
+	case errors.Is(err, db.ErrAssetNotFound):
+		writeError(w, http.StatusNotFound, "asset not found")
+		return
+	case errors.Is(err, db.ErrWatchlistFull):
+		writeError(w, http.StatusConflict, fmt.Sprintf("a watchlist can hold at most %d assets", db.MaxWatchlistAssets))
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "failed to update watchlist")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleRemoveWatchlistAsset(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	watchlistID, err := parseIDParam(r, "watchlistID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid watchlist id")
+		return
+	}
+	assetID, err := parseIDParam(r, "assetID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid asset id")
+		return
+	}
+
+	removed, err := s.DB.RemoveWatchlistAsset(r.Context(), userID, watchlistID, assetID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update watchlist")
+		return
+	}
+	if !removed {
+		writeError(w, http.StatusNotFound, "asset is not on this watchlist")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func normalizeWatchlistName(value string) (string, bool) {
+	name := strings.TrimSpace(value)
+	length := utf8.RuneCountInString(name)
+	return name, length > 0 && length <= maxWatchlistNameLength
+}
+
+// normalizeWatchlistAssetIDs drops duplicates and keeps the caller's order.
+func normalizeWatchlistAssetIDs(values []int64) ([]int64, string) {
+	seen := make(map[int64]bool, len(values))
+	assetIDs := make([]int64, 0, len(values))
+	for _, id := range values {
+		if id <= 0 {
+			return nil, "asset_ids must be greater than 0"
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		assetIDs = append(assetIDs, id)
+	}
+	if len(assetIDs) > db.MaxWatchlistAssets {
+		return nil, fmt.Sprintf("a watchlist can hold at most %d assets", db.MaxWatchlistAssets)
+	}
+	return assetIDs, ""
+}
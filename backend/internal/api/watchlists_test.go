@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"asset-tracker/internal/auth"
+	"asset-tracker/internal/db"
+)
+
+func TestAPIListWatchlists(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{watchlists: []db.Watchlist{
+		{ID: 1, Name: "Tech", AssetIDs: []int64{3, 4}, CreatedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, Name: "Empty"},
+	}}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodGet, "/api/v1/watchlists", "good", nil))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.Code)
+	}
+	var got []watchlistResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got) != 2 || len(got[0].AssetIDs) != 2 || got[1].AssetIDs == nil {
+		t.Fatalf("unexpected watchlists: %+v", got)
+	}
+}
+
+func TestAPICreateWatchlist(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	body := []byte(`{"name":"  Tech  ","asset_ids":[3,4,3]}`)
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/watchlists", "good", body))
+	if res.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", res.Code, res.Body.String())
+	}
+	inserted := store.insertedWatchlists[0]
+	if inserted.UserID != "user-1" || inserted.Name != "Tech" || len(inserted.AssetIDs) != 2 {
+		t.Fatalf("unexpected inserted watchlist: %+v", inserted)
+	}
+}
+
+func TestAPICreateWatchlistErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		body string
+		err  error
+		want int
+	}{
+		{body: `{"name":" "}`, want: http.StatusBadRequest},
+		{body: `{"name":"Tech","asset_ids":[0]}`, want: http.StatusBadRequest},
+		{body: `{"name":"Tech"}`, err: db.ErrWatchlistNameTaken, want: http.StatusConflict},
+		{body: `{"name":"Tech","asset_ids":[99]}`, err: db.ErrAssetNotFound, want: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		store := &mockStore{watchlistErr: tc.err}
+		router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+		res := httptest.NewRecorder()
+
+		router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/watchlists", "good", []byte(tc.body)))
+		if res.Code != tc.want {
+			t.Fatalf("%s: expected %d, got %d", tc.body, tc.want, res.Code)
+		}
+	}
+}
+
+func TestAPIUpdateWatchlistReplacesAssets(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{watchlistFound: true}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodPatch, "/api/v1/watchlists/1", "good", []byte(`{"asset_ids":[]}`)))
+	if res.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", res.Code)
+	}
+	if store.watchlistPatch.Name != nil || store.watchlistPatch.AssetIDs == nil || len(store.watchlistPatch.AssetIDs) != 0 {
+		t.Fatalf("expected an empty asset replacement, got %+v", store.watchlistPatch)
+	}
+}
+
+func TestAPIUpdateWatchlistNotFound(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodPatch, "/api/v1/watchlists/1", "good", []byte(`{"name":"Other"}`)))
+	if res.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", res.Code)
+	}
+}
+
+func TestAPIAddWatchlistAsset(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{watchlistFound: true}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodPut, "/api/v1/watchlists/1/assets/7", "good", nil))
+	if res.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", res.Code)
+	}
+	if store.watchlistAddedAsset != 7 {
+		t.Fatalf("expected asset 7 to be added, got %d", store.watchlistAddedAsset)
+	}
+
+	store = &mockStore{watchlistFound: true, watchlistErr: db.ErrWatchlistFull}
+	router = newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res = httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodPut, "/api/v1/watchlists/1/assets/7", "good", nil))
+	if res.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for a full watchlist, got %d", res.Code)
+	}
+}
+
+func TestAPIRemoveWatchlistAssetNotFound(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodDelete, "/api/v1/watchlists/1/assets/7", "good", nil))
+	if res.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", res.Code)
+	}
+}
@@ -0,0 +1,385 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"asset-tracker/internal/db"
+	"asset-tracker/internal/webhooks"
+)
+
+var subscribableWebhookEvents = map[string]bool{
+	db.WebhookEventLotCreated:     true,
+	db.WebhookEventLotUpdated:     true,
+	db.WebhookEventLotDeleted:     true,
+	db.WebhookEventLotRestored:    true,
+	db.WebhookEventAlertTriggered: true,
+	db.WebhookEventDailySummary:   true,
+}
+
+type webhookResponse struct {
+	ID        int64    `json:"id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	Enabled   bool     `json:"enabled"`
+	CreatedAt string   `json:"created_at"`
+}
+
+type createWebhookRequest struct {
+	URL     string   `json:"url"`
+	Events  []string `json:"events"`
+	Enabled *bool    `json:"enabled"`
+}
+
+type createWebhookResponse struct {
+	ID     int64  `json:"id"`
+	Secret string `json:"secret"`
+}
+
+type updateWebhookRequest struct {
+	URL     *string  `json:"url"`
+	Events  []string `json:"events"`
+	Enabled *bool    `json:"enabled"`
+}
+
+type testWebhookResponse struct {
+	Queued bool `json:"queued"`
+}
+
+type webhookDeliveryResponse struct {
+	ID             int64           `json:"id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *string         `json:"next_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code"`
+	LastError      *string         `json:"last_error"`
+	CreatedAt      string          `json:"created_at"`
+	DeliveredAt    *string         `json:"delivered_at"`
+}
+
+type lotWebhookPayload struct {
+	LotID       int64    `json:"lot_id"`
+	AssetID     int64    `json:"asset_id,omitempty"`
+	Quantity    *float64 `json:"quantity,omitempty"`
+	UnitCost    *float64 `json:"unit_cost,omitempty"`
+	PurchasedAt string   `json:"purchased_at,omitempty"`
+}
+
+type testWebhookPayload struct {
+	WebhookID int64  `json:"webhook_id"`
+	Message   string `json:"message"`
+}
+
+func (s *Server) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	webhooks, err := s.DB.ListWebhooksByUser(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load webhooks")
+		return
+	}
+
+	response := make([]webhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		response = append(response, webhookResponse{
+			ID:        webhook.ID,
+			URL:       webhook.URL,
+			Events:    webhook.Events,
+			Enabled:   webhook.Enabled,
+			CreatedAt: webhook.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	var req createWebhookRequest
+	if err := decodeJSONBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	webhookURL, ok := normalizeWebhookURL(req.URL)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "url must be an absolute http or https URL")
+		return
+	}
+	events, ok := normalizeWebhookEvents(req.Events)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "events must list one or more of lot.created, lot.updated, lot.deleted, lot.restored, alert.triggered, portfolio.daily_summary")
+		return
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create webhook")
+		return
+	}
+
+	webhook := db.Webhook{
+		UserID:  userID,
+		URL:     webhookURL,
+		Secret:  secret,
+		Events:  events,
+		Enabled: true,
+	}
+	if req.Enabled != nil {
+		webhook.Enabled = *req.Enabled
+	}
+
+	id, err := s.DB.InsertWebhook(r.Context(), webhook)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create webhook")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, createWebhookResponse{ID: id, Secret: secret})
+}
+
+func (s *Server) handleUpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	webhookID, err := parseIDParam(r, "webhookID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	var req updateWebhookRequest
+	if err := decodeJSONBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var patch db.WebhookPatch
+	if req.URL != nil {
+		webhookURL, ok := normalizeWebhookURL(*req.URL)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "url must be an absolute http or https URL")
+			return
+		}
+		patch.URL = &webhookURL
+	}
+	if req.Events != nil {
+		events, ok := normalizeWebhookEvents(req.Events)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "events must list one or more of lot.created, lot.updated, lot.deleted, lot.restored, alert.triggered, portfolio.daily_summary")
+			return
+		}
+		patch.Events = events
+	}
+	patch.Enabled = req.Enabled
+
+	updated, err := s.DB.UpdateWebhookForUser(r.Context(), userID, webhookID, patch)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update webhook")
+		return
+	}
+	if !updated {
+		writeError(w, http.StatusNotFound, "webhook not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	webhookID, err := parseIDParam(r, "webhookID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	deleted, err := s.DB.DeleteWebhookForUser(r.Context(), userID, webhookID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete webhook")
+		return
+	}
+	if !deleted {
+		writeError(w, http.StatusNotFound, "webhook not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleTestWebhook queues a webhook.test delivery for one webhook, whether or
+// not it is enabled. The worker sends it on its next dispatch cycle.
+func (s *Server) handleTestWebhook(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	webhookID, err := parseIDParam(r, "webhookID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	queued, err := s.DB.EnqueueWebhookDeliveries(r.Context(), []int64{webhookID}, userID, db.WebhookEventTest, testWebhookPayload{
+		WebhookID: webhookID,
+		Message:   "This is a test event from asset-tracker.",
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to queue test event")
+		return
+	}
+	if queued == 0 {
+		writeError(w, http.StatusNotFound, "webhook not found")
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, testWebhookResponse{Queued: true})
+}
+
+func (s *Server) handleListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user context")
+		return
+	}
+
+	webhookID, err := parseIDParam(r, "webhookID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	limit := 50
+	if rawLimit := strings.TrimSpace(r.URL.Query().Get("limit")); rawLimit != "" {
+		parsedLimit, err := strconv.Atoi(rawLimit)
+		if err != nil || parsedLimit <= 0 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		if parsedLimit > 200 {
+			parsedLimit = 200
+		}
+		limit = parsedLimit
+	}
+
+	deliveries, err := s.DB.ListWebhookDeliveriesForUser(r.Context(), userID, webhookID, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load webhook deliveries")
+		return
+	}
+
+	response := make([]webhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		item := webhookDeliveryResponse{
+			ID:        delivery.ID,
+			EventType: delivery.EventType,
+			Payload:   json.RawMessage(delivery.Payload),
+			Status:    delivery.Status,
+			Attempts:  delivery.Attempts,
+			CreatedAt: delivery.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		if delivery.Status == db.WebhookDeliveryPending {
+			nextAttemptAt := delivery.NextAttemptAt.UTC().Format(time.RFC3339)
+			item.NextAttemptAt = &nextAttemptAt
+		}
+		if delivery.LastStatusCode.Valid {
+			code := int(delivery.LastStatusCode.Int32)
+			item.LastStatusCode = &code
+		}
+		if delivery.LastError.Valid {
+			lastError := delivery.LastError.String
+			item.LastError = &lastError
+		}
+		if delivery.DeliveredAt.Valid {
+			deliveredAt := delivery.DeliveredAt.Time.UTC().Format(time.RFC3339)
+			item.DeliveredAt = &deliveredAt
+		}
+		response = append(response, item)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// publishWebhookEvent queues an event for the user's webhooks. Failures are
+// logged rather than returned so a webhook outage never fails the request.
+func (s *Server) publishWebhookEvent(ctx context.Context, userID string, eventType string, payload any) {
+	if err := s.DB.EnqueueWebhookEvent(ctx, userID, eventType, payload); err != nil {
+		slog.Warn("failed to queue webhook event", "event_type", eventType, "user_id", userID, "error", err)
+	}
+}
+
+// normalizeWebhookURL rejects hosts that are plainly internal. Hostnames are
+// checked again by the dispatcher once resolved, at every delivery.
+func normalizeWebhookURL(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Host == "" {
+		return "", false
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", false
+	}
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return "", false
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !webhooks.IsPublicAddr(addr) {
+		return "", false
+	}
+	return value, true
+}
+
+func normalizeWebhookEvents(values []string) ([]string, bool) {
+	seen := make(map[string]bool, len(values))
+	events := make([]string, 0, len(values))
+	for _, value := range values {
+		event := strings.ToLower(strings.TrimSpace(value))
+		if !subscribableWebhookEvents[event] {
+			return nil, false
+		}
+		if seen[event] {
+			continue
+		}
+		seen[event] = true
+		events = append(events, event)
+	}
+	return events, len(events) > 0
+}
+
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"asset-tracker/internal/auth"
+	"asset-tracker/internal/db"
+)
+
+func TestAPICreateWebhookReturnsSecret(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	body := []byte(`{"url":"https://example.com/hooks","events":["lot.created","Alert.Triggered","lot.created"]}`)
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/webhooks", "good", body))
+
+	if res.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", res.Code)
+	}
+	var got createWebhookResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !strings.HasPrefix(got.Secret, "whsec_") {
+		t.Fatalf("expected generated secret, got %q", got.Secret)
+	}
+	inserted := store.insertedWebhooks[0]
+	if inserted.UserID != "user-1" || inserted.Secret != got.Secret || !inserted.Enabled {
+		t.Fatalf("unexpected inserted webhook: %+v", inserted)
+	}
+	if len(inserted.Events) != 2 || inserted.Events[1] != db.WebhookEventAlertTriggered {
+		t.Fatalf("expected normalized events, got %v", inserted.Events)
+	}
+}
+
+func TestAPICreateWebhookValidation(t *testing.T) {
+	t.Parallel()
+
+	cases := []string{
+		`{"url":"ftp://example.com","events":["lot.created"]}`,
+		`{"url":"/relative","events":["lot.created"]}`,
+		`{"url":"https://example.com","events":[]}`,
+		`{"url":"https://example.com","events":["webhook.test"]}`,
+		`{"url":"http://127.0.0.1:8080/hook","events":["lot.created"]}`,
+		`{"url":"http://localhost/hook","events":["lot.created"]}`,
+		`{"url":"http://[::1]/hook","events":["lot.created"]}`,
+		`{"url":"http://10.0.0.5/hook","events":["lot.created"]}`,
+		`{"url":"http://169.254.169.254/latest/meta-data","events":["lot.created"]}`,
+	}
+	for _, body := range cases {
+		store := &mockStore{}
+		router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+		res := httptest.NewRecorder()
+
+		router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/webhooks", "good", []byte(body)))
+		if res.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", body, res.Code)
+		}
+		if len(store.insertedWebhooks) != 0 {
+			t.Fatalf("expected no inserts for %s", body)
+		}
+	}
+}
+
+func TestAPITestWebhookQueuesDelivery(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{webhookFound: true}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/webhooks/4/test", "good", nil))
+	if res.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", res.Code)
+	}
+	if len(store.queuedWebhookIDs) != 1 || store.queuedWebhookIDs[0] != 4 || store.queuedEventType != db.WebhookEventTest {
+		t.Fatalf("unexpected queued delivery: %v %q", store.queuedWebhookIDs, store.queuedEventType)
+	}
+}
+
+func TestAPITestWebhookNotFound(t *testing.T) {
+	t.Parallel()
+
+	router := newAPIRouter(&mockStore{}, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, newRequest(t, http.MethodPost, "/api/v1/webhooks/4/test", "good", nil))
+	if res.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", res.Code)
+	}
+}
+
+func TestAPILotMutationsQueueWebhookEvents(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{updatedFound: true, deletedFound: true, webhookEventErr: errors.New("queue down")}
+	router := newAPIRouter(store, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+
+	requests := []*http.Request{
+		newRequest(t, http.MethodPost, "/api/v1/lots", "good", []byte(`{"asset_id":1,"quantity":1,"unit_cost":10,"purchased_at":"2026-02-16"}`)),
+		newRequest(t, http.MethodPatch, "/api/v1/lots/1", "good", []byte(`{"quantity":2,"unit_cost":10,"purchased_at":"2026-02-16"}`)),
+		newRequest(t, http.MethodDelete, "/api/v1/lots/1", "good", nil),
+	}
+	for _, req := range requests {
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		if res.Code >= 300 {
+			t.Fatalf("expected success for %s %s despite queue error, got %d", req.Method, req.URL.Path, res.Code)
+		}
+	}
+
+	want := []string{db.WebhookEventLotCreated, db.WebhookEventLotUpdated, db.WebhookEventLotDeleted}
+	if strings.Join(store.webhookEvents, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected events %v, got %v", want, store.webhookEvents)
+	}
+}
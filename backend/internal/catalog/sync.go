@@ -0,0 +1,333 @@
+package catalog
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"asset-tracker/internal/db"
+	"asset-tracker/internal/providers"
+)
+
+type ChangeKind string
+
+const (
+	ChangeInsert       ChangeKind = "insert"
+	ChangeRename       ChangeKind = "rename"
+	ChangeSymbolChange ChangeKind = "symbol_change"
+	// ChangeLink sets the market_data_id of a hand-seeded asset with the
+	// listing's symbol and name.
+	ChangeLink ChangeKind = "link"
+)
+
+type CollisionReason string
+
+const (
+	CollisionSymbolTaken     CollisionReason = "symbol_taken"
+	CollisionSymbolAmbiguous CollisionReason = "symbol_ambiguous"
+	// CollisionInsertConflict is an insert the database skipped on a unique
+	// conflict, such as a row seeded while the sync ran.
+	CollisionInsertConflict CollisionReason = "insert_conflict"
+)
+
+type Change struct {
+	Kind         ChangeKind
+	AssetID      int64
+	MarketDataID string
+	OldSymbol    string
+	NewSymbol    string
+	OldName      string
+	NewName      string
+}
+
+type Collision struct {
+	Reason         CollisionReason
+	MarketDataID   string
+	Symbol         string
+	Name           string
+	ExistingID     int64
+	CompetingCount int
+}
+
+type Plan struct {
+	Changes    []Change
+	Collisions []Collision
+	Unchanged  int
+	Skipped    int
+}
+
+type Store interface {
+	ListAssetsByType(ctx context.Context, assetType db.AssetType) ([]db.Asset, error)
+	ApplyCatalogChanges(ctx context.Context, inserts []db.Asset, updates []db.Asset) ([]db.Asset, error)
+}
+
+type Syncer struct {
+	store    Store
+	provider providers.CatalogProvider
+}
+
+func NewSyncer(store Store, provider providers.CatalogProvider) *Syncer {
+	return &Syncer{store: store, provider: provider}
+}
+
+// Run fetches the provider listing, diffs it against public.assets and, unless
+// dryRun is set, writes inserts, links and renames. Collisions are never
+// written; inserts the database skipped are added to them.
+func (s *Syncer) Run(ctx context.Context, dryRun bool) (Plan, error) {
+	listings, err := s.provider.ListCatalog(ctx)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	existing, err := s.store.ListAssetsByType(ctx, db.AssetTypeCrypto)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	plan := BuildPlan(existing, listings)
+	if dryRun {
+		return plan, nil
+	}
+
+	inserts, updates := plan.writes()
+	skipped, err := s.store.ApplyCatalogChanges(ctx, inserts, updates)
+	if err != nil {
+		return plan, err
+	}
+	for _, asset := range skipped {
+		slog.Warn("catalog insert skipped on conflict", "market_data_id", asset.MarketDataID, "symbol", asset.Symbol, "name", asset.Name)
+		plan.Collisions = append(plan.Collisions, Collision{
+			Reason:       CollisionInsertConflict,
+			MarketDataID: asset.MarketDataID,
+			Symbol:       asset.Symbol,
+			Name:         asset.Name,
+		})
+	}
+	return plan, nil
+}
+
+// BuildPlan matches listings to existing crypto assets by market_data_id.
+// Symbols are compared case-insensitively and stored upper-cased, matching the
+// hand-seeded rows. A hand-seeded asset without a market_data_id is linked to
+// the one listing with its symbol and name. Any other listing whose symbol is
+// already owned by a different asset, or shared by several new listings, is
+// reported as a collision because public.assets is unique on (symbol, type).
+func BuildPlan(existing []db.Asset, listings []providers.CatalogEntry) Plan {
+	var plan Plan
+
+	byMarketID := make(map[string]db.Asset, len(existing))
+	bySymbol := make(map[string]db.Asset, len(existing))
+	for _, asset := range existing {
+		if key := normalizeMarketDataID(asset.MarketDataID); key != "" {
+			byMarketID[key] = asset
+		}
+		bySymbol[normalizeSymbol(asset.Symbol)] = asset
+	}
+
+	entries := normalizeEntries(listings, &plan)
+
+	linkCounts := make(map[string]int)
+	for _, entry := range entries {
+		if _, ok := byMarketID[entry.MarketDataID]; ok {
+			continue
+		}
+		if owner, taken := bySymbol[entry.Symbol]; taken && canLink(owner, entry) {
+			linkCounts[entry.Symbol]++
+		}
+	}
+
+	symbolCounts := make(map[string]int, len(entries))
+	for _, entry := range entries {
+		if _, ok := byMarketID[entry.MarketDataID]; ok {
+			continue
+		}
+		symbolCounts[entry.Symbol]++
+	}
+	for _, entry := range entries {
+		asset, ok := byMarketID[entry.MarketDataID]
+		if !ok || normalizeSymbol(asset.Symbol) == entry.Symbol {
+			continue
+		}
+		symbolCounts[entry.Symbol]++
+	}
+
+	for _, entry := range entries {
+		asset, ok := byMarketID[entry.MarketDataID]
+		if !ok {
+			if owner, taken := bySymbol[entry.Symbol]; taken && canLink(owner, entry) && linkCounts[entry.Symbol] == 1 {
+				plan.Changes = append(plan.Changes, Change{
+					Kind:         ChangeLink,
+					AssetID:      owner.ID,
+					MarketDataID: entry.MarketDataID,
+					OldSymbol:    owner.Symbol,
+					NewSymbol:    entry.Symbol,
+					OldName:      owner.Name,
+					NewName:      entry.Name,
+				})
+				continue
+			}
+			if owner, taken := bySymbol[entry.Symbol]; taken {
+				plan.Collisions = append(plan.Collisions, Collision{
+					Reason:       CollisionSymbolTaken,
+					MarketDataID: entry.MarketDataID,
+					Symbol:       entry.Symbol,
+					Name:         entry.Name,
+					ExistingID:   owner.ID,
+				})
+				continue
+			}
+			if count := symbolCounts[entry.Symbol]; count > 1 {
+				plan.Collisions = append(plan.Collisions, Collision{
+					Reason:         CollisionSymbolAmbiguous,
+					MarketDataID:   entry.MarketDataID,
+					Symbol:         entry.Symbol,
+					Name:           entry.Name,
+					CompetingCount: count,
+				})
+				continue
+			}
+			plan.Changes = append(plan.Changes, Change{
+				Kind:         ChangeInsert,
+				MarketDataID: entry.MarketDataID,
+				NewSymbol:    entry.Symbol,
+				NewName:      entry.Name,
+			})
+			continue
+		}
+
+		changed := false
+		if normalizeSymbol(asset.Symbol) != entry.Symbol {
+			owner, taken := bySymbol[entry.Symbol]
+			switch {
+			case taken && owner.ID != asset.ID:
+				plan.Collisions = append(plan.Collisions, Collision{
+					Reason:       CollisionSymbolTaken,
+					MarketDataID: entry.MarketDataID,
+					Symbol:       entry.Symbol,
+					Name:         entry.Name,
+					ExistingID:   owner.ID,
+				})
+			case symbolCounts[entry.Symbol] > 1:
+				plan.Collisions = append(plan.Collisions, Collision{
+					Reason:         CollisionSymbolAmbiguous,
+					MarketDataID:   entry.MarketDataID,
+					Symbol:         entry.Symbol,
+					Name:           entry.Name,
+					CompetingCount: symbolCounts[entry.Symbol],
+				})
+			default:
+				plan.Changes = append(plan.Changes, Change{
+					Kind:         ChangeSymbolChange,
+					AssetID:      asset.ID,
+					MarketDataID: entry.MarketDataID,
+					OldSymbol:    asset.Symbol,
+					NewSymbol:    entry.Symbol,
+					OldName:      asset.Name,
+					NewName:      entry.Name,
+				})
+				changed = true
+			}
+		}
+		if !changed && asset.Name != entry.Name {
+			plan.Changes = append(plan.Changes, Change{
+				Kind:         ChangeRename,
+				AssetID:      asset.ID,
+				MarketDataID: entry.MarketDataID,
+				OldSymbol:    asset.Symbol,
+				NewSymbol:    asset.Symbol,
+				OldName:      asset.Name,
+				NewName:      entry.Name,
+			})
+			changed = true
+		}
+		if !changed {
+			plan.Unchanged++
+		}
+	}
+
+	return plan
+}
+
+func (p Plan) Count(kind ChangeKind) int {
+	count := 0
+	for _, change := range p.Changes {
+		if change.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+func (p Plan) writes() ([]db.Asset, []db.Asset) {
+	var inserts []db.Asset
+	var updates []db.Asset
+	for _, change := range p.Changes {
+		switch change.Kind {
+		case ChangeInsert:
+			inserts = append(inserts, db.Asset{
+				Symbol:       change.NewSymbol,
+				MarketDataID: change.MarketDataID,
+				Type:         db.AssetTypeCrypto,
+				Name:         change.NewName,
+			})
+		case ChangeRename, ChangeSymbolChange:
+			updates = append(updates, db.Asset{
+				ID:     change.AssetID,
+				Symbol: change.NewSymbol,
+				Name:   change.NewName,
+			})
+		case ChangeLink:
+			updates = append(updates, db.Asset{
+				ID:           change.AssetID,
+				Symbol:       change.NewSymbol,
+				MarketDataID: change.MarketDataID,
+				Name:         change.NewName,
+			})
+		}
+	}
+	return inserts, updates
+}
+
+// canLink reports whether an unlinked asset is the listing: same symbol and,
+// ignoring case, the same name.
+func canLink(asset db.Asset, entry providers.CatalogEntry) bool {
+	return normalizeMarketDataID(asset.MarketDataID) == "" &&
+		normalizeSymbol(asset.Symbol) == entry.Symbol &&
+		strings.EqualFold(strings.TrimSpace(asset.Name), entry.Name)
+}
+
+func normalizeEntries(listings []providers.CatalogEntry, plan *Plan) []providers.CatalogEntry {
+	seen := make(map[string]struct{}, len(listings))
+	out := make([]providers.CatalogEntry, 0, len(listings))
+	for _, listing := range listings {
+		entry := providers.CatalogEntry{
+			MarketDataID: normalizeMarketDataID(listing.MarketDataID),
+			Symbol:       normalizeSymbol(listing.Symbol),
+			Name:         strings.TrimSpace(listing.Name),
+		}
+		if entry.MarketDataID == "" || entry.Symbol == "" {
+			plan.Skipped++
+			continue
+		}
+		if _, ok := seen[entry.MarketDataID]; ok {
+			plan.Skipped++
+			continue
+		}
+		seen[entry.MarketDataID] = struct{}{}
+		if entry.Name == "" {
+			entry.Name = entry.Symbol
+		}
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].MarketDataID < out[j].MarketDataID })
+	return out
+}
+
+func normalizeMarketDataID(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+func normalizeSymbol(value string) string {
+	return strings.ToUpper(strings.TrimSpace(value))
+}
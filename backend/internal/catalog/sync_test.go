@@ -0,0 +1,213 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"asset-tracker/internal/db"
+	"asset-tracker/internal/providers"
+)
+
+type mockStore struct {
+	assets  []db.Asset
+	listErr error
+
+	applyCalls int
+	inserts    []db.Asset
+	updates    []db.Asset
+	conflicts  map[string]bool
+}
+
+func (m *mockStore) ListAssetsByType(ctx context.Context, assetType db.AssetType) ([]db.Asset, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	return m.assets, nil
+}
+
+func (m *mockStore) ApplyCatalogChanges(ctx context.Context, inserts []db.Asset, updates []db.Asset) ([]db.Asset, error) {
+	m.applyCalls++
+	m.inserts = append([]db.Asset(nil), inserts...)
+	m.updates = append([]db.Asset(nil), updates...)
+	var skipped []db.Asset
+	for _, asset := range inserts {
+		if m.conflicts[asset.MarketDataID] {
+			skipped = append(skipped, asset)
+		}
+	}
+	return skipped, nil
+}
+
+type mockCatalogProvider struct {
+	entries []providers.CatalogEntry
+	err     error
+}
+
+func (m mockCatalogProvider) ListCatalog(ctx context.Context) ([]providers.CatalogEntry, error) {
+	return m.entries, m.err
+}
+
+func TestBuildPlanDetectsInsertsRenamesAndCollisions(t *testing.T) {
+	t.Parallel()
+
+	existing := []db.Asset{
+		{ID: 1, Symbol: "BTC", MarketDataID: "bitcoin", Type: db.AssetTypeCrypto, Name: "Bitcoin"},
+		{ID: 2, Symbol: "MATIC", MarketDataID: "polygon", Type: db.AssetTypeCrypto, Name: "Polygon"},
+		{ID: 3, Symbol: "ETH", MarketDataID: "ethereum", Type: db.AssetTypeCrypto, Name: "Ethereum"},
+	}
+	listings := []providers.CatalogEntry{
+		{MarketDataID: "bitcoin", Symbol: "btc", Name: "Bitcoin"},
+		{MarketDataID: "polygon", Symbol: "pol", Name: "POL (ex-MATIC)"},
+		{MarketDataID: "ethereum", Symbol: "eth", Name: "Ethereum Classic?"},
+		{MarketDataID: "solana", Symbol: "sol", Name: "Solana"},
+		{MarketDataID: "fake-eth", Symbol: "eth", Name: "Fake Ether"},
+		{MarketDataID: "usd-coin", Symbol: "usdc", Name: "USDC"},
+		{MarketDataID: "bridged-usdc", Symbol: "USDC", Name: "Bridged USDC"},
+		{MarketDataID: "", Symbol: "X", Name: "No id"},
+	}
+
+	plan := BuildPlan(existing, listings)
+
+	if plan.Unchanged != 1 {
+		t.Fatalf("expected 1 unchanged asset, got %d", plan.Unchanged)
+	}
+	if plan.Skipped != 1 {
+		t.Fatalf("expected 1 skipped listing, got %d", plan.Skipped)
+	}
+	if got := plan.Count(ChangeInsert); got != 1 {
+		t.Fatalf("expected 1 insert, got %d: %+v", got, plan.Changes)
+	}
+	if got := plan.Count(ChangeSymbolChange); got != 1 {
+		t.Fatalf("expected 1 symbol change, got %d", got)
+	}
+	if got := plan.Count(ChangeRename); got != 1 {
+		t.Fatalf("expected 1 rename, got %d", got)
+	}
+
+	byMarketID := map[string]Change{}
+	for _, change := range plan.Changes {
+		byMarketID[change.MarketDataID] = change
+	}
+	if change := byMarketID["polygon"]; change.Kind != ChangeSymbolChange || change.NewSymbol != "POL" || change.NewName != "POL (ex-MATIC)" {
+		t.Fatalf("unexpected polygon change: %+v", change)
+	}
+	if change := byMarketID["solana"]; change.Kind != ChangeInsert || change.NewSymbol != "SOL" {
+		t.Fatalf("unexpected solana change: %+v", change)
+	}
+
+	collisions := map[string]Collision{}
+	for _, collision := range plan.Collisions {
+		collisions[collision.MarketDataID] = collision
+	}
+	if len(collisions) != 3 {
+		t.Fatalf("expected 3 collisions, got %+v", plan.Collisions)
+	}
+	if c := collisions["fake-eth"]; c.Reason != CollisionSymbolTaken || c.ExistingID != 3 {
+		t.Fatalf("unexpected fake-eth collision: %+v", c)
+	}
+	if c := collisions["usd-coin"]; c.Reason != CollisionSymbolAmbiguous || c.CompetingCount != 2 {
+		t.Fatalf("unexpected usd-coin collision: %+v", c)
+	}
+}
+
+func TestBuildPlanLinksUnlinkedAssets(t *testing.T) {
+	t.Parallel()
+
+	existing := []db.Asset{
+		{ID: 1, Symbol: "PEPE", LookupBlockchain: "ethereum", LookupAddress: "0x6982", Type: db.AssetTypeCrypto, Name: "Pepe"},
+		{ID: 2, Symbol: "USDT", LookupBlockchain: "ethereum", LookupAddress: "0xdac1", Type: db.AssetTypeCrypto, Name: "Tether"},
+		{ID: 3, Symbol: "WIF", LookupBlockchain: "solana", LookupAddress: "EKpQ", Type: db.AssetTypeCrypto, Name: "dogwifhat"},
+	}
+	listings := []providers.CatalogEntry{
+		{MarketDataID: "pepe", Symbol: "pepe", Name: "PEPE"},
+		{MarketDataID: "tether", Symbol: "usdt", Name: "Tether"},
+		{MarketDataID: "bridged-tether", Symbol: "usdt", Name: "Tether"},
+		{MarketDataID: "wif-copy", Symbol: "wif", Name: "WIF Copy"},
+	}
+
+	plan := BuildPlan(existing, listings)
+
+	if len(plan.Changes) != 1 || plan.Changes[0].Kind != ChangeLink || plan.Changes[0].AssetID != 1 || plan.Changes[0].MarketDataID != "pepe" {
+		t.Fatalf("expected pepe linked, got %+v", plan.Changes)
+	}
+	if len(plan.Collisions) != 3 {
+		t.Fatalf("expected ambiguous tether and mismatched wif as collisions, got %+v", plan.Collisions)
+	}
+	inserts, updates := plan.writes()
+	if len(inserts) != 0 || len(updates) != 1 || updates[0].ID != 1 || updates[0].MarketDataID != "pepe" || updates[0].Name != "PEPE" {
+		t.Fatalf("unexpected writes: inserts=%+v updates=%+v", inserts, updates)
+	}
+}
+
+func TestSyncerReportsSkippedInserts(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{conflicts: map[string]bool{"solana": true}}
+	provider := mockCatalogProvider{entries: []providers.CatalogEntry{
+		{MarketDataID: "solana", Symbol: "sol", Name: "Solana"},
+		{MarketDataID: "cardano", Symbol: "ada", Name: "Cardano"},
+	}}
+
+	plan, err := NewSyncer(store, provider).Run(context.Background(), false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(plan.Collisions) != 1 || plan.Collisions[0].Reason != CollisionInsertConflict || plan.Collisions[0].MarketDataID != "solana" {
+		t.Fatalf("expected skipped insert reported, got %+v", plan.Collisions)
+	}
+}
+
+func TestSyncerDryRunSkipsWrites(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	provider := mockCatalogProvider{entries: []providers.CatalogEntry{{MarketDataID: "bitcoin", Symbol: "btc", Name: "Bitcoin"}}}
+
+	plan, err := NewSyncer(store, provider).Run(context.Background(), true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if plan.Count(ChangeInsert) != 1 {
+		t.Fatalf("expected 1 planned insert, got %+v", plan.Changes)
+	}
+	if store.applyCalls != 0 {
+		t.Fatalf("expected no writes in dry-run, got %d", store.applyCalls)
+	}
+}
+
+func TestSyncerAppliesInsertsAndUpdates(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{assets: []db.Asset{{ID: 7, Symbol: "BTC", MarketDataID: "bitcoin", Type: db.AssetTypeCrypto, Name: "bitcoin"}}}
+	provider := mockCatalogProvider{entries: []providers.CatalogEntry{
+		{MarketDataID: "bitcoin", Symbol: "btc", Name: "Bitcoin"},
+		{MarketDataID: "solana", Symbol: "sol", Name: "Solana"},
+	}}
+
+	if _, err := NewSyncer(store, provider).Run(context.Background(), false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if store.applyCalls != 1 {
+		t.Fatalf("expected one apply call, got %d", store.applyCalls)
+	}
+	if len(store.inserts) != 1 || store.inserts[0].MarketDataID != "solana" || store.inserts[0].Type != db.AssetTypeCrypto {
+		t.Fatalf("unexpected inserts: %+v", store.inserts)
+	}
+	if len(store.updates) != 1 || store.updates[0].ID != 7 || store.updates[0].Name != "Bitcoin" {
+		t.Fatalf("unexpected updates: %+v", store.updates)
+	}
+}
+
+func TestSyncerProviderError(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{}
+	_, err := NewSyncer(store, mockCatalogProvider{err: errors.New("listing failed")}).Run(context.Background(), false)
+	if err == nil {
+		t.Fatal("expected provider error, got nil")
+	}
+	if store.applyCalls != 0 {
+		t.Fatalf("expected no writes on provider error, got %d", store.applyCalls)
+	}
+}
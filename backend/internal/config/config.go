@@ -3,14 +3,16 @@ package config
 import (
 	"errors"
 	"os"
+	"strconv"
 	"strings"
 )
 
 type Mode string
 
 const (
-	ModeWorker Mode = "worker"
-	ModeWS     Mode = "ws"
+	ModeWorker  Mode = "worker"
+	ModeWS      Mode = "ws"
+	ModeCatalog Mode = "catalog"
 )
 
 // Config holds service configuration shared by worker and WS server.
@@ -24,9 +26,56 @@ type Config struct {
 	CryptoProviderAPIKey  string
 	CryptoProviderName    string
 	CryptoProviderBaseURL string
+	StockProviderHTTP     HTTPProviderSettings
+	CryptoProviderHTTP    HTTPProviderSettings
+	StockProviders        []ProviderSettings
+	CryptoProviders       []ProviderSettings
+	StockProviderQuorum   QuorumSettings
+	CryptoProviderQuorum  QuorumSettings
 	Port                  string
+	DebugAddr             string
+	AdminUserIDs          []string
 }
 
+// ProviderSettings configures one provider of the comma-separated
+// STOCK_PROVIDER_NAME or CRYPTO_PROVIDER_NAME list, in order. BatchSize and
+// RequestsPerMinute override the provider's own limits when set. Seed is the
+// simulated provider's and Path the fixture provider's file.
+type ProviderSettings struct {
+	Name              string
+	APIKey            string
+	BaseURL           string
+	BatchSize         int
+	RequestsPerMinute int
+	Seed              int64
+	Path              string
+}
+
+// HTTPProviderSettings configures the generic "http" provider, whose base URL
+// is a URL template. Field paths are dot-separated.
+type HTTPProviderSettings struct {
+	AuthHeader  string
+	BatchSize   int
+	ResultsPath string
+	KeyField    string
+	PriceField  string
+}
+
+// QuorumSettings switches a provider list from fallback order to asking every
+// provider and taking the median of the quotes within Tolerance of it.
+type QuorumSettings struct {
+	Enabled   bool
+	Tolerance float64
+	MinQuotes int
+}
+
+const (
+	defaultQuorumTolerance = 0.05
+	// defaultQuorumMinQuotes makes quorum mode need two agreeing providers, so
+	// a quote only one provider returned is never accepted unchecked.
+	defaultQuorumMinQuotes = 2
+)
+
 func LoadForWorker() (Config, error) {
 	return load(ModeWorker)
 }
@@ -35,6 +84,10 @@ func LoadForWS() (Config, error) {
 	return load(ModeWS)
 }
 
+func LoadForCatalog() (Config, error) {
+	return load(ModeCatalog)
+}
+
 func load(mode Mode) (Config, error) {
 	cfg := Config{
 		DatabaseURL:           os.Getenv("DATABASE_URL"),
@@ -47,15 +100,26 @@ func load(mode Mode) (Config, error) {
 		CryptoProviderName:    os.Getenv("CRYPTO_PROVIDER_NAME"),
 		CryptoProviderBaseURL: os.Getenv("CRYPTO_PROVIDER_BASE_URL"),
 		Port:                  envDefault("PORT", "8080"),
+		DebugAddr:             strings.TrimSpace(os.Getenv("WORKER_DEBUG_ADDR")),
+		AdminUserIDs:          envList("ADMIN_USER_IDS"),
 	}
 
 	var validationErrs []string
+	cfg.StockProviders = loadProviderSettings("STOCK_PROVIDER", cfg.StockProviderName, cfg.StockProviderAPIKey, cfg.StockProviderBaseURL, &validationErrs)
+	cfg.CryptoProviders = loadProviderSettings("CRYPTO_PROVIDER", cfg.CryptoProviderName, cfg.CryptoProviderAPIKey, cfg.CryptoProviderBaseURL, &validationErrs)
+	cfg.StockProviderHTTP = loadHTTPProviderSettings("STOCK_PROVIDER_HTTP_", &validationErrs)
+	cfg.CryptoProviderHTTP = loadHTTPProviderSettings("CRYPTO_PROVIDER_HTTP_", &validationErrs)
+	cfg.StockProviderQuorum = loadQuorumSettings("STOCK_PROVIDER", cfg.StockProviders, &validationErrs)
+	cfg.CryptoProviderQuorum = loadQuorumSettings("CRYPTO_PROVIDER", cfg.CryptoProviders, &validationErrs)
 	requireEnv("DATABASE_URL", cfg.DatabaseURL, &validationErrs)
 
 	switch mode {
 	case ModeWorker:
 		requireEnv("CRYPTO_PROVIDER_NAME", cfg.CryptoProviderName, &validationErrs)
-		requireEnv("CRYPTO_PROVIDER_API_KEY", cfg.CryptoProviderAPIKey, &validationErrs)
+		requireProviderKeys("CRYPTO_PROVIDER", cfg.CryptoProviders, &validationErrs)
+	case ModeCatalog:
+		requireEnv("CRYPTO_PROVIDER_NAME", cfg.CryptoProviderName, &validationErrs)
+		requireProviderKeys("CRYPTO_PROVIDER", cfg.CryptoProviders, &validationErrs)
 	case ModeWS:
 		requireEnv("SUPABASE_URL", cfg.SupabaseURL, &validationErrs)
 		requireEnv("SUPABASE_SECRET_KEY", cfg.SupabaseSecretKey, &validationErrs)
@@ -77,6 +141,159 @@ func envDefault(key, fallback string) string {
 	return fallback
 }
 
+// envList splits a comma-separated variable, dropping empty entries.
+func envList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// loadProviderSettings reads each provider of the names list. A provider's
+// <prefix>_<NAME>_API_KEY and <prefix>_<NAME>_BASE_URL override the shared
+// key, and the shared base URL, which only applies to the first provider.
+// <prefix>_<NAME>_BATCH_SIZE and <prefix>_<NAME>_REQUESTS_PER_MINUTE override
+// its limits. The offline providers read <prefix>_SIMULATED_SEED and
+// <prefix>_FIXTURE_PATH.
+func loadProviderSettings(prefix, names, apiKey, baseURL string, errs *[]string) []ProviderSettings {
+	var settings []ProviderSettings
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		provider := ProviderSettings{Name: name, APIKey: apiKey}
+		if len(settings) == 0 {
+			provider.BaseURL = baseURL
+		}
+		envName := prefix + "_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		if value := os.Getenv(envName + "_API_KEY"); value != "" {
+			provider.APIKey = value
+		}
+		if value := os.Getenv(envName + "_BASE_URL"); value != "" {
+			provider.BaseURL = value
+		}
+		// The http provider's batch size is one of its HTTP settings.
+		if name != "http" {
+			provider.BatchSize = envPositiveInt(envName+"_BATCH_SIZE", errs)
+		}
+		provider.RequestsPerMinute = envPositiveInt(envName+"_REQUESTS_PER_MINUTE", errs)
+		switch name {
+		case "simulated":
+			provider.Seed = envInt64(envName+"_SEED", errs)
+		case "fixture":
+			provider.Path = strings.TrimSpace(os.Getenv(envName + "_PATH"))
+			requireEnv(envName+"_PATH", provider.Path, errs)
+		}
+		settings = append(settings, provider)
+	}
+	return settings
+}
+
+// keylessProviders need no API key. The http provider's key is optional and
+// checked when the provider is built, since only its URL template and auth
+// header tell whether it needs one.
+var keylessProviders = map[string]bool{"stooq": true, "simulated": true, "fixture": true, "http": true}
+
+// requireProviderKeys reports every provider without an API key that needs
+// one.
+func requireProviderKeys(prefix string, providers []ProviderSettings, errs *[]string) {
+	if len(providers) <= 1 {
+		var apiKey string
+		if len(providers) == 1 {
+			if keylessProviders[providers[0].Name] {
+				return
+			}
+			apiKey = providers[0].APIKey
+		}
+		requireEnv(prefix+"_API_KEY", apiKey, errs)
+		return
+	}
+	for _, provider := range providers {
+		if keylessProviders[provider.Name] {
+			continue
+		}
+		if strings.TrimSpace(provider.APIKey) == "" {
+			envName := prefix + "_" + strings.ToUpper(strings.ReplaceAll(provider.Name, "-", "_"))
+			*errs = append(*errs, envName+"_API_KEY or "+prefix+"_API_KEY is required")
+		}
+	}
+}
+
+// loadQuorumSettings reads <prefix>_MODE (chain or quorum) and, for quorum,
+// <prefix>_QUORUM_TOLERANCE and <prefix>_QUORUM_MIN_QUOTES.
+func loadQuorumSettings(prefix string, providers []ProviderSettings, errs *[]string) QuorumSettings {
+	settings := QuorumSettings{Tolerance: defaultQuorumTolerance, MinQuotes: defaultQuorumMinQuotes}
+	switch mode := strings.ToLower(strings.TrimSpace(os.Getenv(prefix + "_MODE"))); mode {
+	case "", "chain":
+		return settings
+	case "quorum":
+		settings.Enabled = true
+	default:
+		*errs = append(*errs, prefix+"_MODE must be chain or quorum")
+		return settings
+	}
+
+	if len(providers) < 2 {
+		*errs = append(*errs, prefix+"_MODE=quorum requires at least two providers in "+prefix+"_NAME")
+	}
+	if raw := strings.TrimSpace(os.Getenv(prefix + "_QUORUM_TOLERANCE")); raw != "" {
+		tolerance, err := strconv.ParseFloat(raw, 64)
+		if err != nil || tolerance <= 0 || tolerance >= 1 {
+			*errs = append(*errs, prefix+"_QUORUM_TOLERANCE must be a number between 0 and 1")
+		}
+		settings.Tolerance = tolerance
+	}
+	if raw := strings.TrimSpace(os.Getenv(prefix + "_QUORUM_MIN_QUOTES")); raw != "" {
+		minQuotes, err := strconv.Atoi(raw)
+		if err != nil || minQuotes <= 0 || minQuotes > len(providers) {
+			*errs = append(*errs, prefix+"_QUORUM_MIN_QUOTES must be between 1 and the number of providers")
+		}
+		settings.MinQuotes = minQuotes
+	}
+	return settings
+}
+
+func loadHTTPProviderSettings(prefix string, errs *[]string) HTTPProviderSettings {
+	settings := HTTPProviderSettings{
+		AuthHeader:  strings.TrimSpace(os.Getenv(prefix + "AUTH_HEADER")),
+		ResultsPath: strings.TrimSpace(os.Getenv(prefix + "RESULTS_PATH")),
+		KeyField:    strings.TrimSpace(os.Getenv(prefix + "KEY_FIELD")),
+		PriceField:  strings.TrimSpace(os.Getenv(prefix + "PRICE_FIELD")),
+	}
+	settings.BatchSize = envPositiveInt(prefix+"BATCH_SIZE", errs)
+	return settings
+}
+
+// envPositiveInt reads an optional positive integer; unset is 0.
+func envPositiveInt(key string, errs *[]string) int {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return 0
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		*errs = append(*errs, key+" must be a positive integer")
+	}
+	return value
+}
+
+// envInt64 reads an optional integer; unset is 0.
+func envInt64(key string, errs *[]string) int64 {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return 0
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		*errs = append(*errs, key+" must be an integer")
+	}
+	return value
+}
+
 func requireEnv(name, value string, errs *[]string) {
 	if strings.TrimSpace(value) == "" {
 		*errs = append(*errs, name+" is required")
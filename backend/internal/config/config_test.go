@@ -18,7 +18,31 @@ func clearConfigEnv(t *testing.T) {
 		"CRYPTO_PROVIDER_API_KEY",
 		"CRYPTO_PROVIDER_NAME",
 		"CRYPTO_PROVIDER_BASE_URL",
+		"STOCK_PROVIDER_HTTP_AUTH_HEADER",
+		"STOCK_PROVIDER_HTTP_BATCH_SIZE",
+		"STOCK_PROVIDER_HTTP_RESULTS_PATH",
+		"STOCK_PROVIDER_HTTP_KEY_FIELD",
+		"STOCK_PROVIDER_HTTP_PRICE_FIELD",
+		"CRYPTO_PROVIDER_HTTP_AUTH_HEADER",
+		"CRYPTO_PROVIDER_HTTP_BATCH_SIZE",
+		"CRYPTO_PROVIDER_HTTP_RESULTS_PATH",
+		"CRYPTO_PROVIDER_HTTP_KEY_FIELD",
+		"CRYPTO_PROVIDER_HTTP_PRICE_FIELD",
+		"CRYPTO_PROVIDER_MOBULA_API_KEY",
+		"CRYPTO_PROVIDER_COINGECKO_API_KEY",
+		"CRYPTO_PROVIDER_COINGECKO_BASE_URL",
+		"CRYPTO_PROVIDER_COINGECKO_BATCH_SIZE",
+		"CRYPTO_PROVIDER_COINGECKO_REQUESTS_PER_MINUTE",
+		"CRYPTO_PROVIDER_SIMULATED_SEED",
+		"CRYPTO_PROVIDER_FIXTURE_PATH",
+		"STOCK_PROVIDER_FIXTURE_PATH",
+		"STOCK_PROVIDER_MODE",
+		"CRYPTO_PROVIDER_MODE",
+		"CRYPTO_PROVIDER_QUORUM_TOLERANCE",
+		"CRYPTO_PROVIDER_QUORUM_MIN_QUOTES",
 		"PORT",
+		"WORKER_DEBUG_ADDR",
+		"ADMIN_USER_IDS",
 	} {
 		t.Setenv(key, "")
 	}
@@ -63,6 +87,22 @@ func TestLoadForWSValidation(t *testing.T) {
 	}
 }
 
+func TestLoadForWSParsesAdminUserIDs(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("DATABASE_URL", "postgresql://db")
+	t.Setenv("SUPABASE_URL", "https://supabase.example.com")
+	t.Setenv("SUPABASE_SECRET_KEY", "service-key")
+	t.Setenv("ADMIN_USER_IDS", " admin-1, ,admin-2 ")
+
+	cfg, err := LoadForWS()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(cfg.AdminUserIDs) != 2 || cfg.AdminUserIDs[0] != "admin-1" || cfg.AdminUserIDs[1] != "admin-2" {
+		t.Fatalf("unexpected admin user ids: %q", cfg.AdminUserIDs)
+	}
+}
+
 func TestLoadForWorkerSuccessUsesDefaultPort(t *testing.T) {
 	clearConfigEnv(t)
 	t.Setenv("DATABASE_URL", "postgresql://db")
@@ -91,6 +131,150 @@ func TestLoadForWorkerValidation(t *testing.T) {
 	}
 }
 
+func TestLoadForWorkerParsesHTTPProviderSettings(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("DATABASE_URL", "postgresql://db")
+	t.Setenv("CRYPTO_PROVIDER_NAME", "mobula")
+	t.Setenv("CRYPTO_PROVIDER_API_KEY", "key")
+	t.Setenv("STOCK_PROVIDER_HTTP_AUTH_HEADER", "X-Api-Key")
+	t.Setenv("STOCK_PROVIDER_HTTP_BATCH_SIZE", "25")
+	t.Setenv("STOCK_PROVIDER_HTTP_RESULTS_PATH", "data.quotes")
+	t.Setenv("STOCK_PROVIDER_HTTP_KEY_FIELD", "symbol")
+	t.Setenv("STOCK_PROVIDER_HTTP_PRICE_FIELD", "last.price")
+
+	cfg, err := LoadForWorker()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := HTTPProviderSettings{AuthHeader: "X-Api-Key", BatchSize: 25, ResultsPath: "data.quotes", KeyField: "symbol", PriceField: "last.price"}
+	if cfg.StockProviderHTTP != want {
+		t.Fatalf("unexpected stock http settings: %+v", cfg.StockProviderHTTP)
+	}
+
+	t.Setenv("CRYPTO_PROVIDER_NAME", "http")
+	t.Setenv("CRYPTO_PROVIDER_API_KEY", "")
+	t.Setenv("CRYPTO_PROVIDER_BASE_URL", "https://quotes.example.com/v1?ids={keys}")
+	if _, err := LoadForWorker(); err != nil {
+		t.Fatalf("expected the http provider to load without an API key, got %v", err)
+	}
+	t.Setenv("CRYPTO_PROVIDER_NAME", "mobula")
+	t.Setenv("CRYPTO_PROVIDER_API_KEY", "key")
+
+	t.Setenv("CRYPTO_PROVIDER_HTTP_BATCH_SIZE", "0")
+	_, err = LoadForWorker()
+	if err == nil || !strings.Contains(err.Error(), "CRYPTO_PROVIDER_HTTP_BATCH_SIZE must be a positive integer") {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+}
+
+func TestLoadForWorkerParsesProviderChain(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("DATABASE_URL", "postgresql://db")
+	t.Setenv("CRYPTO_PROVIDER_NAME", "Mobula, coingecko")
+	t.Setenv("CRYPTO_PROVIDER_BASE_URL", "https://mobula.example.com")
+	t.Setenv("CRYPTO_PROVIDER_MOBULA_API_KEY", "mobula-key")
+
+	_, err := LoadForWorker()
+	if err == nil || !strings.Contains(err.Error(), "CRYPTO_PROVIDER_COINGECKO_API_KEY or CRYPTO_PROVIDER_API_KEY is required") {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	t.Setenv("CRYPTO_PROVIDER_COINGECKO_API_KEY", "gecko-key")
+	t.Setenv("CRYPTO_PROVIDER_COINGECKO_BATCH_SIZE", "100")
+	t.Setenv("CRYPTO_PROVIDER_COINGECKO_REQUESTS_PER_MINUTE", "10")
+	cfg, err := LoadForWorker()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := []ProviderSettings{
+		{Name: "mobula", APIKey: "mobula-key", BaseURL: "https://mobula.example.com"},
+		{Name: "coingecko", APIKey: "gecko-key", BatchSize: 100, RequestsPerMinute: 10},
+	}
+	if len(cfg.CryptoProviders) != 2 || cfg.CryptoProviders[0] != want[0] || cfg.CryptoProviders[1] != want[1] {
+		t.Fatalf("unexpected crypto providers: %+v", cfg.CryptoProviders)
+	}
+
+	t.Setenv("CRYPTO_PROVIDER_COINGECKO_REQUESTS_PER_MINUTE", "-1")
+	_, err = LoadForWorker()
+	if err == nil || !strings.Contains(err.Error(), "CRYPTO_PROVIDER_COINGECKO_REQUESTS_PER_MINUTE must be a positive integer") {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+}
+
+func TestLoadForWorkerParsesQuorumSettings(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("DATABASE_URL", "postgresql://db")
+	t.Setenv("CRYPTO_PROVIDER_NAME", "mobula,coingecko")
+	t.Setenv("CRYPTO_PROVIDER_API_KEY", "key")
+	t.Setenv("CRYPTO_PROVIDER_MODE", "quorum")
+	t.Setenv("CRYPTO_PROVIDER_QUORUM_TOLERANCE", "0.02")
+	t.Setenv("CRYPTO_PROVIDER_QUORUM_MIN_QUOTES", "2")
+
+	cfg, err := LoadForWorker()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := QuorumSettings{Enabled: true, Tolerance: 0.02, MinQuotes: 2}
+	if cfg.CryptoProviderQuorum != want || cfg.StockProviderQuorum.Enabled {
+		t.Fatalf("unexpected quorum settings: %+v %+v", cfg.CryptoProviderQuorum, cfg.StockProviderQuorum)
+	}
+
+	t.Setenv("CRYPTO_PROVIDER_QUORUM_TOLERANCE", "")
+	t.Setenv("CRYPTO_PROVIDER_QUORUM_MIN_QUOTES", "")
+	cfg, err = LoadForWorker()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if want := (QuorumSettings{Enabled: true, Tolerance: 0.05, MinQuotes: 2}); cfg.CryptoProviderQuorum != want {
+		t.Fatalf("unexpected default quorum settings: %+v", cfg.CryptoProviderQuorum)
+	}
+
+	t.Setenv("CRYPTO_PROVIDER_NAME", "mobula")
+	t.Setenv("CRYPTO_PROVIDER_QUORUM_TOLERANCE", "5")
+	t.Setenv("CRYPTO_PROVIDER_QUORUM_MIN_QUOTES", "2")
+	_, err = LoadForWorker()
+	if err == nil || !strings.Contains(err.Error(), "requires at least two providers") || !strings.Contains(err.Error(), "CRYPTO_PROVIDER_QUORUM_TOLERANCE must be") || !strings.Contains(err.Error(), "CRYPTO_PROVIDER_QUORUM_MIN_QUOTES must be") {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+}
+
+func TestLoadForWorkerAllowsOfflineProvidersWithoutKeys(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("DATABASE_URL", "postgresql://db")
+	t.Setenv("CRYPTO_PROVIDER_NAME", "simulated")
+	t.Setenv("CRYPTO_PROVIDER_SIMULATED_SEED", "42")
+	t.Setenv("STOCK_PROVIDER_NAME", "fixture")
+	t.Setenv("STOCK_PROVIDER_FIXTURE_PATH", "testdata/stocks.csv")
+
+	cfg, err := LoadForWorker()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(cfg.CryptoProviders) != 1 || cfg.CryptoProviders[0].Seed != 42 {
+		t.Fatalf("unexpected crypto providers: %+v", cfg.CryptoProviders)
+	}
+	if len(cfg.StockProviders) != 1 || cfg.StockProviders[0].Path != "testdata/stocks.csv" {
+		t.Fatalf("unexpected stock providers: %+v", cfg.StockProviders)
+	}
+
+	t.Setenv("CRYPTO_PROVIDER_NAME", "fixture,mobula")
+	t.Setenv("CRYPTO_PROVIDER_SIMULATED_SEED", "")
+	_, err = LoadForWorker()
+	if err == nil || !strings.Contains(err.Error(), "CRYPTO_PROVIDER_FIXTURE_PATH is required") || !strings.Contains(err.Error(), "CRYPTO_PROVIDER_MOBULA_API_KEY or CRYPTO_PROVIDER_API_KEY is required") {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+	if strings.Contains(err.Error(), "CRYPTO_PROVIDER_FIXTURE_API_KEY") {
+		t.Fatalf("expected no key required for fixture: %v", err)
+	}
+
+	t.Setenv("CRYPTO_PROVIDER_NAME", "simulated")
+	t.Setenv("CRYPTO_PROVIDER_SIMULATED_SEED", "abc")
+	_, err = LoadForWorker()
+	if err == nil || !strings.Contains(err.Error(), "CRYPTO_PROVIDER_SIMULATED_SEED must be an integer") {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+}
+
 func TestLoadUnknownMode(t *testing.T) {
 	clearConfigEnv(t)
 	t.Setenv("DATABASE_URL", "postgresql://db")
@@ -103,3 +287,16 @@ func TestLoadUnknownMode(t *testing.T) {
 		t.Fatalf("unexpected error for unknown mode: %v", err)
 	}
 }
+
+func TestLoadForCatalogValidation(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("DATABASE_URL", "postgresql://db")
+
+	_, err := LoadForCatalog()
+	if err == nil {
+		t.Fatal("expected validation error, got nil")
+	}
+	if !strings.Contains(err.Error(), "CRYPTO_PROVIDER_NAME is required") || !strings.Contains(err.Error(), "CRYPTO_PROVIDER_API_KEY is required") {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+}
@@ -0,0 +1,254 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const AlertEventsChannel = "alert_events"
+
+func (d *DB) ListAlertsByUser(ctx context.Context, userID string) ([]Alert, error) {
+	rows, err := d.pool.Query(ctx, `
+		select id, user_id, asset_id, kind, threshold, cooldown_sec, hysteresis_pct, enabled, armed, last_triggered_at, created_at, updated_at
+		from public.alerts
+		where user_id = $1
+		order by created_at desc, id desc
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []Alert
+	for rows.Next() {
+		var alert Alert
+		if err := rows.Scan(&alert.ID, &alert.UserID, &alert.AssetID, &alert.Kind, &alert.Threshold, &alert.CooldownSec, &alert.HysteresisPct, &alert.Enabled, &alert.Armed, &alert.LastTriggeredAt, &alert.CreatedAt, &alert.UpdatedAt); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, rows.Err()
+}
+
+func (d *DB) InsertAlert(ctx context.Context, alert Alert) (int64, error) {
+	row := d.pool.QueryRow(ctx, `
+		insert into public.alerts (user_id, asset_id, kind, threshold, cooldown_sec, hysteresis_pct, enabled)
+		values ($1, $2, $3::public.alert_kind, $4, $5, $6, $7)
+		returning id
+	`, alert.UserID, alert.AssetID, string(alert.Kind), alert.Threshold, alert.CooldownSec, alert.HysteresisPct, alert.Enabled)
+
+	var id int64
+	err := row.Scan(&id)
+	if isConstraintViolation(err, "alerts_asset_id_fkey") {
+		return 0, ErrAssetNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// UpdateAlertForUser applies the non-nil fields of patch. Changing the
+// threshold re-arms the alert so it can fire against the new level.
+func (d *DB) UpdateAlertForUser(ctx context.Context, userID string, alertID int64, patch AlertPatch) (bool, error) {
+	tag, err := d.pool.Exec(ctx, `
+		update public.alerts
+		set threshold = coalesce($1, threshold),
+			cooldown_sec = coalesce($2, cooldown_sec),
+			hysteresis_pct = coalesce($3, hysteresis_pct),
+			enabled = coalesce($4, enabled),
+			armed = case when $1::numeric is not null then true else armed end
+		where id = $5 and user_id = $6
+	`, patch.Threshold, patch.CooldownSec, patch.HysteresisPct, patch.Enabled, alertID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (d *DB) DeleteAlertForUser(ctx context.Context, userID string, alertID int64) (bool, error) {
+	tag, err := d.pool.Exec(ctx, `
+		delete from public.alerts
+		where id = $1 and user_id = $2
+	`, alertID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (d *DB) ListAlertEventsByUser(ctx context.Context, userID string, limit int) ([]AlertEvent, error) {
+	rows, err := d.pool.Query(ctx, `
+		select id, alert_id, user_id, asset_id, kind, threshold, observed_value, price, triggered_at
+		from public.alert_events
+		where user_id = $1
+		order by triggered_at desc, id desc
+		limit $2
+	`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []AlertEvent
+	for rows.Next() {
+		var event AlertEvent
+		if err := rows.Scan(&event.ID, &event.AlertID, &event.UserID, &event.AssetID, &event.Kind, &event.Threshold, &event.ObservedValue, &event.Price, &event.TriggeredAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func (d *DB) FetchAlertCandidates(ctx context.Context, assetIDs []int64, now time.Time) ([]AlertCandidate, error) {
+	if len(assetIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := d.pool.Query(ctx, `
+		select
+			a.id, a.user_id, a.asset_id, a.kind, a.threshold, a.cooldown_sec, a.hysteresis_pct, a.enabled, a.armed, a.last_triggered_at, a.created_at, a.updated_at,
+			pv.avg_cost,
+			ps.price
+		from public.alerts a
+		left join public.positions_view pv on pv.user_id = a.user_id and pv.asset_id = a.asset_id
+		left join lateral (
+			select price
+			from public.price_snapshots
+			where asset_id = a.asset_id
+			and fetched_at <= $2::timestamptz - interval '24 hours'
+			order by fetched_at desc
+			limit 1
+		) ps on a.kind = 'change_24h'
+		where a.enabled
+		and a.asset_id = any($1::bigint[])
+	`, assetIDs, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []AlertCandidate
+	for rows.Next() {
+		var c AlertCandidate
+		if err := rows.Scan(&c.ID, &c.UserID, &c.AssetID, &c.Kind, &c.Threshold, &c.CooldownSec, &c.HysteresisPct, &c.Enabled, &c.Armed, &c.LastTriggeredAt, &c.CreatedAt, &c.UpdatedAt, &c.AvgCost, &c.Price24hAgo); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+type alertEventNotification struct {
+	ID            int64     `json:"id"`
+	AlertID       int64     `json:"alert_id"`
+	UserID        string    `json:"user_id"`
+	AssetID       int64     `json:"asset_id"`
+	Kind          AlertKind `json:"kind"`
+	Threshold     float64   `json:"threshold"`
+	ObservedValue float64   `json:"observed_value"`
+	Price         float64   `json:"price"`
+	TriggeredAt   time.Time `json:"triggered_at"`
+}
+
+// RecordAlertEvaluations stores fired events, disarms their alerts, re-arms
+// alerts whose condition has cleared, notifies listeners on
+// AlertEventsChannel and queues webhook deliveries, all in one transaction.
+// Returned events carry their ids.
+func (d *DB) RecordAlertEvaluations(ctx context.Context, fired []AlertEvent, rearmIDs []int64) ([]AlertEvent, error) {
+	if len(fired) == 0 && len(rearmIDs) == 0 {
+		return nil, nil
+	}
+
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	recorded := make([]AlertEvent, 0, len(fired))
+	for _, event := range fired {
+		if err := tx.QueryRow(ctx, `
+			insert into public.alert_events (alert_id, user_id, asset_id, kind, threshold, observed_value, price, triggered_at)
+			values ($1, $2, $3, $4::public.alert_kind, $5, $6, $7, $8)
+			returning id
+		`, event.AlertID, event.UserID, event.AssetID, string(event.Kind), event.Threshold, event.ObservedValue, event.Price, event.TriggeredAt).Scan(&event.ID); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(ctx, `
+			update public.alerts
+			set armed = false, last_triggered_at = $1
+			where id = $2
+		`, event.TriggeredAt, event.AlertID); err != nil {
+			return nil, err
+		}
+		if err := notify(ctx, tx, AlertEventsChannel, alertEventNotification(event)); err != nil {
+			return nil, err
+		}
+		if err := enqueueWebhookEvent(ctx, tx, event.UserID, WebhookEventAlertTriggered, alertEventNotification(event)); err != nil {
+			return nil, err
+		}
+		recorded = append(recorded, event)
+	}
+
+	if len(rearmIDs) > 0 {
+		if _, err := tx.Exec(ctx, `
+			update public.alerts
+			set armed = true
+			where id = any($1::bigint[])
+		`, rearmIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return recorded, nil
+}
+
+// ListenAlertEvents blocks, invoking fn for every alert event notified by the
+// worker, until ctx is cancelled or the connection fails.
+func (d *DB) ListenAlertEvents(ctx context.Context, fn func(AlertEvent)) error {
+	return d.listen(ctx, AlertEventsChannel, func(payload string) {
+		var n alertEventNotification
+		if err := json.Unmarshal([]byte(payload), &n); err != nil {
+			return
+		}
+		fn(AlertEvent(n))
+	})
+}
+
+func notify(ctx context.Context, tx pgx.Tx, channel string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `select pg_notify($1, $2)`, channel, string(body))
+	return err
+}
+
+func (d *DB) listen(ctx context.Context, channel string, fn func(payload string)) error {
+	conn, err := d.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	defer func() { _, _ = conn.Exec(context.Background(), "unlisten *") }()
+
+	if _, err := conn.Exec(ctx, "listen "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		fn(notification.Payload)
+	}
+}
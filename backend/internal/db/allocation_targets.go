@@ -0,0 +1,56 @@
+package db
+
+import "context"
+
+func (d *DB) ListAllocationTargets(ctx context.Context, userID string) ([]AllocationTarget, error) {
+	rows, err := d.pool.Query(ctx, `
+		select user_id, coalesce(asset_id, 0), coalesce(asset_type::text, ''), weight
+		from public.allocation_targets
+		where user_id = $1
+		order by asset_type nulls last, asset_id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []AllocationTarget
+	for rows.Next() {
+		var target AllocationTarget
+		if err := rows.Scan(&target.UserID, &target.AssetID, &target.AssetType, &target.Weight); err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+	return targets, rows.Err()
+}
+
+// ReplaceAllocationTargets swaps the user's targets for targets in one
+// transaction. It returns ErrAssetNotFound when an asset id does not exist.
+func (d *DB) ReplaceAllocationTargets(ctx context.Context, userID string, targets []AllocationTarget) error {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `
+		delete from public.allocation_targets
+		where user_id = $1
+	`, userID); err != nil {
+		return err
+	}
+	for _, target := range targets {
+		_, err := tx.Exec(ctx, `
+			insert into public.allocation_targets (user_id, asset_id, asset_type, weight)
+			values ($1, nullif($2, 0), nullif($3, '')::public.asset_type, $4)
+		`, userID, target.AssetID, string(target.AssetType), target.Weight)
+		if isForeignKeyViolation(err) {
+			return ErrAssetNotFound
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
@@ -29,12 +29,33 @@ func (d *DB) ListAssetsByIDs(ctx context.Context, ids []int64) ([]Asset, error)
 	return assets, rows.Err()
 }
 
+// FetchTrackedAssets returns every asset held in a lot, on a watchlist, named
+// by an allocation target, used as a benchmark or watched by an enabled alert,
+// with the shortest refresh interval among the users tracking it.
 func (d *DB) FetchTrackedAssets(ctx context.Context) ([]TrackedAsset, error) {
 	rows, err := d.pool.Query(ctx, `
 		select a.id, a.symbol, coalesce(a.market_data_id, ''), coalesce(a.lookup_blockchain, ''), coalesce(a.lookup_address, ''), a.type, min(us.refresh_interval_sec) as min_refresh_interval_sec
 		from public.assets a
-		join public.lots l on l.asset_id = a.id
-		join public.user_settings us on us.user_id = l.user_id
+		join (
+			select asset_id, user_id
+			from public.lots
+			where deleted_at is null
+			union
+			select asset_id, user_id
+			from public.watchlist_assets
+			union
+			select asset_id, user_id
+			from public.allocation_targets
+			where asset_id is not null
+			union
+			select asset_id, user_id
+			from public.benchmarks
+			union
+			select asset_id, user_id
+			from public.alerts
+			where enabled
+		) tracked on tracked.asset_id = a.id
+		join public.user_settings us on us.user_id = tracked.user_id
 		group by a.id, a.symbol, a.market_data_id, a.lookup_blockchain, a.lookup_address, a.type
 		order by a.id
 	`)
@@ -78,3 +99,69 @@ func (d *DB) SearchAssets(ctx context.Context, query string, assetType string, l
 	}
 	return assets, rows.Err()
 }
+
+func (d *DB) ListAssetsByType(ctx context.Context, assetType AssetType) ([]Asset, error) {
+	rows, err := d.pool.Query(ctx, `
+		select id, symbol, coalesce(market_data_id, ''), coalesce(lookup_blockchain, ''), coalesce(lookup_address, ''), type, name
+		from public.assets
+		where type = $1::public.asset_type
+		order by id
+	`, string(assetType))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var assets []Asset
+	for rows.Next() {
+		var asset Asset
+		if err := rows.Scan(&asset.ID, &asset.Symbol, &asset.MarketDataID, &asset.LookupBlockchain, &asset.LookupAddress, &asset.Type, &asset.Name); err != nil {
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+	return assets, rows.Err()
+}
+
+// ApplyCatalogChanges inserts new assets and updates symbol/name of existing
+// ones in a single transaction; an update with a MarketDataID also links the
+// asset. Inserts that hit a unique constraint, on (symbol, type) or on
+// market_data_id, are skipped so a concurrent manual seed does not fail the
+// sync, and returned.
+func (d *DB) ApplyCatalogChanges(ctx context.Context, inserts []Asset, updates []Asset) ([]Asset, error) {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var skipped []Asset
+	for _, asset := range inserts {
+		tag, err := tx.Exec(ctx, `
+			insert into public.assets (symbol, market_data_id, type, name)
+			values ($1, $2, $3::public.asset_type, $4)
+			on conflict do nothing
+		`, asset.Symbol, asset.MarketDataID, string(asset.Type), asset.Name)
+		if err != nil {
+			return nil, err
+		}
+		if tag.RowsAffected() == 0 {
+			skipped = append(skipped, asset)
+		}
+	}
+
+	for _, asset := range updates {
+		if _, err := tx.Exec(ctx, `
+			update public.assets
+			set symbol = $1, name = $2, market_data_id = coalesce(nullif($4, ''), market_data_id)
+			where id = $3
+		`, asset.Symbol, asset.Name, asset.ID, asset.MarketDataID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return skipped, nil
+}
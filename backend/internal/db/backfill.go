@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ListBackfillCandidates returns assets held in a lot whose backfill has not
+// reached the earliest purchase. Assets whose last attempt failed are skipped
+// until it is older than retryBefore.
+func (d *DB) ListBackfillCandidates(ctx context.Context, retryBefore time.Time) ([]BackfillCandidate, error) {
+	rows, err := d.pool.Query(ctx, `
+		select a.id, a.symbol, coalesce(a.market_data_id, ''), coalesce(a.lookup_blockchain, ''), coalesce(a.lookup_address, ''), a.type,
+			min(l.purchased_at) as earliest_purchase,
+			b.covered_from,
+			(
+				select min(s.fetched_at)
+				from public.price_snapshots s
+				where s.asset_id = a.id and not s.backfilled
+			) as first_snapshot_at
+		from public.assets a
+		join public.lots l on l.asset_id = a.id and l.deleted_at is null
+		left join public.price_backfills b on b.asset_id = a.id
+		where b.last_error is null or b.attempted_at < $1
+		group by a.id, b.covered_from
+		having b.covered_from is null or min(l.purchased_at) < b.covered_from
+		order by a.id
+	`, retryBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []BackfillCandidate
+	for rows.Next() {
+		var candidate BackfillCandidate
+		if err := rows.Scan(
+			&candidate.ID,
+			&candidate.Symbol,
+			&candidate.MarketDataID,
+			&candidate.LookupBlockchain,
+			&candidate.LookupAddress,
+			&candidate.Type,
+			&candidate.EarliestPurchase,
+			&candidate.CoveredFrom,
+			&candidate.FirstSnapshotAt,
+		); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, rows.Err()
+}
+
+// InsertBackfilledSnapshots stores daily prices as backfilled snapshots and
+// returns how many were new. Days already backfilled are left alone.
+func (d *DB) InsertBackfilledSnapshots(ctx context.Context, updates []PriceUpdate) (int64, error) {
+	if len(updates) == 0 {
+		return 0, nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, update := range updates {
+		batch.Queue(`
+			insert into public.price_snapshots (asset_id, price, fetched_at, provider, backfilled)
+			values ($1, $2, $3, $4, true)
+			on conflict (asset_id, fetched_at) where backfilled do nothing
+		`, update.AssetID, update.Price, update.FetchedAt, update.Provider)
+	}
+	br := d.pool.SendBatch(ctx, batch)
+	defer br.Close()
+
+	var inserted int64
+	for range updates {
+		tag, err := br.Exec()
+		if err != nil {
+			return inserted, err
+		}
+		inserted += tag.RowsAffected()
+	}
+	return inserted, nil
+}
+
+func (d *DB) SaveBackfillProgress(ctx context.Context, progress BackfillProgress) error {
+	_, err := d.pool.Exec(ctx, `
+		insert into public.price_backfills (asset_id, covered_from, last_error, attempted_at)
+		values ($1, $2, nullif($3, ''), now())
+		on conflict (asset_id)
+		do update set covered_from = excluded.covered_from, last_error = excluded.last_error, attempted_at = excluded.attempted_at
+	`, progress.AssetID, progress.CoveredFrom, progress.LastError)
+	return err
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+func (d *DB) ListBenchmarks(ctx context.Context, userID string) ([]int64, error) {
+	rows, err := d.pool.Query(ctx, `
+		select asset_id
+		from public.benchmarks
+		where user_id = $1
+		order by asset_id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var assetIDs []int64
+	for rows.Next() {
+		var assetID int64
+		if err := rows.Scan(&assetID); err != nil {
+			return nil, err
+		}
+		assetIDs = append(assetIDs, assetID)
+	}
+	return assetIDs, rows.Err()
+}
+
+// ReplaceBenchmarks swaps the user's benchmarks for assetIDs in one
+// transaction. It returns ErrAssetNotFound when an asset id does not exist.
+func (d *DB) ReplaceBenchmarks(ctx context.Context, userID string, assetIDs []int64) error {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `
+		delete from public.benchmarks
+		where user_id = $1
+	`, userID); err != nil {
+		return err
+	}
+	if len(assetIDs) > 0 {
+		_, err := tx.Exec(ctx, `
+			insert into public.benchmarks (user_id, asset_id)
+			select $1, unnest($2::bigint[])
+		`, userID, assetIDs)
+		if isForeignKeyViolation(err) {
+			return ErrAssetNotFound
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// ListPriceSeries returns, for each asset and each of times, the latest
+// snapshot price at or before that time, so gaps between snapshots carry the
+// last price forward. Price is invalid before an asset's first snapshot.
+func (d *DB) ListPriceSeries(ctx context.Context, assetIDs []int64, times []time.Time) ([]PricePoint, error) {
+	if len(assetIDs) == 0 || len(times) == 0 {
+		return nil, nil
+	}
+
+	rows, err := d.pool.Query(ctx, `
+		select a.asset_id, t.at, p.price
+		from unnest($1::bigint[]) as a(asset_id)
+		cross join unnest($2::timestamptz[]) as t(at)
+		left join lateral (
+			select s.price
+			from public.price_snapshots s
+			where s.asset_id = a.asset_id and s.fetched_at <= t.at
+			order by s.fetched_at desc
+			limit 1
+		) p on true
+		order by a.asset_id, t.at
+	`, assetIDs, times)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []PricePoint
+	for rows.Next() {
+		var point PricePoint
+		if err := rows.Scan(&point.AssetID, &point.At, &point.Price); err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+	}
+	return points, rows.Err()
+}
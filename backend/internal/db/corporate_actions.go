@@ -0,0 +1,216 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrCorporateActionNotFound = errors.New("corporate action not found")
+	ErrCorporateActionApplied  = errors.New("corporate action already applied")
+	ErrAssetSymbolTaken        = errors.New("asset symbol already in use")
+)
+
+const corporateActionColumns = "id, asset_id, kind, ratio, new_symbol, previous_symbol, effective_at, notes, coalesce(created_by::text, ''), created_at, applied_at"
+
+func (d *DB) InsertCorporateAction(ctx context.Context, action CorporateAction) (int64, error) {
+	var id int64
+	err := d.pool.QueryRow(ctx, `
+		insert into public.corporate_actions (asset_id, kind, ratio, new_symbol, effective_at, notes, created_by)
+		values ($1, $2::public.corporate_action_kind, $3, $4, $5, $6, nullif($7, '')::uuid)
+		returning id
+	`, action.AssetID, string(action.Kind), action.Ratio, action.NewSymbol, action.EffectiveAt, action.Notes, action.CreatedBy).Scan(&id)
+	if isForeignKeyViolation(err) {
+		return 0, ErrAssetNotFound
+	}
+	return id, err
+}
+
+// ListCorporateActions returns the newest actions first, optionally limited
+// to one asset when assetID is non-zero.
+func (d *DB) ListCorporateActions(ctx context.Context, assetID int64, limit int) ([]CorporateAction, error) {
+	rows, err := d.pool.Query(ctx, `
+		select `+corporateActionColumns+`
+		from public.corporate_actions
+		where ($1::bigint = 0 or asset_id = $1)
+		order by effective_at desc, id desc
+		limit $2
+	`, assetID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []CorporateAction
+	for rows.Next() {
+		action, err := scanCorporateAction(rows)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+	return actions, rows.Err()
+}
+
+// ApplyCorporateAction applies an action exactly once, in one transaction.
+// Splits, reverse splits and redenominations multiply the quantity of every
+// lot of the asset bought before the effective date, including soft-deleted
+// ones, by the ratio and divide its unit cost by the same ratio, so each lot's
+// cost basis is unchanged; every changed lot gets a corporate_action_adjustments
+// row. Prices recorded before the effective date and price alert thresholds
+// are divided by the ratio too, so price history and alerts stay comparable
+// with prices quoted after it. Ticker changes rename the asset. It returns the
+// adjusted lots.
+func (d *DB) ApplyCorporateAction(ctx context.Context, actionID int64) ([]CorporateActionAdjustment, error) {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	action, err := scanCorporateAction(tx.QueryRow(ctx, `
+		select `+corporateActionColumns+`
+		from public.corporate_actions
+		where id = $1
+		for update
+	`, actionID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCorporateActionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if action.AppliedAt.Valid {
+		return nil, ErrCorporateActionApplied
+	}
+
+	// Attributes the lot_events rows written by the lots trigger to this action.
+	if _, err := tx.Exec(ctx, `select set_config('app.actor', $1, true)`, "corporate_action:"+strconv.FormatInt(action.ID, 10)); err != nil {
+		return nil, err
+	}
+
+	var adjusted []CorporateActionAdjustment
+	switch action.Kind {
+	case CorporateActionTickerChange:
+		if !action.NewSymbol.Valid {
+			return nil, fmt.Errorf("corporate action %d has no new symbol", action.ID)
+		}
+		_, err = tx.Exec(ctx, `
+			with renamed as (
+				update public.assets a
+				set symbol = $2
+				from (select id, symbol from public.assets where id = $1 for update) old
+				where a.id = old.id
+				returning old.symbol
+			)
+			update public.corporate_actions
+			set previous_symbol = (select symbol from renamed)
+			where id = $3
+		`, action.AssetID, action.NewSymbol.String, action.ID)
+		if isUniqueViolation(err) {
+			return nil, ErrAssetSymbolTaken
+		}
+		if err != nil {
+			return nil, err
+		}
+	case CorporateActionSplit, CorporateActionReverseSplit, CorporateActionRedenomination:
+		if !action.Ratio.Valid || action.Ratio.Float64 <= 0 {
+			return nil, fmt.Errorf("corporate action %d has no ratio", action.ID)
+		}
+		if adjusted, err = rescaleLots(ctx, tx, action); err != nil {
+			return nil, err
+		}
+		if err := rescalePrices(ctx, tx, action); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown corporate action kind %q", action.Kind)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		update public.corporate_actions
+		set applied_at = now()
+		where id = $1
+	`, action.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return adjusted, nil
+}
+
+func rescaleLots(ctx context.Context, tx pgx.Tx, action CorporateAction) ([]CorporateActionAdjustment, error) {
+	rows, err := tx.Query(ctx, `
+		with adjusted as (
+			update public.lots l
+			set quantity = old.quantity * $2::numeric,
+				unit_cost = old.unit_cost / $2::numeric
+			from (
+				select id, quantity, unit_cost
+				from public.lots
+				where asset_id = $1 and purchased_at < $3
+				for update
+			) old
+			where l.id = old.id
+			returning l.id, l.user_id, old.quantity as quantity_before, l.quantity as quantity_after,
+				old.unit_cost as unit_cost_before, l.unit_cost as unit_cost_after, l.deleted_at is not null as deleted
+		), audit as (
+			insert into public.corporate_action_adjustments (action_id, lot_id, user_id, quantity_before, quantity_after, unit_cost_before, unit_cost_after)
+			select $4, id, user_id, quantity_before, quantity_after, unit_cost_before, unit_cost_after
+			from adjusted
+		)
+		select id, user_id::text, quantity_after, unit_cost_after, deleted
+		from adjusted
+		order by id
+	`, action.AssetID, action.Ratio.Float64, action.EffectiveAt, action.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var adjusted []CorporateActionAdjustment
+	for rows.Next() {
+		var lot CorporateActionAdjustment
+		if err := rows.Scan(&lot.LotID, &lot.UserID, &lot.Quantity, &lot.UnitCost, &lot.Deleted); err != nil {
+			return nil, err
+		}
+		adjusted = append(adjusted, lot)
+	}
+	return adjusted, rows.Err()
+}
+
+// rescalePrices divides the asset's prices from before the effective date,
+// and its price alert thresholds, by the action's ratio.
+func rescalePrices(ctx context.Context, tx pgx.Tx, action CorporateAction) error {
+	if _, err := tx.Exec(ctx, `
+		update public.price_snapshots
+		set price = price / $2::numeric
+		where asset_id = $1 and fetched_at < $3
+	`, action.AssetID, action.Ratio.Float64, action.EffectiveAt); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		update public.prices_current
+		set price = price / $2::numeric
+		where asset_id = $1 and fetched_at < $3
+	`, action.AssetID, action.Ratio.Float64, action.EffectiveAt); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `
+		update public.alerts
+		set threshold = threshold / $2::numeric
+		where asset_id = $1 and kind in ('price_above', 'price_below')
+	`, action.AssetID, action.Ratio.Float64)
+	return err
+}
+
+func scanCorporateAction(row pgx.Row) (CorporateAction, error) {
+	var action CorporateAction
+	err := row.Scan(&action.ID, &action.AssetID, &action.Kind, &action.Ratio, &action.NewSymbol, &action.PreviousSymbol, &action.EffectiveAt, &action.Notes, &action.CreatedBy, &action.CreatedAt, &action.AppliedAt)
+	return action, err
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestApplyCorporateActionSplitRescalesPrices(t *testing.T) {
+	database := mustOpenIntegrationDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	userID := randomUUID(t)
+	mustInsertAuthUser(t, ctx, database, userID, "corporate-split@example.com")
+	defer cleanupAuthUser(t, context.Background(), database, userID)
+
+	assetID := mustInsertStockAsset(t, ctx, database, "SPLT", "Split Corp")
+	defer cleanupAsset(t, context.Background(), database, assetID)
+	lotID := mustInsertLot(t, ctx, database, userID, assetID, 10, 100)
+
+	effectiveAt := time.Now().Add(-time.Minute)
+	if _, err := database.pool.Exec(ctx, `
+		insert into public.price_snapshots (asset_id, price, fetched_at, provider)
+		values ($1, 120, $2, 'test'), ($1, 61, $3, 'test')
+	`, assetID, effectiveAt.Add(-time.Hour), effectiveAt.Add(time.Second)); err != nil {
+		t.Fatalf("failed to insert snapshots: %v", err)
+	}
+	alertID, err := database.InsertAlert(ctx, Alert{UserID: userID, AssetID: assetID, Kind: AlertKindPriceBelow, Threshold: 90, CooldownSec: 3600, HysteresisPct: 0.01, Enabled: true})
+	if err != nil {
+		t.Fatalf("InsertAlert failed: %v", err)
+	}
+	actionID, err := database.InsertCorporateAction(ctx, CorporateAction{
+		AssetID:     assetID,
+		Kind:        CorporateActionSplit,
+		Ratio:       sql.NullFloat64{Float64: 2, Valid: true},
+		EffectiveAt: effectiveAt,
+	})
+	if err != nil {
+		t.Fatalf("InsertCorporateAction failed: %v", err)
+	}
+
+	adjusted, err := database.ApplyCorporateAction(ctx, actionID)
+	if err != nil {
+		t.Fatalf("ApplyCorporateAction failed: %v", err)
+	}
+	if len(adjusted) != 1 || adjusted[0].LotID != lotID || adjusted[0].UserID != userID || adjusted[0].Quantity != 20 || adjusted[0].UnitCost != 50 {
+		t.Fatalf("unexpected adjusted lots: %+v", adjusted)
+	}
+
+	var before, after float64
+	if err := database.pool.QueryRow(ctx, `
+		select
+			(select price from public.price_snapshots where asset_id = $1 and fetched_at < $2),
+			(select price from public.price_snapshots where asset_id = $1 and fetched_at > $2)
+	`, assetID, effectiveAt).Scan(&before, &after); err != nil {
+		t.Fatalf("failed to read snapshots: %v", err)
+	}
+	assertApproxEqual(t, before, 60, "pre-split snapshot")
+	assertApproxEqual(t, after, 61, "post-split snapshot")
+
+	var threshold float64
+	if err := database.pool.QueryRow(ctx, `select threshold from public.alerts where id = $1`, alertID).Scan(&threshold); err != nil {
+		t.Fatalf("failed to read alert: %v", err)
+	}
+	assertApproxEqual(t, threshold, 45, "alert threshold")
+}
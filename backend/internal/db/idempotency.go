@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ReserveIdempotencyKey claims key for a new request. It returns the stored
+// row and true when the caller owns the reservation, or the existing row and
+// false when the key is already in use. Expired keys, and reservations left
+// in flight longer than staleAfter, are taken over.
+func (d *DB) ReserveIdempotencyKey(ctx context.Context, key IdempotencyKey, ttl time.Duration, staleAfter time.Duration) (IdempotencyKey, bool, error) {
+	for range 2 {
+		reserved, err := scanIdempotencyKey(d.pool.QueryRow(ctx, `
+			insert into public.idempotency_keys (user_id, key, method, path, request_hash, expires_at)
+			values ($1, $2, $3, $4, $5, now() + make_interval(secs => $6))
+			on conflict (user_id, key) do update
+			set method = excluded.method,
+				path = excluded.path,
+				request_hash = excluded.request_hash,
+				status_code = null,
+				response_body = null,
+				response_content_type = null,
+				response_headers = '{}'::jsonb,
+				created_at = now(),
+				expires_at = excluded.expires_at
+			where public.idempotency_keys.expires_at <= now()
+			or (public.idempotency_keys.status_code is null and public.idempotency_keys.created_at <= now() - make_interval(secs => $7))
+			returning user_id, key, method, path, request_hash, status_code, response_body, response_content_type, response_headers, created_at, expires_at
+		`, key.UserID, key.Key, key.Method, key.Path, key.RequestHash, ttl.Seconds(), staleAfter.Seconds()))
+		if err == nil {
+			return reserved, true, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return IdempotencyKey{}, false, err
+		}
+
+		existing, err := scanIdempotencyKey(d.pool.QueryRow(ctx, `
+			select user_id, key, method, path, request_hash, status_code, response_body, response_content_type, response_headers, created_at, expires_at
+			from public.idempotency_keys
+			where user_id = $1 and key = $2
+		`, key.UserID, key.Key))
+		if err == nil {
+			return existing, false, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return IdempotencyKey{}, false, err
+		}
+		// The key was released between the two statements; try again.
+	}
+	return IdempotencyKey{}, false, errors.New("idempotency key reservation contended")
+}
+
+// CompleteIdempotencyKey stores the response to replay for key. headers holds
+// the response headers replayed besides Content-Type.
+func (d *DB) CompleteIdempotencyKey(ctx context.Context, userID string, key string, statusCode int, contentType string, headers map[string]string, body []byte) error {
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	_, err := d.pool.Exec(ctx, `
+		update public.idempotency_keys
+		set status_code = $3, response_content_type = nullif($4, ''), response_headers = $5, response_body = $6
+		where user_id = $1 and key = $2
+	`, userID, key, statusCode, contentType, headers, body)
+	return err
+}
+
+// ReleaseIdempotencyKey drops an in-flight reservation so the client can retry
+// a request that failed on the server side.
+func (d *DB) ReleaseIdempotencyKey(ctx context.Context, userID string, key string) error {
+	_, err := d.pool.Exec(ctx, `
+		delete from public.idempotency_keys
+		where user_id = $1 and key = $2 and status_code is null
+	`, userID, key)
+	return err
+}
+
+// PurgeExpiredIdempotencyKeys removes keys that expired before cutoff. Expired
+// keys are never replayed, only taken over by a new request with the same key.
+func (d *DB) PurgeExpiredIdempotencyKeys(ctx context.Context, cutoff time.Time) (int64, error) {
+	tag, err := d.pool.Exec(ctx, `
+		delete from public.idempotency_keys
+		where expires_at < $1
+	`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func scanIdempotencyKey(row pgx.Row) (IdempotencyKey, error) {
+	var key IdempotencyKey
+	err := row.Scan(&key.UserID, &key.Key, &key.Method, &key.Path, &key.RequestHash, &key.StatusCode, &key.ResponseBody, &key.ResponseContentType, &key.ResponseHeaders, &key.CreatedAt, &key.ExpiresAt)
+	return key, err
+}
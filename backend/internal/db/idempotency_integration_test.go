@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPurgeExpiredIdempotencyKeys(t *testing.T) {
+	database := mustOpenIntegrationDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	userID := randomUUID(t)
+	mustInsertAuthUser(t, ctx, database, userID, "idempotency-purge@example.com")
+	defer cleanupAuthUser(t, context.Background(), database, userID)
+
+	for _, key := range []string{"purge-a", "purge-b"} {
+		if _, reserved, err := database.ReserveIdempotencyKey(ctx, IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			Method:      "POST",
+			Path:        "/api/v1/lots",
+			RequestHash: "hash",
+		}, time.Hour, time.Minute); err != nil || !reserved {
+			t.Fatalf("ReserveIdempotencyKey(%s) = %v, %v", key, reserved, err)
+		}
+	}
+
+	countKeys := func() int {
+		t.Helper()
+		var count int
+		if err := database.pool.QueryRow(ctx, `select count(*) from public.idempotency_keys where user_id = $1`, userID).Scan(&count); err != nil {
+			t.Fatalf("failed to count idempotency keys: %v", err)
+		}
+		return count
+	}
+
+	if _, err := database.PurgeExpiredIdempotencyKeys(ctx, time.Now()); err != nil {
+		t.Fatalf("PurgeExpiredIdempotencyKeys failed: %v", err)
+	}
+	if got := countKeys(); got != 2 {
+		t.Fatalf("expected unexpired keys to be kept, got %d", got)
+	}
+
+	purged, err := database.PurgeExpiredIdempotencyKeys(ctx, time.Now().Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("PurgeExpiredIdempotencyKeys failed: %v", err)
+	}
+	if purged < 2 {
+		t.Fatalf("expected at least 2 purged keys, got %d", purged)
+	}
+	if got := countKeys(); got != 0 {
+		t.Fatalf("expected expired keys to be purged, got %d left", got)
+	}
+}
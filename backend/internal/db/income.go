@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrIncomeNotFound = errors.New("income not found")
+
+const incomeColumns = "id, user_id, asset_id, kind, amount, currency, received_at, lot_id, notes, created_at, updated_at"
+
+// ListIncomeForUser returns the most recently received income first,
+// optionally limited to one asset when assetID is non-zero.
+func (d *DB) ListIncomeForUser(ctx context.Context, userID string, assetID int64, limit int) ([]Income, error) {
+	rows, err := d.pool.Query(ctx, `
+		select `+incomeColumns+`
+		from public.income
+		where user_id = $1
+		and ($2::bigint = 0 or asset_id = $2)
+		order by received_at desc, id desc
+		limit $3
+	`, userID, assetID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []Income
+	for rows.Next() {
+		record, err := scanIncome(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// InsertIncome records income. When reinvest is non-nil, a lot with its
+// quantity and unit cost is created for the same asset at the time the income
+// was received, in the same transaction, and linked from the income record.
+// It returns the stored record and ErrAssetNotFound for an unknown asset.
+func (d *DB) InsertIncome(ctx context.Context, income Income, reinvest *Lot) (Income, error) {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return Income{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if reinvest != nil {
+		var lotID int64
+		err := tx.QueryRow(ctx, `
+			insert into public.lots (user_id, asset_id, quantity, unit_cost, purchased_at)
+			values ($1, $2, $3, $4, $5)
+			returning id
+		`, income.UserID, income.AssetID, reinvest.Quantity, reinvest.UnitCost, income.ReceivedAt).Scan(&lotID)
+		if isForeignKeyViolation(err) {
+			return Income{}, ErrAssetNotFound
+		}
+		if err != nil {
+			return Income{}, err
+		}
+		income.LotID = sql.NullInt64{Int64: lotID, Valid: true}
+	}
+
+	stored, err := scanIncome(tx.QueryRow(ctx, `
+		insert into public.income (user_id, asset_id, kind, amount, currency, received_at, lot_id, notes)
+		values ($1, $2, $3::public.income_kind, $4, $5, $6, $7, $8)
+		returning `+incomeColumns+`
+	`, income.UserID, income.AssetID, string(income.Kind), income.Amount, income.Currency, income.ReceivedAt, income.LotID, income.Notes))
+	if isForeignKeyViolation(err) {
+		return Income{}, ErrAssetNotFound
+	}
+	if err != nil {
+		return Income{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Income{}, err
+	}
+	return stored, nil
+}
+
+// UpdateIncomeForUser applies the non-nil fields of patch; an empty Notes
+// clears the notes. A reinvested lot is left as it is.
+func (d *DB) UpdateIncomeForUser(ctx context.Context, userID string, incomeID int64, patch IncomePatch) (bool, error) {
+	var kind *string
+	if patch.Kind != nil {
+		value := string(*patch.Kind)
+		kind = &value
+	}
+	tag, err := d.pool.Exec(ctx, `
+		update public.income
+		set kind = coalesce($1::public.income_kind, kind),
+			amount = coalesce($2, amount),
+			currency = coalesce($3, currency),
+			received_at = coalesce($4, received_at),
+			notes = case when $5::text is null then notes else nullif($5, '') end
+		where id = $6 and user_id = $7
+	`, kind, patch.Amount, patch.Currency, patch.ReceivedAt, patch.Notes, incomeID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteIncomeForUser removes an income record. A lot created by reinvesting
+// it is kept; it can be deleted through the lots API.
+func (d *DB) DeleteIncomeForUser(ctx context.Context, userID string, incomeID int64) (bool, error) {
+	tag, err := d.pool.Exec(ctx, `
+		delete from public.income
+		where id = $1 and user_id = $2
+	`, incomeID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func scanIncome(row pgx.Row) (Income, error) {
+	var income Income
+	err := row.Scan(&income.ID, &income.UserID, &income.AssetID, &income.Kind, &income.Amount, &income.Currency, &income.ReceivedAt, &income.LotID, &income.Notes, &income.CreatedAt, &income.UpdatedAt)
+	return income, err
+}
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var ErrAssetNotFound = errors.New("asset not found")
+
+// LotBatchError reports the operation that aborted a lot batch.
+type LotBatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *LotBatchError) Error() string {
+	return fmt.Sprintf("lot operation %d: %v", e.Index, e.Err)
+}
+
+func (e *LotBatchError) Unwrap() error {
+	return e.Err
+}
+
+// ApplyLotBatch runs ops for userID in order inside one transaction. Either
+// every operation is applied or none is; on failure the returned error is a
+// *LotBatchError naming the first failing operation.
+func (d *DB) ApplyLotBatch(ctx context.Context, userID string, ops []LotOperation) ([]LotOperationResult, error) {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	results := make([]LotOperationResult, 0, len(ops))
+	for i, op := range ops {
+		result := LotOperationResult{Kind: op.Kind, LotID: op.Lot.ID}
+
+		switch op.Kind {
+		case LotOperationCreate:
+			err = tx.QueryRow(ctx, `
+				insert into public.lots (user_id, asset_id, quantity, unit_cost, purchased_at, location)
+				values ($1, $2, $3, $4, $5, $6)
+				returning id
+			`, userID, op.Lot.AssetID, op.Lot.Quantity, op.Lot.UnitCost, op.Lot.PurchasedAt, op.Lot.Location).Scan(&result.LotID)
+			if isForeignKeyViolation(err) {
+				err = ErrAssetNotFound
+			}
+		case LotOperationUpdate:
+			var tag pgconn.CommandTag
+			tag, err = tx.Exec(ctx, `
+				update public.lots
+				set asset_id = coalesce($1, asset_id),
+					quantity = coalesce($2, quantity),
+					unit_cost = coalesce($3, unit_cost),
+					purchased_at = coalesce($4, purchased_at),
+					location = case when $8::text is null then location else nullif($8, '') end
+				where id = $5 and user_id = $6 and deleted_at is null
+				and ($7::bigint = 0 or revision = $7)
+			`, op.Patch.AssetID, op.Patch.Quantity, op.Patch.UnitCost, op.Patch.PurchasedAt, op.Lot.ID, userID, op.Lot.Revision, op.Patch.Location)
+			if isForeignKeyViolation(err) {
+				err = ErrAssetNotFound
+			}
+			if err == nil && tag.RowsAffected() == 0 {
+				err = batchLotMissError(ctx, tx, userID, op.Lot.ID)
+			}
+		case LotOperationDelete:
+			var tag pgconn.CommandTag
+			tag, err = tx.Exec(ctx, `
+				update public.lots
+				set deleted_at = now()
+				where id = $1 and user_id = $2 and deleted_at is null
+				and ($3::bigint = 0 or revision = $3)
+			`, op.Lot.ID, userID, op.Lot.Revision)
+			if err == nil && tag.RowsAffected() == 0 {
+				err = batchLotMissError(ctx, tx, userID, op.Lot.ID)
+			}
+		default:
+			err = fmt.Errorf("unknown lot operation %q", op.Kind)
+		}
+		if err != nil {
+			return nil, &LotBatchError{Index: i, Err: err}
+		}
+		results = append(results, result)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func batchLotMissError(ctx context.Context, tx pgx.Tx, userID string, lotID int64) error {
+	var exists bool
+	if err := tx.QueryRow(ctx, `
+		select exists (select 1 from public.lots where id = $1 and user_id = $2 and deleted_at is null)
+	`, lotID, userID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return ErrLotRevisionMismatch
+	}
+	return ErrLotNotFound
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+// isConstraintViolation reports whether err is a foreign key violation of the
+// named constraint.
+func isConstraintViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == constraint
+}
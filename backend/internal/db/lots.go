@@ -2,14 +2,33 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrLotNotFound         = errors.New("lot not found")
+	ErrLotRevisionMismatch = errors.New("lot revision mismatch")
+	ErrLotNotDeleted       = errors.New("lot is not deleted")
+	ErrLotRestoreExpired   = errors.New("lot restore window has expired")
 )
 
+// DeletedLotRetention is how long a soft-deleted lot can be restored before
+// the worker purges it.
+const DeletedLotRetention = 30 * 24 * time.Hour
+
+const lotColumns = "id, user_id, asset_id, quantity, unit_cost, purchased_at, created_at, updated_at, revision, location"
+
 func (d *DB) ListLotsByUser(ctx context.Context, userID string) ([]Lot, error) {
 	rows, err := d.pool.Query(ctx, `
-		select id, user_id, asset_id, quantity, unit_cost, purchased_at, created_at, updated_at
+		select `+lotColumns+`
 		from public.lots
-		where user_id = $1
+		where user_id = $1 and deleted_at is null
 		order by purchased_at desc
 	`, userID)
 	if err != nil {
@@ -19,8 +38,67 @@ func (d *DB) ListLotsByUser(ctx context.Context, userID string) ([]Lot, error) {
 
 	var lots []Lot
 	for rows.Next() {
-		var lot Lot
-		if err := rows.Scan(&lot.ID, &lot.UserID, &lot.AssetID, &lot.Quantity, &lot.UnitCost, &lot.PurchasedAt, &lot.CreatedAt, &lot.UpdatedAt); err != nil {
+		lot, err := scanLot(rows)
+		if err != nil {
+			return nil, err
+		}
+		lots = append(lots, lot)
+	}
+	return lots, rows.Err()
+}
+
+// ListLots returns one page of lots matching q, ordered by q.SortField then
+// id so pages are stable when sort values tie.
+func (d *DB) ListLots(ctx context.Context, q LotQuery) ([]Lot, error) {
+	sortColumn := "l.purchased_at"
+	if q.SortField == LotSortCreatedAt {
+		sortColumn = "l.created_at"
+	}
+	direction, comparison := "asc", ">"
+	if q.Descending {
+		direction, comparison = "desc", "<"
+	}
+
+	args := []any{q.UserID}
+	conditions := []string{"l.user_id = $1", "l.deleted_at is null"}
+	addCondition := func(format string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+	if q.AssetID > 0 {
+		addCondition("l.asset_id = $%d", q.AssetID)
+	}
+	if q.AssetType != "" {
+		addCondition("l.asset_id in (select id from public.assets where type = $%d::public.asset_type)", string(q.AssetType))
+	}
+	if !q.PurchasedFrom.IsZero() {
+		addCondition("l.purchased_at >= $%d", q.PurchasedFrom)
+	}
+	if !q.PurchasedTo.IsZero() {
+		addCondition("l.purchased_at < $%d", q.PurchasedTo)
+	}
+	if q.After != nil {
+		args = append(args, q.After.Value, q.After.ID)
+		conditions = append(conditions, fmt.Sprintf("(%s, l.id) %s ($%d, $%d)", sortColumn, comparison, len(args)-1, len(args)))
+	}
+	args = append(args, q.Limit)
+
+	rows, err := d.pool.Query(ctx, fmt.Sprintf(`
+		select `+lotColumns+`
+		from public.lots l
+		where %s
+		order by %s %s, l.id %s
+		limit $%d
+	`, strings.Join(conditions, " and "), sortColumn, direction, direction, len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lots []Lot
+	for rows.Next() {
+		lot, err := scanLot(rows)
+		if err != nil {
 			return nil, err
 		}
 		lots = append(lots, lot)
@@ -30,9 +108,9 @@ func (d *DB) ListLotsByUser(ctx context.Context, userID string) ([]Lot, error) {
 
 func (d *DB) ListLotsByUserAsset(ctx context.Context, userID string, assetID int64) ([]Lot, error) {
 	rows, err := d.pool.Query(ctx, `
-		select id, user_id, asset_id, quantity, unit_cost, purchased_at, created_at, updated_at
+		select `+lotColumns+`
 		from public.lots
-		where user_id = $1 and asset_id = $2
+		where user_id = $1 and asset_id = $2 and deleted_at is null
 		order by purchased_at desc
 	`, userID, assetID)
 	if err != nil {
@@ -42,8 +120,8 @@ func (d *DB) ListLotsByUserAsset(ctx context.Context, userID string, assetID int
 
 	var lots []Lot
 	for rows.Next() {
-		var lot Lot
-		if err := rows.Scan(&lot.ID, &lot.UserID, &lot.AssetID, &lot.Quantity, &lot.UnitCost, &lot.PurchasedAt, &lot.CreatedAt, &lot.UpdatedAt); err != nil {
+		lot, err := scanLot(rows)
+		if err != nil {
 			return nil, err
 		}
 		lots = append(lots, lot)
@@ -53,10 +131,10 @@ func (d *DB) ListLotsByUserAsset(ctx context.Context, userID string, assetID int
 
 func (d *DB) InsertLot(ctx context.Context, lot Lot) (int64, error) {
 	row := d.pool.QueryRow(ctx, `
-		insert into public.lots (user_id, asset_id, quantity, unit_cost, purchased_at)
-		values ($1, $2, $3, $4, $5)
+		insert into public.lots (user_id, asset_id, quantity, unit_cost, purchased_at, location)
+		values ($1, $2, $3, $4, $5, $6)
 		returning id
-	`, lot.UserID, lot.AssetID, lot.Quantity, lot.UnitCost, lot.PurchasedAt)
+	`, lot.UserID, lot.AssetID, lot.Quantity, lot.UnitCost, lot.PurchasedAt, lot.Location)
 
 	var id int64
 	if err := row.Scan(&id); err != nil {
@@ -69,38 +147,152 @@ func (d *DB) UpdateLot(ctx context.Context, lot Lot) error {
 	_, err := d.pool.Exec(ctx, `
 		update public.lots
 		set quantity = $1, unit_cost = $2, purchased_at = $3
-		where id = $4 and user_id = $5
+		where id = $4 and user_id = $5 and deleted_at is null
 	`, lot.Quantity, lot.UnitCost, lot.PurchasedAt, lot.ID, lot.UserID)
 	return err
 }
 
-func (d *DB) UpdateLotForUser(ctx context.Context, userID string, lotID int64, quantity float64, unitCost float64, purchasedAt time.Time) (bool, error) {
-	tag, err := d.pool.Exec(ctx, `
+// UpdateLotForUser applies the non-nil fields of patch and returns the lot
+// with its new revision. A non-zero expectedRevision makes the write
+// conditional: if the stored revision differs, the current lot is returned
+// with ErrLotRevisionMismatch.
+func (d *DB) UpdateLotForUser(ctx context.Context, userID string, lotID int64, patch LotPatch, expectedRevision int64) (Lot, error) {
+	lot, err := scanLot(d.pool.QueryRow(ctx, `
 		update public.lots
-		set quantity = $1, unit_cost = $2, purchased_at = $3
-		where id = $4 and user_id = $5
-	`, quantity, unitCost, purchasedAt, lotID, userID)
-	if err != nil {
-		return false, err
+		set asset_id = coalesce($1, asset_id),
+			quantity = coalesce($2, quantity),
+			unit_cost = coalesce($3, unit_cost),
+			purchased_at = coalesce($4, purchased_at),
+			location = case when $8::text is null then location else nullif($8, '') end
+		where id = $5 and user_id = $6 and deleted_at is null
+		and ($7::bigint = 0 or revision = $7)
+		returning `+lotColumns, patch.AssetID, patch.Quantity, patch.UnitCost, patch.PurchasedAt, lotID, userID, expectedRevision, patch.Location))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return d.lotPreconditionFailure(ctx, userID, lotID)
+	}
+	if isForeignKeyViolation(err) {
+		return Lot{}, ErrAssetNotFound
 	}
-	return tag.RowsAffected() > 0, nil
+	return lot, err
 }
 
 func (d *DB) DeleteLot(ctx context.Context, userID string, lotID int64) error {
 	_, err := d.pool.Exec(ctx, `
-		delete from public.lots
-		where id = $1 and user_id = $2
+		update public.lots
+		set deleted_at = now()
+		where id = $1 and user_id = $2 and deleted_at is null
 	`, lotID, userID)
 	return err
 }
 
-func (d *DB) DeleteLotForUser(ctx context.Context, userID string, lotID int64) (bool, error) {
+// DeleteLotForUser soft-deletes a lot and returns the deleted row, with the
+// same expectedRevision semantics as UpdateLotForUser. The lot can be restored
+// until DeletedLotRetention has passed.
+func (d *DB) DeleteLotForUser(ctx context.Context, userID string, lotID int64, expectedRevision int64) (Lot, error) {
+	lot, err := scanLot(d.pool.QueryRow(ctx, `
+		update public.lots
+		set deleted_at = now()
+		where id = $1 and user_id = $2 and deleted_at is null
+		and ($3::bigint = 0 or revision = $3)
+		returning `+lotColumns, lotID, userID, expectedRevision))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return d.lotPreconditionFailure(ctx, userID, lotID)
+	}
+	return lot, err
+}
+
+func (d *DB) GetLotForUser(ctx context.Context, userID string, lotID int64) (Lot, error) {
+	lot, err := scanLot(d.pool.QueryRow(ctx, `
+		select `+lotColumns+`
+		from public.lots
+		where id = $1 and user_id = $2 and deleted_at is null
+	`, lotID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Lot{}, ErrLotNotFound
+	}
+	return lot, err
+}
+
+// RestoreLotForUser undoes a soft delete made less than window ago.
+func (d *DB) RestoreLotForUser(ctx context.Context, userID string, lotID int64, window time.Duration) (Lot, error) {
+	lot, err := scanLot(d.pool.QueryRow(ctx, `
+		update public.lots
+		set deleted_at = null
+		where id = $1 and user_id = $2
+		and deleted_at > now() - make_interval(secs => $3)
+		returning `+lotColumns, lotID, userID, window.Seconds()))
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return lot, err
+	}
+
+	var deletedAt sql.NullTime
+	err = d.pool.QueryRow(ctx, `
+		select deleted_at
+		from public.lots
+		where id = $1 and user_id = $2
+	`, lotID, userID).Scan(&deletedAt)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return Lot{}, ErrLotNotFound
+	case err != nil:
+		return Lot{}, err
+	case !deletedAt.Valid:
+		return Lot{}, ErrLotNotDeleted
+	default:
+		return Lot{}, ErrLotRestoreExpired
+	}
+}
+
+// PurgeDeletedLots permanently removes lots soft-deleted before cutoff. Their
+// lot_events history is kept.
+func (d *DB) PurgeDeletedLots(ctx context.Context, cutoff time.Time) (int64, error) {
 	tag, err := d.pool.Exec(ctx, `
 		delete from public.lots
-		where id = $1 and user_id = $2
-	`, lotID, userID)
+		where deleted_at < $1
+	`, cutoff)
 	if err != nil {
-		return false, err
+		return 0, err
 	}
-	return tag.RowsAffected() > 0, nil
+	return tag.RowsAffected(), nil
+}
+
+// ListLotEventsForUser returns a lot's change history, oldest first. It
+// includes deleted and purged lots.
+func (d *DB) ListLotEventsForUser(ctx context.Context, userID string, lotID int64) ([]LotEvent, error) {
+	rows, err := d.pool.Query(ctx, `
+		select id, lot_id, user_id, event_type, actor, before, after, created_at
+		from public.lot_events
+		where user_id = $1 and lot_id = $2
+		order by id
+	`, userID, lotID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []LotEvent
+	for rows.Next() {
+		var event LotEvent
+		if err := rows.Scan(&event.ID, &event.LotID, &event.UserID, &event.Type, &event.Actor, &event.Before, &event.After, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// lotPreconditionFailure explains a conditional write that matched no row:
+// either the lot does not exist or its revision has moved on.
+func (d *DB) lotPreconditionFailure(ctx context.Context, userID string, lotID int64) (Lot, error) {
+	current, err := d.GetLotForUser(ctx, userID, lotID)
+	if err != nil {
+		return Lot{}, err
+	}
+	return current, ErrLotRevisionMismatch
+}
+
+func scanLot(row pgx.Row) (Lot, error) {
+	var lot Lot
+	err := row.Scan(&lot.ID, &lot.UserID, &lot.AssetID, &lot.Quantity, &lot.UnitCost, &lot.PurchasedAt, &lot.CreatedAt, &lot.UpdatedAt, &lot.Revision, &lot.Location)
+	return lot, err
 }
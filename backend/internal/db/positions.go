@@ -4,7 +4,8 @@ import "context"
 
 func (d *DB) FetchPositionsForUser(ctx context.Context, userID string) ([]Position, error) {
 	rows, err := d.pool.Query(ctx, `
-		select user_id, asset_id, total_qty, avg_cost, current_price, unrealized_pl
+		select user_id, asset_id, total_qty, avg_cost, current_price, unrealized_pl, income_received,
+			change_24h_pct, volume_24h, market_cap, price_as_of
 		from public.positions_view
 		where user_id = $1
 	`, userID)
@@ -16,7 +17,8 @@ func (d *DB) FetchPositionsForUser(ctx context.Context, userID string) ([]Positi
 	var positions []Position
 	for rows.Next() {
 		var pos Position
-		if err := rows.Scan(&pos.UserID, &pos.AssetID, &pos.TotalQty, &pos.AvgCost, &pos.CurrentPrice, &pos.UnrealizedPL); err != nil {
+		if err := rows.Scan(&pos.UserID, &pos.AssetID, &pos.TotalQty, &pos.AvgCost, &pos.CurrentPrice, &pos.UnrealizedPL, &pos.IncomeReceived,
+			&pos.Change24hPct, &pos.Volume24h, &pos.MarketCap, &pos.PriceAsOf); err != nil {
 			return nil, err
 		}
 		positions = append(positions, pos)
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/jackc/pgx/v5"
 )
@@ -14,11 +15,13 @@ func (d *DB) UpsertCurrentPrices(ctx context.Context, updates []PriceUpdate) err
 	batch := &pgx.Batch{}
 	for _, update := range updates {
 		batch.Queue(`
-			insert into public.prices_current (asset_id, price, fetched_at, provider)
-			values ($1, $2, $3, $4)
+			insert into public.prices_current (asset_id, price, fetched_at, provider, change_24h_pct, volume_24h, market_cap, provider_as_of)
+			values ($1, $2, $3, $4, $5, $6, $7, $8)
 			on conflict (asset_id)
-			do update set price = excluded.price, fetched_at = excluded.fetched_at, provider = excluded.provider
-		`, update.AssetID, update.Price, update.FetchedAt, update.Provider)
+			do update set price = excluded.price, fetched_at = excluded.fetched_at, provider = excluded.provider,
+				change_24h_pct = excluded.change_24h_pct, volume_24h = excluded.volume_24h,
+				market_cap = excluded.market_cap, provider_as_of = excluded.provider_as_of
+		`, update.AssetID, update.Price, update.FetchedAt, update.Provider, update.Change24hPct, update.Volume24h, update.MarketCap, update.ProviderAsOf)
 	}
 	br := d.pool.SendBatch(ctx, batch)
 	defer br.Close()
@@ -39,9 +42,9 @@ func (d *DB) InsertPriceSnapshots(ctx context.Context, updates []PriceUpdate) er
 	batch := &pgx.Batch{}
 	for _, update := range updates {
 		batch.Queue(`
-			insert into public.price_snapshots (asset_id, price, fetched_at, provider)
-			values ($1, $2, $3, $4)
-		`, update.AssetID, update.Price, update.FetchedAt, update.Provider)
+			insert into public.price_snapshots (asset_id, price, fetched_at, provider, change_24h_pct, volume_24h, market_cap, provider_as_of)
+			values ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, update.AssetID, update.Price, update.FetchedAt, update.Provider, update.Change24hPct, update.Volume24h, update.MarketCap, update.ProviderAsOf)
 	}
 	br := d.pool.SendBatch(ctx, batch)
 	defer br.Close()
@@ -53,3 +56,58 @@ func (d *DB) InsertPriceSnapshots(ctx context.Context, updates []PriceUpdate) er
 	}
 	return nil
 }
+
+func (d *DB) RecordPriceDisagreements(ctx context.Context, disagreements []PriceDisagreement) error {
+	if len(disagreements) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, disagreement := range disagreements {
+		quotes, err := json.Marshal(disagreement.Quotes)
+		if err != nil {
+			return err
+		}
+		batch.Queue(`
+			insert into public.price_disagreements (asset_id, median, tolerance, quotes, rejected, detected_at)
+			values ($1, $2, $3, $4, $5, $6)
+		`, disagreement.AssetID, disagreement.Median, disagreement.Tolerance, quotes, disagreement.Rejected, disagreement.DetectedAt)
+	}
+	br := d.pool.SendBatch(ctx, batch)
+	defer br.Close()
+
+	for range disagreements {
+		if _, err := br.Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListCurrentPrices returns the latest price of each asset that has one.
+func (d *DB) ListCurrentPrices(ctx context.Context, assetIDs []int64) (map[int64]float64, error) {
+	prices := make(map[int64]float64, len(assetIDs))
+	if len(assetIDs) == 0 {
+		return prices, nil
+	}
+
+	rows, err := d.pool.Query(ctx, `
+		select asset_id, price
+		from public.prices_current
+		where asset_id = any($1::bigint[])
+	`, assetIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var assetID int64
+		var price float64
+		if err := rows.Scan(&assetID, &price); err != nil {
+			return nil, err
+		}
+		prices[assetID] = price
+	}
+	return prices, rows.Err()
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFetchTrackedAssetsIncludesEnabledAlerts(t *testing.T) {
+	database := mustOpenIntegrationDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	userID := randomUUID(t)
+	mustInsertAuthUser(t, ctx, database, userID, "tracked-alerts@example.com")
+	defer cleanupAuthUser(t, context.Background(), database, userID)
+
+	alertedAssetID := mustInsertStockAsset(t, ctx, database, "TRKA", "Tracked Alert")
+	disabledAssetID := mustInsertStockAsset(t, ctx, database, "TRKD", "Tracked Disabled Alert")
+	defer cleanupAsset(t, context.Background(), database, alertedAssetID)
+	defer cleanupAsset(t, context.Background(), database, disabledAssetID)
+
+	for assetID, enabled := range map[int64]bool{alertedAssetID: true, disabledAssetID: false} {
+		if _, err := database.InsertAlert(ctx, Alert{
+			UserID:        userID,
+			AssetID:       assetID,
+			Kind:          AlertKindPriceAbove,
+			Threshold:     100,
+			CooldownSec:   3600,
+			HysteresisPct: 0.01,
+			Enabled:       enabled,
+		}); err != nil {
+			t.Fatalf("InsertAlert failed: %v", err)
+		}
+	}
+
+	tracked, err := database.FetchTrackedAssets(ctx)
+	if err != nil {
+		t.Fatalf("FetchTrackedAssets failed: %v", err)
+	}
+	found := make(map[int64]bool, len(tracked))
+	for _, asset := range tracked {
+		found[asset.ID] = true
+	}
+	if !found[alertedAssetID] {
+		t.Fatalf("expected asset %d with an enabled alert to be tracked", alertedAssetID)
+	}
+	if found[disabledAssetID] {
+		t.Fatalf("expected asset %d with only a disabled alert to be untracked", disabledAssetID)
+	}
+}
+
+func TestInsertAlertUnknownAsset(t *testing.T) {
+	database := mustOpenIntegrationDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	userID := randomUUID(t)
+	mustInsertAuthUser(t, ctx, database, userID, "alert-unknown-asset@example.com")
+	defer cleanupAuthUser(t, context.Background(), database, userID)
+
+	_, err := database.InsertAlert(ctx, Alert{
+		UserID:        userID,
+		AssetID:       -1,
+		Kind:          AlertKindPriceAbove,
+		Threshold:     100,
+		CooldownSec:   3600,
+		HysteresisPct: 0.01,
+		Enabled:       true,
+	})
+	if err != ErrAssetNotFound {
+		t.Fatalf("expected ErrAssetNotFound, got %v", err)
+	}
+}
+
+func TestInsertAlertUnknownUserIsNotAssetNotFound(t *testing.T) {
+	database := mustOpenIntegrationDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	assetID := mustInsertStockAsset(t, ctx, database, "ALRU", "Alert Unknown User")
+	defer cleanupAsset(t, context.Background(), database, assetID)
+
+	_, err := database.InsertAlert(ctx, Alert{
+		UserID:        randomUUID(t),
+		AssetID:       assetID,
+		Kind:          AlertKindPriceAbove,
+		Threshold:     100,
+		CooldownSec:   3600,
+		HysteresisPct: 0.01,
+		Enabled:       true,
+	})
+	if err == nil || err == ErrAssetNotFound {
+		t.Fatalf("expected the raw foreign key error for an unknown user, got %v", err)
+	}
+}
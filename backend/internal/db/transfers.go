@@ -0,0 +1,292 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrInsufficientQuantity = errors.New("not enough quantity to transfer")
+	ErrTransferSameLocation = errors.New("lot is already at the destination")
+	ErrTransferFeeTooLarge  = errors.New("fee must be less than the transferred quantity")
+)
+
+// transferQuantityTolerance absorbs float rounding against the numeric(30, 10)
+// lot quantities, so taking what is left of a lot moves the whole lot.
+const transferQuantityTolerance = 1e-10
+
+const transferColumns = "id, user_id, asset_id, from_location, to_location, quantity, fee_quantity, transferred_at, notes, created_at"
+
+type transferSource struct {
+	id       int64
+	quantity float64
+	location sql.NullString
+}
+
+// TransferLots moves quantity of transfer.AssetID to transfer.ToLocation in
+// one transaction. Without legs, lots at FromLocation (unlabelled lots when it
+// is not set) are drawn oldest first until Quantity is covered; otherwise each
+// leg names a source lot and the quantity to take from it, zero meaning the
+// whole lot. A whole lot is relabelled in place; a partial one is reduced and
+// the moved part becomes a new lot. The fee is taken from every leg in
+// proportion, so the destination receives Quantity minus FeeQuantity. Moved
+// lots keep their purchased_at and cost basis: the unit_cost of what arrives
+// is raised so the fee's share of cost stays on the received quantity. Lot
+// changes are attributed to the transfer in lot_events.
+func (d *DB) TransferLots(ctx context.Context, transfer Transfer) (Transfer, error) {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return Transfer{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var legs []TransferLeg
+	var sources map[int64]transferSource
+	if len(transfer.Legs) == 0 {
+		legs, sources, err = planFIFOTransfer(ctx, tx, transfer)
+	} else {
+		legs, sources, err = planSpecificTransfer(ctx, tx, transfer)
+	}
+	if err != nil {
+		return Transfer{}, err
+	}
+
+	transfer.Quantity = 0
+	for _, leg := range legs {
+		if leg.FromLocation.Valid && leg.FromLocation.String == transfer.ToLocation {
+			return Transfer{}, ErrTransferSameLocation
+		}
+		transfer.Quantity += leg.Quantity
+	}
+	if transfer.FeeQuantity >= transfer.Quantity {
+		return Transfer{}, ErrTransferFeeTooLarge
+	}
+
+	stored, err := scanTransfer(tx.QueryRow(ctx, `
+		insert into public.transfers (user_id, asset_id, from_location, to_location, quantity, fee_quantity, transferred_at, notes)
+		values ($1, $2, $3, $4, $5, $6, $7, $8)
+		returning `+transferColumns,
+		transfer.UserID, transfer.AssetID, transfer.FromLocation, transfer.ToLocation, transfer.Quantity, transfer.FeeQuantity, transfer.TransferredAt, transfer.Notes))
+	if isForeignKeyViolation(err) {
+		return Transfer{}, ErrAssetNotFound
+	}
+	if err != nil {
+		return Transfer{}, err
+	}
+
+	// Attributes the lot_events rows written by the lots trigger to this transfer.
+	if _, err := tx.Exec(ctx, `select set_config('app.actor', $1, true)`, "transfer:"+strconv.FormatInt(stored.ID, 10)); err != nil {
+		return Transfer{}, err
+	}
+
+	received := (transfer.Quantity - transfer.FeeQuantity) / transfer.Quantity
+	for i := range legs {
+		leg := &legs[i]
+		leg.ReceivedQuantity = leg.Quantity * received
+		if sources[leg.SourceLotID].quantity-leg.Quantity <= transferQuantityTolerance {
+			leg.DestinationLotID = leg.SourceLotID
+			err = tx.QueryRow(ctx, `
+				update public.lots
+				set location = $2, quantity = $3, unit_cost = unit_cost * $4 / $3
+				where id = $1
+				returning unit_cost, purchased_at
+			`, leg.SourceLotID, transfer.ToLocation, leg.ReceivedQuantity, leg.Quantity).Scan(&leg.UnitCost, &leg.PurchasedAt)
+		} else {
+			err = tx.QueryRow(ctx, `
+				update public.lots
+				set quantity = quantity - $2
+				where id = $1
+				returning quantity
+			`, leg.SourceLotID, leg.Quantity).Scan(&leg.SourceQuantity)
+			if err == nil {
+				err = tx.QueryRow(ctx, `
+					insert into public.lots (user_id, asset_id, quantity, unit_cost, purchased_at, location)
+					select user_id, asset_id, $2::numeric, unit_cost * $4::numeric / $2::numeric, purchased_at, $3
+					from public.lots
+					where id = $1
+					returning id, unit_cost, purchased_at
+				`, leg.SourceLotID, leg.ReceivedQuantity, transfer.ToLocation, leg.Quantity).Scan(&leg.DestinationLotID, &leg.UnitCost, &leg.PurchasedAt)
+			}
+		}
+		if err != nil {
+			return Transfer{}, err
+		}
+
+		if _, err := tx.Exec(ctx, `
+			insert into public.transfer_legs (transfer_id, user_id, source_lot_id, destination_lot_id, from_location, quantity, received_quantity)
+			values ($1, $2, $3, $4, $5, $6, $7)
+		`, stored.ID, transfer.UserID, leg.SourceLotID, leg.DestinationLotID, leg.FromLocation, leg.Quantity, leg.ReceivedQuantity); err != nil {
+			return Transfer{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Transfer{}, err
+	}
+	stored.Legs = legs
+	return stored, nil
+}
+
+func planFIFOTransfer(ctx context.Context, tx pgx.Tx, transfer Transfer) ([]TransferLeg, map[int64]transferSource, error) {
+	rows, err := tx.Query(ctx, `
+		select id, quantity, location
+		from public.lots
+		where user_id = $1 and asset_id = $2 and deleted_at is null
+		and location is not distinct from $3
+		order by purchased_at, id
+		for update
+	`, transfer.UserID, transfer.AssetID, transfer.FromLocation)
+	if err != nil {
+		return nil, nil, err
+	}
+	sources, err := collectTransferSources(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var legs []TransferLeg
+	remaining := transfer.Quantity
+	for _, source := range sources {
+		if remaining <= transferQuantityTolerance {
+			break
+		}
+		take := min(remaining, source.quantity)
+		legs = append(legs, TransferLeg{SourceLotID: source.id, FromLocation: source.location, Quantity: take})
+		remaining -= take
+	}
+	if remaining > transferQuantityTolerance {
+		return nil, nil, ErrInsufficientQuantity
+	}
+	return legs, transferSourceMap(sources), nil
+}
+
+func planSpecificTransfer(ctx context.Context, tx pgx.Tx, transfer Transfer) ([]TransferLeg, map[int64]transferSource, error) {
+	lotIDs := make([]int64, 0, len(transfer.Legs))
+	for _, leg := range transfer.Legs {
+		lotIDs = append(lotIDs, leg.SourceLotID)
+	}
+	rows, err := tx.Query(ctx, `
+		select id, quantity, location
+		from public.lots
+		where id = any($3::bigint[]) and user_id = $1 and asset_id = $2 and deleted_at is null
+		order by id
+		for update
+	`, transfer.UserID, transfer.AssetID, lotIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	found, err := collectTransferSources(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+	sources := transferSourceMap(found)
+
+	legs := make([]TransferLeg, 0, len(transfer.Legs))
+	for _, leg := range transfer.Legs {
+		source, ok := sources[leg.SourceLotID]
+		if !ok {
+			return nil, nil, ErrLotNotFound
+		}
+		quantity := leg.Quantity
+		if quantity == 0 {
+			quantity = source.quantity
+		}
+		if quantity-source.quantity > transferQuantityTolerance {
+			return nil, nil, ErrInsufficientQuantity
+		}
+		legs = append(legs, TransferLeg{SourceLotID: source.id, FromLocation: source.location, Quantity: min(quantity, source.quantity)})
+	}
+	return legs, sources, nil
+}
+
+func collectTransferSources(rows pgx.Rows) ([]transferSource, error) {
+	defer rows.Close()
+
+	var sources []transferSource
+	for rows.Next() {
+		var source transferSource
+		if err := rows.Scan(&source.id, &source.quantity, &source.location); err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, rows.Err()
+}
+
+func transferSourceMap(sources []transferSource) map[int64]transferSource {
+	byID := make(map[int64]transferSource, len(sources))
+	for _, source := range sources {
+		byID[source.id] = source
+	}
+	return byID
+}
+
+// ListTransfersForUser returns the most recent transfers first, with their
+// legs.
+func (d *DB) ListTransfersForUser(ctx context.Context, userID string, limit int) ([]Transfer, error) {
+	rows, err := d.pool.Query(ctx, `
+		select `+transferColumns+`
+		from public.transfers
+		where user_id = $1
+		order by transferred_at desc, id desc
+		limit $2
+	`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transfers []Transfer
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, transfer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(transfers) == 0 {
+		return transfers, nil
+	}
+
+	transferIDs := make([]int64, 0, len(transfers))
+	index := make(map[int64]int, len(transfers))
+	for i, transfer := range transfers {
+		transferIDs = append(transferIDs, transfer.ID)
+		index[transfer.ID] = i
+	}
+
+	legRows, err := d.pool.Query(ctx, `
+		select transfer_id, source_lot_id, destination_lot_id, from_location, quantity, received_quantity
+		from public.transfer_legs
+		where transfer_id = any($1::bigint[])
+		order by id
+	`, transferIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer legRows.Close()
+
+	for legRows.Next() {
+		var transferID int64
+		var leg TransferLeg
+		if err := legRows.Scan(&transferID, &leg.SourceLotID, &leg.DestinationLotID, &leg.FromLocation, &leg.Quantity, &leg.ReceivedQuantity); err != nil {
+			return nil, err
+		}
+		i := index[transferID]
+		transfers[i].Legs = append(transfers[i].Legs, leg)
+	}
+	return transfers, legRows.Err()
+}
+
+func scanTransfer(row pgx.Row) (Transfer, error) {
+	var transfer Transfer
+	err := row.Scan(&transfer.ID, &transfer.UserID, &transfer.AssetID, &transfer.FromLocation, &transfer.ToLocation, &transfer.Quantity, &transfer.FeeQuantity, &transfer.TransferredAt, &transfer.Notes, &transfer.CreatedAt)
+	return transfer, err
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTransferLotsFeeKeepsCostBasis(t *testing.T) {
+	database := mustOpenIntegrationDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	userID := randomUUID(t)
+	mustInsertAuthUser(t, ctx, database, userID, "transfer-fee@example.com")
+	defer cleanupAuthUser(t, context.Background(), database, userID)
+
+	assetID := mustInsertStockAsset(t, ctx, database, "TFEE", "Transfer Fee Corp")
+	defer cleanupAsset(t, context.Background(), database, assetID)
+	mustInsertLot(t, ctx, database, userID, assetID, 10, 100)
+	mustInsertLot(t, ctx, database, userID, assetID, 5, 200)
+
+	costBasis := func() float64 {
+		t.Helper()
+		var total float64
+		if err := database.pool.QueryRow(ctx, `
+			select coalesce(sum(quantity * unit_cost), 0)::float8
+			from public.lots
+			where user_id = $1 and asset_id = $2 and deleted_at is null
+		`, userID, assetID).Scan(&total); err != nil {
+			t.Fatalf("failed to read cost basis: %v", err)
+		}
+		return total
+	}
+	before := costBasis()
+
+	stored, err := database.TransferLots(ctx, Transfer{
+		UserID:        userID,
+		AssetID:       assetID,
+		ToLocation:    "Ledger",
+		Quantity:      12,
+		FeeQuantity:   0.6,
+		TransferredAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("TransferLots failed: %v", err)
+	}
+	if len(stored.Legs) != 2 || stored.Legs[0].DestinationLotID != stored.Legs[0].SourceLotID || stored.Legs[1].SourceQuantity != 3 {
+		t.Fatalf("unexpected legs: %+v", stored.Legs)
+	}
+
+	if after := costBasis(); math.Abs(after-before) > 1e-6 {
+		t.Fatalf("cost basis changed: before %.10f after %.10f", before, after)
+	}
+}
@@ -43,6 +43,73 @@ type Lot struct {
 	PurchasedAt time.Time
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	Revision    int64
+	// Location is the custodian label, such as an exchange or wallet name.
+	Location sql.NullString
+}
+
+const (
+	LotEventCreated  = "created"
+	LotEventUpdated  = "updated"
+	LotEventDeleted  = "deleted"
+	LotEventRestored = "restored"
+)
+
+// LotEvent is one entry of a lot's audit trail. Before and After are JSON
+// snapshots of the row; Before is nil for creates. Actor is the acting user id
+// or the name of a system job.
+type LotEvent struct {
+	ID        int64
+	LotID     int64
+	UserID    string
+	Type      string
+	Actor     string
+	Before    []byte
+	After     []byte
+	CreatedAt time.Time
+}
+
+// LotPatch leaves a column unchanged when its field is nil, so columns the
+// caller does not know about are never overwritten. An empty Location clears
+// the label.
+type LotPatch struct {
+	AssetID     *int64
+	Quantity    *float64
+	UnitCost    *float64
+	PurchasedAt *time.Time
+	Location    *string
+}
+
+type Watchlist struct {
+	ID        int64
+	UserID    string
+	Name      string
+	AssetIDs  []int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WatchlistPatch leaves a field unchanged when it is nil. A non-nil AssetIDs
+// replaces the list's assets.
+type WatchlistPatch struct {
+	Name     *string
+	AssetIDs []int64
+}
+
+// AllocationTarget is a target portfolio weight for either one asset
+// (AssetID) or every asset of a type (AssetType); the other field is zero.
+type AllocationTarget struct {
+	UserID    string
+	AssetID   int64
+	AssetType AssetType
+	Weight    float64
+}
+
+// PricePoint is the last known price of an asset at At.
+type PricePoint struct {
+	AssetID int64
+	At      time.Time
+	Price   sql.NullFloat64
 }
 
 type TrackedAsset struct {
@@ -55,11 +122,52 @@ type TrackedAsset struct {
 	MinUserRefreshSec int
 }
 
+// PriceUpdate is one quote to store. The market data fields are set when the
+// provider reported them; ProviderAsOf is the provider's last-updated time.
 type PriceUpdate struct {
-	AssetID   int64
-	Price     float64
-	FetchedAt time.Time
-	Provider  string
+	AssetID      int64
+	Price        float64
+	FetchedAt    time.Time
+	Provider     string
+	Change24hPct sql.NullFloat64
+	Volume24h    sql.NullFloat64
+	MarketCap    sql.NullFloat64
+	ProviderAsOf sql.NullTime
+}
+
+// PriceDisagreement records quorum quotes for an asset that strayed from
+// their median by more than Tolerance. Rejected means no price was written.
+type PriceDisagreement struct {
+	AssetID    int64
+	Median     float64
+	Tolerance  float64
+	Quotes     []ProviderPrice
+	Rejected   bool
+	DetectedAt time.Time
+}
+
+type ProviderPrice struct {
+	Provider string  `json:"provider"`
+	Price    float64 `json:"price"`
+	Outlier  bool    `json:"outlier"`
+}
+
+// BackfillCandidate is a held asset whose daily prices may not reach back to
+// its earliest lot. CoveredFrom is the backfill's progress, FirstSnapshotAt the
+// worker's first live snapshot of the asset.
+type BackfillCandidate struct {
+	TrackedAsset
+	EarliestPurchase time.Time
+	CoveredFrom      sql.NullTime
+	FirstSnapshotAt  sql.NullTime
+}
+
+// BackfillProgress is saved after each backfill request. LastError is empty
+// when the request succeeded.
+type BackfillProgress struct {
+	AssetID     int64
+	CoveredFrom sql.NullTime
+	LastError   string
 }
 
 type Position struct {
@@ -69,6 +177,12 @@ type Position struct {
 	AvgCost      float64
 	CurrentPrice sql.NullFloat64
 	UnrealizedPL sql.NullFloat64
+	// IncomeReceived is the USD income recorded for the asset.
+	IncomeReceived float64
+	Change24hPct   sql.NullFloat64
+	Volume24h      sql.NullFloat64
+	MarketCap      sql.NullFloat64
+	PriceAsOf      sql.NullTime
 }
 
 type LotPerformance struct {
@@ -81,3 +195,293 @@ type LotPerformance struct {
 	CurrentPrice sql.NullFloat64
 	UnrealizedPL sql.NullFloat64
 }
+
+type AlertKind string
+
+const (
+	AlertKindPriceAbove       AlertKind = "price_above"
+	AlertKindPriceBelow       AlertKind = "price_below"
+	AlertKindPositionDrawdown AlertKind = "position_drawdown"
+	AlertKindChange24h        AlertKind = "change_24h"
+)
+
+type Alert struct {
+	ID              int64
+	UserID          string
+	AssetID         int64
+	Kind            AlertKind
+	Threshold       float64
+	CooldownSec     int
+	HysteresisPct   float64
+	Enabled         bool
+	Armed           bool
+	LastTriggeredAt sql.NullTime
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+type AlertPatch struct {
+	Threshold     *float64
+	CooldownSec   *int
+	HysteresisPct *float64
+	Enabled       *bool
+}
+
+// AlertCandidate is an enabled alert joined with the context needed to
+// evaluate it: the owner's average cost and the price roughly 24h ago.
+type AlertCandidate struct {
+	Alert
+	AvgCost     sql.NullFloat64
+	Price24hAgo sql.NullFloat64
+}
+
+type AlertEvent struct {
+	ID            int64
+	AlertID       int64
+	UserID        string
+	AssetID       int64
+	Kind          AlertKind
+	Threshold     float64
+	ObservedValue float64
+	Price         float64
+	TriggeredAt   time.Time
+}
+
+const (
+	WebhookEventLotCreated     = "lot.created"
+	WebhookEventLotUpdated     = "lot.updated"
+	WebhookEventLotDeleted     = "lot.deleted"
+	WebhookEventLotRestored    = "lot.restored"
+	WebhookEventAlertTriggered = "alert.triggered"
+	WebhookEventDailySummary   = "portfolio.daily_summary"
+	WebhookEventTest           = "webhook.test"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+type Webhook struct {
+	ID        int64
+	UserID    string
+	URL       string
+	Secret    string
+	Events    []string
+	Enabled   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WebhookPatch leaves a field unchanged when it is nil.
+type WebhookPatch struct {
+	URL     *string
+	Events  []string
+	Enabled *bool
+}
+
+type WebhookDelivery struct {
+	ID             int64
+	WebhookID      int64
+	UserID         string
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode sql.NullInt32
+	LastError      sql.NullString
+	CreatedAt      time.Time
+	DeliveredAt    sql.NullTime
+}
+
+// WebhookJob is a claimed delivery together with the endpoint it targets.
+type WebhookJob struct {
+	WebhookDelivery
+	URL    string
+	Secret string
+}
+
+// WebhookAttempt is the outcome of one delivery attempt. Status stays pending
+// while retries remain, with NextAttemptAt set to the backoff deadline.
+type WebhookAttempt struct {
+	DeliveryID    int64
+	Status        string
+	StatusCode    int
+	Error         string
+	AttemptedAt   time.Time
+	NextAttemptAt time.Time
+}
+
+type WebhookTarget struct {
+	WebhookID int64
+	UserID    string
+}
+
+// IdempotencyKey is a stored API response keyed by user and client-supplied
+// key. StatusCode is null while the original request is still in flight.
+type IdempotencyKey struct {
+	UserID              string
+	Key                 string
+	Method              string
+	Path                string
+	RequestHash         string
+	StatusCode          sql.NullInt32
+	ResponseBody        []byte
+	ResponseContentType sql.NullString
+	ResponseHeaders     map[string]string
+	CreatedAt           time.Time
+	ExpiresAt           time.Time
+}
+
+type CorporateActionKind string
+
+const (
+	CorporateActionSplit          CorporateActionKind = "split"
+	CorporateActionReverseSplit   CorporateActionKind = "reverse_split"
+	CorporateActionTickerChange   CorporateActionKind = "ticker_change"
+	CorporateActionRedenomination CorporateActionKind = "redenomination"
+)
+
+// CorporateAction is a split, reverse split, redenomination or ticker change
+// of an asset. Ratio is new units per old unit; ticker changes set NewSymbol
+// instead. AppliedAt is set once the action has adjusted lots.
+type CorporateAction struct {
+	ID             int64
+	AssetID        int64
+	Kind           CorporateActionKind
+	Ratio          sql.NullFloat64
+	NewSymbol      sql.NullString
+	PreviousSymbol sql.NullString
+	EffectiveAt    time.Time
+	Notes          sql.NullString
+	CreatedBy      string
+	CreatedAt      time.Time
+	AppliedAt      sql.NullTime
+}
+
+// CorporateActionAdjustment is a lot rescaled by a corporate action, with its
+// new quantity and unit cost. Deleted lots can still be restored.
+type CorporateActionAdjustment struct {
+	LotID    int64
+	UserID   string
+	Quantity float64
+	UnitCost float64
+	Deleted  bool
+}
+
+type IncomeKind string
+
+const (
+	IncomeKindDividend      IncomeKind = "dividend"
+	IncomeKindStakingReward IncomeKind = "staking_reward"
+	IncomeKindInterest      IncomeKind = "interest"
+	IncomeKindAirdrop       IncomeKind = "airdrop"
+)
+
+// Income is a dividend, reward, interest payment or airdrop received for an
+// asset. Amount is in Currency. LotID is set when the income was reinvested.
+type Income struct {
+	ID         int64
+	UserID     string
+	AssetID    int64
+	Kind       IncomeKind
+	Amount     float64
+	Currency   string
+	ReceivedAt time.Time
+	LotID      sql.NullInt64
+	Notes      sql.NullString
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// IncomePatch leaves a field unchanged when it is nil.
+type IncomePatch struct {
+	Kind       *IncomeKind
+	Amount     *float64
+	Currency   *string
+	ReceivedAt *time.Time
+	Notes      *string
+}
+
+// Transfer moves quantity of an asset between locations without changing
+// cost basis. FromLocation is only set for FIFO transfers; FeeQuantity units
+// are lost in transit.
+type Transfer struct {
+	ID            int64
+	UserID        string
+	AssetID       int64
+	FromLocation  sql.NullString
+	ToLocation    string
+	Quantity      float64
+	FeeQuantity   float64
+	TransferredAt time.Time
+	Notes         sql.NullString
+	CreatedAt     time.Time
+	Legs          []TransferLeg
+}
+
+// TransferLeg is the part of a transfer taken from one source lot.
+// DestinationLotID equals SourceLotID when the whole lot moved. TransferLots
+// also fills in the destination lot's UnitCost and PurchasedAt, and the
+// SourceQuantity left on a partly moved source lot.
+type TransferLeg struct {
+	SourceLotID      int64
+	DestinationLotID int64
+	FromLocation     sql.NullString
+	Quantity         float64
+	ReceivedQuantity float64
+	SourceQuantity   float64
+	UnitCost         float64
+	PurchasedAt      time.Time
+}
+
+type LotOperationKind string
+
+const (
+	LotOperationCreate LotOperationKind = "create"
+	LotOperationUpdate LotOperationKind = "update"
+	LotOperationDelete LotOperationKind = "delete"
+)
+
+// LotOperation is one step of a lot batch. Creates insert Lot; updates apply
+// Patch to Lot.ID; deletes use only Lot.ID. A non-zero Lot.Revision makes an
+// update or delete conditional on the stored revision.
+type LotOperation struct {
+	Kind  LotOperationKind
+	Lot   Lot
+	Patch LotPatch
+}
+
+type LotOperationResult struct {
+	Kind  LotOperationKind
+	LotID int64
+}
+
+type LotSortField string
+
+const (
+	LotSortPurchasedAt LotSortField = "purchased_at"
+	LotSortCreatedAt   LotSortField = "created_at"
+)
+
+// LotQuery filters and pages a user's lots. Zero values leave a filter unset;
+// PurchasedTo is exclusive. After resumes a previous page in the same order.
+type LotQuery struct {
+	UserID        string
+	AssetID       int64
+	AssetType     AssetType
+	PurchasedFrom time.Time
+	PurchasedTo   time.Time
+	SortField     LotSortField
+	Descending    bool
+	After         *LotCursor
+	Limit         int
+}
+
+// LotCursor is the sort value and id of the last lot on a page.
+type LotCursor struct {
+	Value time.Time
+	ID    int64
+}
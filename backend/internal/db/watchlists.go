@@ -0,0 +1,214 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrWatchlistNotFound  = errors.New("watchlist not found")
+	ErrWatchlistNameTaken = errors.New("watchlist name already in use")
+	ErrWatchlistFull      = errors.New("watchlist is full")
+)
+
+// MaxWatchlistAssets caps the number of assets on one watchlist.
+const MaxWatchlistAssets = 200
+
+func (d *DB) ListWatchlistsByUser(ctx context.Context, userID string) ([]Watchlist, error) {
+	rows, err := d.pool.Query(ctx, `
+		select w.id, w.user_id, w.name,
+			coalesce(array_agg(wa.asset_id order by wa.asset_id) filter (where wa.asset_id is not null), '{}'),
+			w.created_at, w.updated_at
+		from public.watchlists w
+		left join public.watchlist_assets wa on wa.watchlist_id = w.id
+		where w.user_id = $1
+		group by w.id
+		order by w.name, w.id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var watchlists []Watchlist
+	for rows.Next() {
+		var watchlist Watchlist
+		if err := rows.Scan(&watchlist.ID, &watchlist.UserID, &watchlist.Name, &watchlist.AssetIDs, &watchlist.CreatedAt, &watchlist.UpdatedAt); err != nil {
+			return nil, err
+		}
+		watchlists = append(watchlists, watchlist)
+	}
+	return watchlists, rows.Err()
+}
+
+// InsertWatchlist creates a watchlist together with its assets. It returns
+// ErrWatchlistNameTaken when the user already has a list with that name and
+// ErrAssetNotFound when an asset id does not exist.
+func (d *DB) InsertWatchlist(ctx context.Context, watchlist Watchlist) (int64, error) {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var id int64
+	err = tx.QueryRow(ctx, `
+		insert into public.watchlists (user_id, name)
+		values ($1, $2)
+		returning id
+	`, watchlist.UserID, watchlist.Name).Scan(&id)
+	if isUniqueViolation(err) {
+		return 0, ErrWatchlistNameTaken
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := addWatchlistAssets(ctx, tx, id, watchlist.UserID, watchlist.AssetIDs); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// UpdateWatchlistForUser renames the list when patch.Name is set and replaces
+// its assets when patch.AssetIDs is non-nil.
+func (d *DB) UpdateWatchlistForUser(ctx context.Context, userID string, watchlistID int64, patch WatchlistPatch) error {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tag, err := tx.Exec(ctx, `
+		update public.watchlists
+		set name = coalesce($1, name)
+		where id = $2 and user_id = $3
+	`, patch.Name, watchlistID, userID)
+	if isUniqueViolation(err) {
+		return ErrWatchlistNameTaken
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWatchlistNotFound
+	}
+
+	if patch.AssetIDs != nil {
+		if _, err := tx.Exec(ctx, `
+			delete from public.watchlist_assets
+			where watchlist_id = $1 and asset_id <> all($2::bigint[])
+		`, watchlistID, patch.AssetIDs); err != nil {
+			return err
+		}
+		if err := addWatchlistAssets(ctx, tx, watchlistID, userID, patch.AssetIDs); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (d *DB) DeleteWatchlistForUser(ctx context.Context, userID string, watchlistID int64) (bool, error) {
+	tag, err := d.pool.Exec(ctx, `
+		delete from public.watchlists
+		where id = $1 and user_id = $2
+	`, watchlistID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// AddWatchlistAsset adds assetID to the list; adding an asset twice is a
+// no-op. It returns ErrWatchlistFull once the list holds MaxWatchlistAssets.
+func (d *DB) AddWatchlistAsset(ctx context.Context, userID string, watchlistID int64, assetID int64) error {
+	var found, present bool
+	err := d.pool.QueryRow(ctx, `
+		with target as (
+			select w.id, w.user_id,
+				(select count(*) from public.watchlist_assets wa where wa.watchlist_id = w.id) as size
+			from public.watchlists w
+			where w.id = $1 and w.user_id = $2
+		), inserted as (
+			insert into public.watchlist_assets (watchlist_id, asset_id, user_id)
+			select id, $3, user_id
+			from target
+			where size < $4
+			on conflict do nothing
+			returning asset_id
+		)
+		select exists (select 1 from target),
+			exists (select 1 from inserted)
+			or exists (select 1 from public.watchlist_assets where watchlist_id = $1 and asset_id = $3)
+	`, watchlistID, userID, assetID, MaxWatchlistAssets).Scan(&found, &present)
+	if isForeignKeyViolation(err) {
+		return ErrAssetNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrWatchlistNotFound
+	}
+	if !present {
+		return ErrWatchlistFull
+	}
+	return nil
+}
+
+func (d *DB) RemoveWatchlistAsset(ctx context.Context, userID string, watchlistID int64, assetID int64) (bool, error) {
+	tag, err := d.pool.Exec(ctx, `
+		delete from public.watchlist_assets
+		where watchlist_id = $1 and asset_id = $2 and user_id = $3
+	`, watchlistID, assetID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListWatchlistAssetIDs returns the asset ids of one of userID's watchlists.
+func (d *DB) ListWatchlistAssetIDs(ctx context.Context, userID string, watchlistID int64) ([]int64, error) {
+	var assetIDs []int64
+	err := d.pool.QueryRow(ctx, `
+		select array(
+			select asset_id
+			from public.watchlist_assets
+			where watchlist_id = w.id
+			order by asset_id
+		)
+		from public.watchlists w
+		where w.id = $1 and w.user_id = $2
+	`, watchlistID, userID).Scan(&assetIDs)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWatchlistNotFound
+	}
+	return assetIDs, err
+}
+
+func addWatchlistAssets(ctx context.Context, q execer, watchlistID int64, userID string, assetIDs []int64) error {
+	if len(assetIDs) == 0 {
+		return nil
+	}
+	_, err := q.Exec(ctx, `
+		insert into public.watchlist_assets (watchlist_id, asset_id, user_id)
+		select $1, asset_id, $2
+		from unnest($3::bigint[]) as asset_id
+		on conflict do nothing
+	`, watchlistID, userID, assetIDs)
+	if isForeignKeyViolation(err) {
+		return ErrAssetNotFound
+	}
+	return err
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,228 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+func (d *DB) ListWebhooksByUser(ctx context.Context, userID string) ([]Webhook, error) {
+	rows, err := d.pool.Query(ctx, `
+		select id, user_id, url, secret, events, enabled, created_at, updated_at
+		from public.webhooks
+		where user_id = $1
+		order by created_at desc, id desc
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []Webhook
+	for rows.Next() {
+		var webhook Webhook
+		if err := rows.Scan(&webhook.ID, &webhook.UserID, &webhook.URL, &webhook.Secret, &webhook.Events, &webhook.Enabled, &webhook.CreatedAt, &webhook.UpdatedAt); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+func (d *DB) InsertWebhook(ctx context.Context, webhook Webhook) (int64, error) {
+	row := d.pool.QueryRow(ctx, `
+		insert into public.webhooks (user_id, url, secret, events, enabled)
+		values ($1, $2, $3, $4, $5)
+		returning id
+	`, webhook.UserID, webhook.URL, webhook.Secret, webhook.Events, webhook.Enabled)
+
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (d *DB) UpdateWebhookForUser(ctx context.Context, userID string, webhookID int64, patch WebhookPatch) (bool, error) {
+	tag, err := d.pool.Exec(ctx, `
+		update public.webhooks
+		set url = coalesce($1, url),
+			events = coalesce($2, events),
+			enabled = coalesce($3, enabled)
+		where id = $4 and user_id = $5
+	`, patch.URL, patch.Events, patch.Enabled, webhookID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (d *DB) DeleteWebhookForUser(ctx context.Context, userID string, webhookID int64) (bool, error) {
+	tag, err := d.pool.Exec(ctx, `
+		delete from public.webhooks
+		where id = $1 and user_id = $2
+	`, webhookID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// EnqueueWebhookEvent queues a delivery of payload to every enabled webhook
+// of userID subscribed to eventType. The worker performs the HTTP calls.
+func (d *DB) EnqueueWebhookEvent(ctx context.Context, userID string, eventType string, payload any) error {
+	return enqueueWebhookEvent(ctx, d.pool, userID, eventType, payload)
+}
+
+func enqueueWebhookEvent(ctx context.Context, q execer, userID string, eventType string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = q.Exec(ctx, `
+		insert into public.webhook_deliveries (webhook_id, user_id, event_type, payload)
+		select id, user_id, $2, $3::jsonb
+		from public.webhooks
+		where user_id = $1
+		and enabled
+		and $2 = any(events)
+	`, userID, eventType, string(body))
+	return err
+}
+
+// EnqueueWebhookDeliveries queues payload for the given webhooks regardless
+// of their subscriptions; webhooks not owned by userID are ignored.
+func (d *DB) EnqueueWebhookDeliveries(ctx context.Context, webhookIDs []int64, userID string, eventType string, payload any) (int64, error) {
+	if len(webhookIDs) == 0 {
+		return 0, nil
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+	tag, err := d.pool.Exec(ctx, `
+		insert into public.webhook_deliveries (webhook_id, user_id, event_type, payload)
+		select id, user_id, $3, $4::jsonb
+		from public.webhooks
+		where id = any($1::bigint[])
+		and user_id = $2
+	`, webhookIDs, userID, eventType, string(body))
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (d *DB) ListWebhookDeliveriesForUser(ctx context.Context, userID string, webhookID int64, limit int) ([]WebhookDelivery, error) {
+	rows, err := d.pool.Query(ctx, `
+		select id, webhook_id, user_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, delivered_at
+		from public.webhook_deliveries
+		where webhook_id = $1 and user_id = $2
+		order by created_at desc, id desc
+		limit $3
+	`, webhookID, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []WebhookDelivery
+	for rows.Next() {
+		var delivery WebhookDelivery
+		if err := rows.Scan(&delivery.ID, &delivery.WebhookID, &delivery.UserID, &delivery.EventType, &delivery.Payload, &delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastStatusCode, &delivery.LastError, &delivery.CreatedAt, &delivery.DeliveredAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// ClaimWebhookDeliveries returns up to limit pending deliveries that are due
+// and pushes their next_attempt_at out by lease so concurrent workers skip
+// them while the attempt is in flight.
+func (d *DB) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]WebhookJob, error) {
+	rows, err := d.pool.Query(ctx, `
+		with due as (
+			select id
+			from public.webhook_deliveries
+			where status = 'pending'
+			and next_attempt_at <= $1
+			order by next_attempt_at
+			limit $3
+			for update skip locked
+		)
+		update public.webhook_deliveries d
+		set next_attempt_at = $1::timestamptz + make_interval(secs => $2)
+		from due, public.webhooks w
+		where d.id = due.id
+		and w.id = d.webhook_id
+		returning d.id, d.webhook_id, d.user_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at, d.created_at, w.url, w.secret
+	`, now, lease.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []WebhookJob
+	for rows.Next() {
+		var job WebhookJob
+		if err := rows.Scan(&job.ID, &job.WebhookID, &job.UserID, &job.EventType, &job.Payload, &job.Status, &job.Attempts, &job.NextAttemptAt, &job.CreatedAt, &job.URL, &job.Secret); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func (d *DB) RecordWebhookAttempt(ctx context.Context, attempt WebhookAttempt) error {
+	_, err := d.pool.Exec(ctx, `
+		update public.webhook_deliveries
+		set status = $2,
+			attempts = attempts + 1,
+			last_status_code = nullif($3, 0),
+			last_error = nullif($4, ''),
+			next_attempt_at = $5,
+			delivered_at = case when $2 = 'succeeded' then $6 else delivered_at end
+		where id = $1
+	`, attempt.DeliveryID, attempt.Status, attempt.StatusCode, attempt.Error, attempt.NextAttemptAt, attempt.AttemptedAt)
+	return err
+}
+
+// ListDailySummaryTargets returns enabled webhooks subscribed to the daily
+// summary that have not had one queued since dayStart.
+func (d *DB) ListDailySummaryTargets(ctx context.Context, dayStart time.Time) ([]WebhookTarget, error) {
+	rows, err := d.pool.Query(ctx, `
+		select w.id, w.user_id
+		from public.webhooks w
+		where w.enabled
+		and $1 = any(w.events)
+		and not exists (
+			select 1
+			from public.webhook_deliveries d
+			where d.webhook_id = w.id
+			and d.event_type = $1
+			and d.created_at >= $2
+		)
+		order by w.user_id, w.id
+	`, WebhookEventDailySummary, dayStart)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []WebhookTarget
+	for rows.Next() {
+		var target WebhookTarget
+		if err := rows.Scan(&target.WebhookID, &target.UserID); err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+	return targets, rows.Err()
+}
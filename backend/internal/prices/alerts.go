@@ -0,0 +1,118 @@
+package prices
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"asset-tracker/internal/db"
+)
+
+func (s *Service) evaluateAlerts(ctx context.Context, now time.Time, updates []db.PriceUpdate) (int, error) {
+	priceByAsset := make(map[int64]float64, len(updates))
+	assetIDs := make([]int64, 0, len(updates))
+	for _, update := range updates {
+		if _, ok := priceByAsset[update.AssetID]; !ok {
+			assetIDs = append(assetIDs, update.AssetID)
+		}
+		priceByAsset[update.AssetID] = update.Price
+	}
+
+	candidates, err := s.store.FetchAlertCandidates(ctx, assetIDs, now)
+	if err != nil {
+		return 0, err
+	}
+
+	fired, rearm := evaluateAlerts(now, candidates, priceByAsset)
+	if len(fired) == 0 && len(rearm) == 0 {
+		return 0, nil
+	}
+
+	recorded, err := s.store.RecordAlertEvaluations(ctx, fired, rearm)
+	if err != nil {
+		return 0, err
+	}
+	return len(recorded), nil
+}
+
+// evaluateAlerts returns the events to fire and the ids of disarmed alerts to
+// re-arm. An alert fires only while armed and outside its cooldown; once fired
+// it stays disarmed until the observed value retreats past the threshold by
+// hysteresis_pct, so a price hovering at the threshold does not flap.
+func evaluateAlerts(now time.Time, candidates []db.AlertCandidate, priceByAsset map[int64]float64) ([]db.AlertEvent, []int64) {
+	var fired []db.AlertEvent
+	var rearm []int64
+
+	for _, candidate := range candidates {
+		price, ok := priceByAsset[candidate.AssetID]
+		if !ok {
+			continue
+		}
+		observed, ok := observedAlertValue(candidate, price)
+		if !ok {
+			continue
+		}
+
+		if !candidate.Armed {
+			if alertCleared(candidate.Alert, observed) {
+				rearm = append(rearm, candidate.ID)
+			}
+			continue
+		}
+		if !alertConditionMet(candidate.Alert, observed) {
+			continue
+		}
+		if candidate.LastTriggeredAt.Valid && now.Before(candidate.LastTriggeredAt.Time.Add(time.Duration(candidate.CooldownSec)*time.Second)) {
+			continue
+		}
+
+		fired = append(fired, db.AlertEvent{
+			AlertID:       candidate.ID,
+			UserID:        candidate.UserID,
+			AssetID:       candidate.AssetID,
+			Kind:          candidate.Kind,
+			Threshold:     candidate.Threshold,
+			ObservedValue: observed,
+			Price:         price,
+			TriggeredAt:   now,
+		})
+	}
+
+	return fired, rearm
+}
+
+// observedAlertValue returns the value compared against the threshold: the
+// price for price alerts, the percentage loss from average cost for drawdown
+// alerts, and the absolute percentage move over 24h for change alerts.
+func observedAlertValue(candidate db.AlertCandidate, price float64) (float64, bool) {
+	switch candidate.Kind {
+	case db.AlertKindPriceAbove, db.AlertKindPriceBelow:
+		return price, true
+	case db.AlertKindPositionDrawdown:
+		if !candidate.AvgCost.Valid || candidate.AvgCost.Float64 <= 0 {
+			return 0, false
+		}
+		return (candidate.AvgCost.Float64 - price) / candidate.AvgCost.Float64 * 100, true
+	case db.AlertKindChange24h:
+		if !candidate.Price24hAgo.Valid || candidate.Price24hAgo.Float64 <= 0 {
+			return 0, false
+		}
+		return math.Abs(price-candidate.Price24hAgo.Float64) / candidate.Price24hAgo.Float64 * 100, true
+	default:
+		return 0, false
+	}
+}
+
+func alertConditionMet(alert db.Alert, observed float64) bool {
+	if alert.Kind == db.AlertKindPriceBelow {
+		return observed <= alert.Threshold
+	}
+	return observed >= alert.Threshold
+}
+
+func alertCleared(alert db.Alert, observed float64) bool {
+	if alert.Kind == db.AlertKindPriceBelow {
+		return observed > alert.Threshold*(1+alert.HysteresisPct)
+	}
+	return observed < alert.Threshold*(1-alert.HysteresisPct)
+}
@@ -0,0 +1,104 @@
+package prices
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"asset-tracker/internal/db"
+	"asset-tracker/internal/providers"
+)
+
+func TestEvaluateAlertsFiresAndRespectsCooldown(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	candidates := []db.AlertCandidate{
+		{Alert: db.Alert{ID: 1, UserID: "u1", AssetID: 10, Kind: db.AlertKindPriceAbove, Threshold: 100000, Armed: true}},
+		{Alert: db.Alert{ID: 2, UserID: "u1", AssetID: 10, Kind: db.AlertKindPriceBelow, Threshold: 90000, Armed: true}},
+		{Alert: db.Alert{
+			ID: 3, UserID: "u2", AssetID: 10, Kind: db.AlertKindPriceAbove, Threshold: 100000, Armed: true, CooldownSec: 3600,
+			LastTriggeredAt: sql.NullTime{Time: now.Add(-10 * time.Minute), Valid: true},
+		}},
+		{Alert: db.Alert{ID: 4, UserID: "u1", AssetID: 20, Kind: db.AlertKindPositionDrawdown, Threshold: 20, Armed: true}, AvgCost: sql.NullFloat64{Float64: 100, Valid: true}},
+		{Alert: db.Alert{ID: 5, UserID: "u1", AssetID: 20, Kind: db.AlertKindChange24h, Threshold: 5, Armed: true}, Price24hAgo: sql.NullFloat64{Float64: 90, Valid: true}},
+		{Alert: db.Alert{ID: 6, UserID: "u1", AssetID: 20, Kind: db.AlertKindChange24h, Threshold: 5, Armed: true}},
+		{Alert: db.Alert{ID: 7, UserID: "u1", AssetID: 30, Kind: db.AlertKindPriceAbove, Threshold: 1, Armed: true}},
+	}
+	prices := map[int64]float64{10: 100500, 20: 75}
+
+	fired, rearm := evaluateAlerts(now, candidates, prices)
+	if len(rearm) != 0 {
+		t.Fatalf("expected no re-arms, got %v", rearm)
+	}
+
+	got := map[int64]db.AlertEvent{}
+	for _, event := range fired {
+		got[event.AlertID] = event
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected alerts 1, 4 and 5 to fire, got %+v", fired)
+	}
+	if event := got[1]; event.Price != 100500 || event.ObservedValue != 100500 || !event.TriggeredAt.Equal(now) {
+		t.Fatalf("unexpected price alert event: %+v", event)
+	}
+	if event := got[4]; event.ObservedValue != 25 {
+		t.Fatalf("expected drawdown of 25%%, got %+v", event)
+	}
+	if event, ok := got[5]; !ok || event.ObservedValue < 16.66 || event.ObservedValue > 16.67 {
+		t.Fatalf("expected 24h change of ~16.67%%, got %+v", event)
+	}
+}
+
+func TestEvaluateAlertsHysteresis(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	disarmed := db.AlertCandidate{Alert: db.Alert{ID: 1, AssetID: 10, Kind: db.AlertKindPriceAbove, Threshold: 100, HysteresisPct: 0.02, Armed: false}}
+
+	fired, rearm := evaluateAlerts(now, []db.AlertCandidate{disarmed}, map[int64]float64{10: 99})
+	if len(fired) != 0 || len(rearm) != 0 {
+		t.Fatalf("expected no change inside hysteresis band, got fired=%v rearm=%v", fired, rearm)
+	}
+
+	fired, rearm = evaluateAlerts(now, []db.AlertCandidate{disarmed}, map[int64]float64{10: 97})
+	if len(fired) != 0 || len(rearm) != 1 || rearm[0] != 1 {
+		t.Fatalf("expected re-arm below hysteresis band, got fired=%v rearm=%v", fired, rearm)
+	}
+
+	disarmedBelow := db.AlertCandidate{Alert: db.Alert{ID: 2, AssetID: 10, Kind: db.AlertKindPriceBelow, Threshold: 100, HysteresisPct: 0.02, Armed: false}}
+	_, rearm = evaluateAlerts(now, []db.AlertCandidate{disarmedBelow}, map[int64]float64{10: 103})
+	if len(rearm) != 1 || rearm[0] != 2 {
+		t.Fatalf("expected price_below alert to re-arm above band, got %v", rearm)
+	}
+}
+
+func TestRefreshEvaluatesAlertsForWrittenUpdates(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{
+		settings: db.AppSettings{MinRefreshIntervalSec: 60, MaxRefreshIntervalSec: 3600},
+		tracked: []db.TrackedAsset{
+			{ID: 2, Type: db.AssetTypeCrypto, Symbol: "BTC", MarketDataID: "bitcoin", MinUserRefreshSec: 60},
+		},
+		alertCandidates: []db.AlertCandidate{
+			{Alert: db.Alert{ID: 9, UserID: "u1", AssetID: 2, Kind: db.AlertKindPriceAbove, Threshold: 100000, Armed: true}},
+		},
+	}
+	crypto := &mockQuoteProvider{
+		quotes: []providers.AssetQuote{{LookupKey: "bitcoin", Price: 101000, Provider: "crypto-test"}},
+	}
+
+	svc := NewService(store, &mockQuoteProvider{}, crypto)
+	if err := svc.Refresh(context.Background()); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if len(store.alertAssetIDs) != 1 || store.alertAssetIDs[0] != 2 {
+		t.Fatalf("expected alerts evaluated for asset 2, got %v", store.alertAssetIDs)
+	}
+	if len(store.firedAlerts) != 1 || store.firedAlerts[0].AlertID != 9 || store.firedAlerts[0].UserID != "u1" {
+		t.Fatalf("expected alert 9 to fire, got %+v", store.firedAlerts)
+	}
+}
@@ -0,0 +1,153 @@
+package prices
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"asset-tracker/internal/db"
+	"asset-tracker/internal/providers"
+)
+
+const (
+	// backfillChunk is the range of one history request. CoinGecko answers
+	// ranges over 90 days with daily points.
+	backfillChunk = 365 * 24 * time.Hour
+	// backfillRequestsPerRun caps the history requests of one run, leaving
+	// the provider budget to price refreshes.
+	backfillRequestsPerRun = 20
+	// backfillRetryAfter is how long an asset whose backfill failed waits
+	// before it is tried again.
+	backfillRetryAfter = 24 * time.Hour
+)
+
+type BackfillStore interface {
+	ListBackfillCandidates(ctx context.Context, retryBefore time.Time) ([]db.BackfillCandidate, error)
+	InsertBackfilledSnapshots(ctx context.Context, updates []db.PriceUpdate) (int64, error)
+	SaveBackfillProgress(ctx context.Context, progress db.BackfillProgress) error
+}
+
+// Backfiller fills price_snapshots with daily prices from an asset's earliest
+// lot up to the worker's first live snapshot. It works backwards one chunk at
+// a time and saves its progress after each, so an interrupted run resumes
+// where it stopped and a lot purchased earlier extends the backfill.
+type Backfiller struct {
+	store       BackfillStore
+	stock       providers.HistoryProvider
+	crypto      providers.HistoryProvider
+	maxRequests int
+	now         func() time.Time
+}
+
+// NewBackfiller takes the history providers of each asset type; either may be
+// nil when no configured provider has history.
+func NewBackfiller(store BackfillStore, stock, crypto providers.HistoryProvider) *Backfiller {
+	return &Backfiller{
+		store:       store,
+		stock:       stock,
+		crypto:      crypto,
+		maxRequests: backfillRequestsPerRun,
+		now:         time.Now,
+	}
+}
+
+// Run backfills candidates in asset order until the request cap and returns
+// how many snapshots it inserted. Asset errors are saved with their progress
+// and returned joined.
+func (b *Backfiller) Run(ctx context.Context) (int64, error) {
+	now := b.now().UTC()
+	candidates, err := b.store.ListBackfillCandidates(ctx, now.Add(-backfillRetryAfter))
+	if err != nil {
+		return 0, err
+	}
+
+	var inserted int64
+	var errs []error
+	requests := 0
+	for _, candidate := range candidates {
+		if requests >= b.maxRequests || ctx.Err() != nil {
+			break
+		}
+		count, used, err := b.backfill(ctx, candidate, now, b.maxRequests-requests)
+		inserted += count
+		requests += used
+		if err != nil {
+			errs = append(errs, fmt.Errorf("asset %d: %w", candidate.ID, err))
+		}
+	}
+	return inserted, errors.Join(errs...)
+}
+
+func (b *Backfiller) backfill(ctx context.Context, candidate db.BackfillCandidate, now time.Time, budget int) (int64, int, error) {
+	start := startOfDay(candidate.EarliestPurchase)
+	end := startOfDay(now)
+	if candidate.FirstSnapshotAt.Valid && candidate.FirstSnapshotAt.Time.Before(end) {
+		end = startOfDay(candidate.FirstSnapshotAt.Time)
+	}
+	covered := candidate.CoveredFrom
+	if covered.Valid && covered.Time.Before(end) {
+		end = covered.Time
+	}
+	if !end.After(start) {
+		// Live snapshots already reach back to the purchase.
+		return 0, 0, b.store.SaveBackfillProgress(ctx, db.BackfillProgress{AssetID: candidate.ID, CoveredFrom: sql.NullTime{Time: end, Valid: true}})
+	}
+
+	history := b.crypto
+	if candidate.Type == db.AssetTypeStock {
+		history = b.stock
+	}
+	key := lookupKeyForAsset(candidate.TrackedAsset)
+	if history == nil || key == "" {
+		err := providers.ErrHistoryUnsupported
+		return 0, 0, errors.Join(err, b.store.SaveBackfillProgress(ctx, db.BackfillProgress{AssetID: candidate.ID, CoveredFrom: covered, LastError: err.Error()}))
+	}
+
+	var inserted int64
+	requests := 0
+	for end.After(start) && requests < budget {
+		chunkStart := end.Add(-backfillChunk)
+		if chunkStart.Before(start) {
+			chunkStart = start
+		}
+		requests++
+		points, err := history.FetchHistory(ctx, key, chunkStart, end)
+		if err != nil {
+			if ctx.Err() != nil {
+				return inserted, requests, err
+			}
+			return inserted, requests, errors.Join(err, b.store.SaveBackfillProgress(ctx, db.BackfillProgress{AssetID: candidate.ID, CoveredFrom: covered, LastError: err.Error()}))
+		}
+
+		updates := make([]db.PriceUpdate, 0, len(points))
+		for _, price := range points {
+			if price.At.Before(chunkStart) || !price.At.Before(end) {
+				continue
+			}
+			updates = append(updates, db.PriceUpdate{AssetID: candidate.ID, Price: price.Price, FetchedAt: price.At, Provider: price.Provider})
+		}
+		count, err := b.store.InsertBackfilledSnapshots(ctx, updates)
+		inserted += count
+		if err != nil {
+			return inserted, requests, err
+		}
+
+		// Days the provider had no price for count as covered too: a coin
+		// listed after the purchase has nothing earlier to backfill.
+		covered = sql.NullTime{Time: chunkStart, Valid: true}
+		if err := b.store.SaveBackfillProgress(ctx, db.BackfillProgress{AssetID: candidate.ID, CoveredFrom: covered}); err != nil {
+			return inserted, requests, err
+		}
+		end = chunkStart
+	}
+
+	slog.Info("asset prices backfilled", "asset_id", candidate.ID, "snapshots", inserted, "covered_from", covered.Time, "done", !end.After(start))
+	return inserted, requests, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
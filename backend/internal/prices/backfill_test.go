@@ -0,0 +1,167 @@
+package prices
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"asset-tracker/internal/db"
+	"asset-tracker/internal/providers"
+)
+
+type mockBackfillStore struct {
+	candidates  []db.BackfillCandidate
+	retryBefore time.Time
+	inserted    []db.PriceUpdate
+	progress    []db.BackfillProgress
+}
+
+func (m *mockBackfillStore) ListBackfillCandidates(ctx context.Context, retryBefore time.Time) ([]db.BackfillCandidate, error) {
+	m.retryBefore = retryBefore
+	return m.candidates, nil
+}
+
+func (m *mockBackfillStore) InsertBackfilledSnapshots(ctx context.Context, updates []db.PriceUpdate) (int64, error) {
+	m.inserted = append(m.inserted, updates...)
+	return int64(len(updates)), nil
+}
+
+func (m *mockBackfillStore) SaveBackfillProgress(ctx context.Context, progress db.BackfillProgress) error {
+	m.progress = append(m.progress, progress)
+	return nil
+}
+
+type historyRequest struct {
+	key      string
+	from, to time.Time
+}
+
+// dailyHistory prices every day of the requested range at 1.
+type dailyHistory struct {
+	requests []historyRequest
+	err      error
+}
+
+func (h *dailyHistory) FetchHistory(ctx context.Context, lookupKey string, from, to time.Time) ([]providers.HistoricalPrice, error) {
+	h.requests = append(h.requests, historyRequest{key: lookupKey, from: from, to: to})
+	if h.err != nil {
+		return nil, h.err
+	}
+	var prices []providers.HistoricalPrice
+	for at := from; at.Before(to); at = at.AddDate(0, 0, 1) {
+		prices = append(prices, providers.HistoricalPrice{At: at, Price: 1, Provider: "coingecko"})
+	}
+	return prices, nil
+}
+
+func TestBackfillerWorksBackwardsAndResumes(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	purchase := time.Date(2025, 1, 10, 15, 30, 0, 0, time.UTC)
+	firstSnapshot := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	candidate := db.BackfillCandidate{
+		TrackedAsset:     db.TrackedAsset{ID: 7, Symbol: "BTC", MarketDataID: "bitcoin", Type: db.AssetTypeCrypto},
+		EarliestPurchase: purchase,
+		FirstSnapshotAt:  sql.NullTime{Time: firstSnapshot, Valid: true},
+	}
+	store := &mockBackfillStore{candidates: []db.BackfillCandidate{candidate}}
+	history := &dailyHistory{}
+	backfiller := NewBackfiller(store, nil, history)
+	backfiller.now = func() time.Time { return now }
+	backfiller.maxRequests = 1
+
+	inserted, err := backfiller.Run(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	firstChunk := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	if len(history.requests) != 1 || history.requests[0].key != "bitcoin" || !history.requests[0].from.Equal(firstChunk) || !history.requests[0].to.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected requests: %+v", history.requests)
+	}
+	if inserted != 365 || len(store.progress) != 1 || !store.progress[0].CoveredFrom.Time.Equal(firstChunk) || store.progress[0].LastError != "" {
+		t.Fatalf("unexpected first run: inserted=%d progress=%+v", inserted, store.progress)
+	}
+	if !store.retryBefore.Equal(now.Add(-backfillRetryAfter)) {
+		t.Fatalf("unexpected retryBefore: %v", store.retryBefore)
+	}
+
+	candidate.CoveredFrom = store.progress[0].CoveredFrom
+	store.candidates = []db.BackfillCandidate{candidate}
+	backfiller.maxRequests = backfillRequestsPerRun
+	if _, err := backfiller.Run(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	start := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	if len(history.requests) != 2 || !history.requests[1].from.Equal(start) || !history.requests[1].to.Equal(firstChunk) {
+		t.Fatalf("expected resume from saved progress, got %+v", history.requests)
+	}
+	if last := store.progress[len(store.progress)-1]; !last.CoveredFrom.Time.Equal(start) {
+		t.Fatalf("expected backfill to reach the purchase day, got %+v", last)
+	}
+	if first := store.inserted[365]; !first.FetchedAt.Equal(start) || first.Provider != "coingecko" || first.AssetID != 7 {
+		t.Fatalf("unexpected oldest snapshot: %+v", first)
+	}
+}
+
+func TestBackfillerSavesErrorAndKeepsProgress(t *testing.T) {
+	t.Parallel()
+
+	covered := sql.NullTime{Time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+	store := &mockBackfillStore{candidates: []db.BackfillCandidate{{
+		TrackedAsset:     db.TrackedAsset{ID: 3, Symbol: "AAPL", Type: db.AssetTypeStock},
+		EarliestPurchase: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		CoveredFrom:      covered,
+	}}}
+	history := &dailyHistory{err: errors.New("stooq error: status 503")}
+	backfiller := NewBackfiller(store, history, nil)
+
+	if _, err := backfiller.Run(context.Background()); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if len(history.requests) != 1 || history.requests[0].key != "AAPL" || !history.requests[0].to.Equal(covered.Time) {
+		t.Fatalf("unexpected requests: %+v", history.requests)
+	}
+	if len(store.progress) != 1 || store.progress[0].CoveredFrom != covered || store.progress[0].LastError != "stooq error: status 503" {
+		t.Fatalf("expected error saved with unchanged progress, got %+v", store.progress)
+	}
+}
+
+func TestBackfillerWithoutHistoryProvider(t *testing.T) {
+	t.Parallel()
+
+	store := &mockBackfillStore{candidates: []db.BackfillCandidate{{
+		TrackedAsset:     db.TrackedAsset{ID: 3, Symbol: "AAPL", Type: db.AssetTypeStock},
+		EarliestPurchase: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+	}}}
+	backfiller := NewBackfiller(store, nil, &dailyHistory{})
+
+	if _, err := backfiller.Run(context.Background()); !errors.Is(err, providers.ErrHistoryUnsupported) {
+		t.Fatalf("expected ErrHistoryUnsupported, got %v", err)
+	}
+	if len(store.progress) != 1 || store.progress[0].LastError == "" {
+		t.Fatalf("expected error saved, got %+v", store.progress)
+	}
+}
+
+func TestBackfillerSkipsAssetsPricedSincePurchase(t *testing.T) {
+	t.Parallel()
+
+	firstSnapshot := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	store := &mockBackfillStore{candidates: []db.BackfillCandidate{{
+		TrackedAsset:     db.TrackedAsset{ID: 5, MarketDataID: "ethereum", Type: db.AssetTypeCrypto},
+		EarliestPurchase: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		FirstSnapshotAt:  sql.NullTime{Time: firstSnapshot, Valid: true},
+	}}}
+	history := &dailyHistory{}
+	backfiller := NewBackfiller(store, nil, history)
+
+	if _, err := backfiller.Run(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(history.requests) != 0 || len(store.progress) != 1 || !store.progress[0].CoveredFrom.Time.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected progress saved without requests, got requests=%+v progress=%+v", history.requests, store.progress)
+	}
+}
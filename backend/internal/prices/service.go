@@ -2,8 +2,10 @@ package prices
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"log/slog"
+	"math"
 	"strings"
 	"time"
 
@@ -33,6 +35,9 @@ type Store interface {
 	FetchTrackedAssets(ctx context.Context) ([]db.TrackedAsset, error)
 	UpsertCurrentPrices(ctx context.Context, updates []db.PriceUpdate) error
 	InsertPriceSnapshots(ctx context.Context, updates []db.PriceUpdate) error
+	RecordPriceDisagreements(ctx context.Context, disagreements []db.PriceDisagreement) error
+	FetchAlertCandidates(ctx context.Context, assetIDs []int64, now time.Time) ([]db.AlertCandidate, error)
+	RecordAlertEvaluations(ctx context.Context, fired []db.AlertEvent, rearmIDs []int64) ([]db.AlertEvent, error)
 }
 
 func NewService(store Store, stock providers.StockProvider, crypto providers.CryptoProvider) *Service {
@@ -50,6 +55,7 @@ func (s *Service) Refresh(ctx context.Context) error {
 	dueCount := 0
 	quoteCount := 0
 	updateCount := 0
+	alertCount := 0
 	var refreshErr error
 	defer func() {
 		duration := time.Since(start).Round(time.Millisecond)
@@ -60,6 +66,7 @@ func (s *Service) Refresh(ctx context.Context) error {
 				"due", dueCount,
 				"quotes", quoteCount,
 				"updates_written", updateCount,
+				"alerts_fired", alertCount,
 				"duration", duration.String(),
 				"error", refreshErr,
 			)
@@ -71,6 +78,7 @@ func (s *Service) Refresh(ctx context.Context) error {
 			"due", dueCount,
 			"quotes", quoteCount,
 			"updates_written", updateCount,
+			"alerts_fired", alertCount,
 			"duration", duration.String(),
 		)
 	}()
@@ -113,6 +121,7 @@ func (s *Service) Refresh(ctx context.Context) error {
 
 	var errs []error
 	updates := make([]db.PriceUpdate, 0, len(dueAssets))
+	var disagreements []db.PriceDisagreement
 
 	if len(stockMap) > 0 {
 		quotes, err := s.stock.FetchQuotes(ctx, keys(stockMap))
@@ -121,6 +130,7 @@ func (s *Service) Refresh(ctx context.Context) error {
 		} else {
 			quoteCount += len(quotes)
 			updates = append(updates, toUpdates(quotes, stockMap, now)...)
+			disagreements = append(disagreements, toDisagreements(quotes, stockMap, now)...)
 		}
 	}
 
@@ -131,6 +141,16 @@ func (s *Service) Refresh(ctx context.Context) error {
 		} else {
 			quoteCount += len(quotes)
 			updates = append(updates, toUpdates(quotes, cryptoMap, now)...)
+			disagreements = append(disagreements, toDisagreements(quotes, cryptoMap, now)...)
+		}
+	}
+
+	if len(disagreements) > 0 {
+		for _, disagreement := range disagreements {
+			slog.Warn("providers disagree on price", "asset_id", disagreement.AssetID, "median", disagreement.Median, "rejected", disagreement.Rejected)
+		}
+		if err := s.store.RecordPriceDisagreements(ctx, disagreements); err != nil {
+			errs = append(errs, err)
 		}
 	}
 
@@ -140,13 +160,22 @@ func (s *Service) Refresh(ctx context.Context) error {
 		return refreshErr
 	}
 
-	if err := s.store.UpsertCurrentPrices(ctx, updates); err != nil {
-		errs = append(errs, err)
+	upsertErr := s.store.UpsertCurrentPrices(ctx, updates)
+	if upsertErr != nil {
+		errs = append(errs, upsertErr)
 	}
 	if err := s.store.InsertPriceSnapshots(ctx, updates); err != nil {
 		errs = append(errs, err)
 	}
 
+	if upsertErr == nil {
+		fired, err := s.evaluateAlerts(ctx, now, updates)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		alertCount = fired
+	}
+
 	for _, update := range updates {
 		if state, ok := s.state[update.AssetID]; ok {
 			state.nextDue = now.Add(state.interval)
@@ -210,29 +239,74 @@ func keys[T any](m map[string]T) []string {
 	return out
 }
 
+// toUpdates skips quotes rejected by quorum, so the asset keeps its last
+// price.
 func toUpdates(quotes []providers.AssetQuote, assets map[string]dueAsset, fetchedAt time.Time) []db.PriceUpdate {
 	updates := make([]db.PriceUpdate, 0, len(quotes))
 	for _, quote := range quotes {
 		asset, ok := assets[quote.LookupKey]
-		if !ok {
+		if !ok || (quote.Disagreement != nil && quote.Disagreement.Rejected) {
 			continue
 		}
-		updates = append(updates, db.PriceUpdate{
-			AssetID:   asset.ID,
-			Price:     quote.Price,
-			FetchedAt: fetchedAt,
-			Provider:  quote.Provider,
-		})
+		update := db.PriceUpdate{
+			AssetID:      asset.ID,
+			Price:        quote.Price,
+			FetchedAt:    fetchedAt,
+			Provider:     quote.Provider,
+			Change24hPct: nullFloat(quote.Change24hPct),
+			Volume24h:    nullFloat(quote.Volume24h),
+			MarketCap:    nullFloat(quote.MarketCap),
+		}
+		if !quote.AsOf.IsZero() {
+			update.ProviderAsOf = sql.NullTime{Time: quote.AsOf, Valid: true}
+		}
+		updates = append(updates, update)
 	}
 	return updates
 }
 
+// nullFloat drops missing and non-finite market data.
+func nullFloat(value *float64) sql.NullFloat64 {
+	if value == nil || math.IsNaN(*value) || math.IsInf(*value, 0) {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *value, Valid: true}
+}
+
+func toDisagreements(quotes []providers.AssetQuote, assets map[string]dueAsset, detectedAt time.Time) []db.PriceDisagreement {
+	var disagreements []db.PriceDisagreement
+	for _, quote := range quotes {
+		asset, ok := assets[quote.LookupKey]
+		if !ok || quote.Disagreement == nil {
+			continue
+		}
+		prices := make([]db.ProviderPrice, 0, len(quote.Disagreement.Prices))
+		for _, price := range quote.Disagreement.Prices {
+			prices = append(prices, db.ProviderPrice{Provider: price.Provider, Price: price.Price, Outlier: price.Outlier})
+		}
+		disagreements = append(disagreements, db.PriceDisagreement{
+			AssetID:    asset.ID,
+			Median:     quote.Disagreement.Median,
+			Tolerance:  quote.Disagreement.Tolerance,
+			Quotes:     prices,
+			Rejected:   quote.Disagreement.Rejected,
+			DetectedAt: detectedAt,
+		})
+	}
+	return disagreements
+}
+
+// lookupKeyForAsset prefers a crypto asset's market_data_id, then its
+// blockchain and contract address, and only then its ambiguous symbol.
 func lookupKeyForAsset(asset db.TrackedAsset) string {
 	switch asset.Type {
 	case db.AssetTypeCrypto:
 		if marketDataID := strings.TrimSpace(asset.MarketDataID); marketDataID != "" {
 			return strings.ToLower(marketDataID)
 		}
+		if strings.TrimSpace(asset.LookupBlockchain) != "" && strings.TrimSpace(asset.LookupAddress) != "" {
+			return providers.AddressLookupKey(asset.LookupBlockchain, asset.LookupAddress)
+		}
 		return strings.ToLower(strings.TrimSpace(asset.Symbol))
 	default:
 		return strings.TrimSpace(asset.Symbol)
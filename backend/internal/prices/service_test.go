@@ -3,6 +3,7 @@ package prices
 import (
 	"context"
 	"errors"
+	"math"
 	"sort"
 	"strings"
 	"testing"
@@ -26,6 +27,13 @@ type mockStore struct {
 	snapshotErr       error
 	snapshotCalls     int
 	snapshottedUpdate []db.PriceUpdate
+
+	disagreements []db.PriceDisagreement
+
+	alertCandidates []db.AlertCandidate
+	alertAssetIDs   []int64
+	firedAlerts     []db.AlertEvent
+	rearmedAlerts   []int64
 }
 
 func (m *mockStore) FetchAppSettings(ctx context.Context) (db.AppSettings, error) {
@@ -57,6 +65,22 @@ func (m *mockStore) InsertPriceSnapshots(ctx context.Context, updates []db.Price
 	return m.snapshotErr
 }
 
+func (m *mockStore) RecordPriceDisagreements(ctx context.Context, disagreements []db.PriceDisagreement) error {
+	m.disagreements = append(m.disagreements, disagreements...)
+	return nil
+}
+
+func (m *mockStore) FetchAlertCandidates(ctx context.Context, assetIDs []int64, now time.Time) ([]db.AlertCandidate, error) {
+	m.alertAssetIDs = append([]int64(nil), assetIDs...)
+	return m.alertCandidates, nil
+}
+
+func (m *mockStore) RecordAlertEvaluations(ctx context.Context, fired []db.AlertEvent, rearmIDs []int64) ([]db.AlertEvent, error) {
+	m.firedAlerts = append(m.firedAlerts, fired...)
+	m.rearmedAlerts = append(m.rearmedAlerts, rearmIDs...)
+	return fired, nil
+}
+
 type mockQuoteProvider struct {
 	quotes []providers.AssetQuote
 	err    error
@@ -222,6 +246,63 @@ func TestRefreshDBWriteErrorsAreJoined(t *testing.T) {
 	}
 }
 
+func TestRefreshRecordsDisagreementsAndSkipsRejectedQuotes(t *testing.T) {
+	t.Parallel()
+
+	store := &mockStore{
+		settings: db.AppSettings{MinRefreshIntervalSec: 60, MaxRefreshIntervalSec: 3600},
+		tracked: []db.TrackedAsset{
+			{ID: 1, Type: db.AssetTypeCrypto, Symbol: "BTC", MarketDataID: "bitcoin", MinUserRefreshSec: 60},
+			{ID: 2, Type: db.AssetTypeCrypto, Symbol: "PEPE", MarketDataID: "pepe", MinUserRefreshSec: 60},
+		},
+	}
+	crypto := &mockQuoteProvider{
+		quotes: []providers.AssetQuote{
+			{LookupKey: "bitcoin", Price: 64200, Provider: "mobula+http", Disagreement: &providers.QuoteDisagreement{
+				Median:    64000,
+				Tolerance: 0.05,
+				Prices: []providers.ProviderPrice{
+					{Provider: "mobula", Price: 64000},
+					{Provider: "coingecko", Price: 6400, Outlier: true},
+					{Provider: "http", Price: 64400},
+				},
+			}},
+			{LookupKey: "pepe", Price: 0.000015, Disagreement: &providers.QuoteDisagreement{
+				Median:    0.000015,
+				Tolerance: 0.05,
+				Prices: []providers.ProviderPrice{
+					{Provider: "mobula", Price: 0.00001, Outlier: true},
+					{Provider: "coingecko", Price: 0.00002, Outlier: true},
+				},
+				Rejected: true,
+			}},
+		},
+	}
+
+	svc := NewService(store, &mockQuoteProvider{}, crypto)
+	start := time.Now().UTC()
+
+	if err := svc.Refresh(context.Background()); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if len(store.upsertedUpdates) != 1 || store.upsertedUpdates[0].AssetID != 1 || store.upsertedUpdates[0].Price != 64200 {
+		t.Fatalf("expected only the accepted quote written, got %+v", store.upsertedUpdates)
+	}
+	if len(store.disagreements) != 2 {
+		t.Fatalf("expected 2 disagreements recorded, got %+v", store.disagreements)
+	}
+	if store.disagreements[0].AssetID != 1 || store.disagreements[0].Rejected || !store.disagreements[0].Quotes[1].Outlier {
+		t.Fatalf("unexpected accepted disagreement: %+v", store.disagreements[0])
+	}
+	if store.disagreements[1].AssetID != 2 || !store.disagreements[1].Rejected || len(store.disagreements[1].Quotes) != 2 {
+		t.Fatalf("unexpected rejected disagreement: %+v", store.disagreements[1])
+	}
+	if svc.state[2].nextDue.After(time.Now()) || !svc.state[1].nextDue.After(start) {
+		t.Fatalf("expected only the accepted asset rescheduled, got btc=%v pepe=%v", svc.state[1].nextDue, svc.state[2].nextDue)
+	}
+}
+
 func TestRefreshSkipsProvidersWhenNothingIsDue(t *testing.T) {
 	t.Parallel()
 
@@ -288,6 +369,16 @@ func TestLookupKeyForAsset(t *testing.T) {
 		t.Fatalf("expected sol fallback when market_data_id is whitespace, got %q", got)
 	}
 
+	cryptoByAddress := db.TrackedAsset{Type: "crypto", Symbol: "USDC", LookupBlockchain: " Ethereum ", LookupAddress: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"}
+	if got := lookupKeyForAsset(cryptoByAddress); got != "ethereum:0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48" {
+		t.Fatalf("expected address lookup key, got %q", got)
+	}
+
+	solanaByAddress := db.TrackedAsset{Type: "crypto", Symbol: "BONK", LookupBlockchain: "solana", LookupAddress: "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263"}
+	if got := lookupKeyForAsset(solanaByAddress); got != "solana:DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263" {
+		t.Fatalf("expected case-preserving solana key, got %q", got)
+	}
+
 	stock := db.TrackedAsset{Type: "stock", Symbol: "  AAPL  "}
 	if got := lookupKeyForAsset(stock); got != "AAPL" {
 		t.Fatalf("expected AAPL, got %q", got)
@@ -316,6 +407,42 @@ func TestToUpdates(t *testing.T) {
 	if !updates[0].FetchedAt.Equal(now) {
 		t.Fatalf("expected fetched_at %v, got %v", now, updates[0].FetchedAt)
 	}
+	if updates[0].Change24hPct.Valid || updates[0].ProviderAsOf.Valid {
+		t.Fatalf("expected no market data, got %+v", updates[0])
+	}
+}
+
+func TestToUpdatesCarriesMarketData(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	asOf := now.Add(-time.Minute).Truncate(time.Second)
+	change, volume, marketCap := -2.5, 3.2e10, math.Inf(1)
+	assets := map[string]dueAsset{"bitcoin": {TrackedAsset: db.TrackedAsset{ID: 101}}}
+	quotes := []providers.AssetQuote{{
+		LookupKey:    "bitcoin",
+		Price:        64000,
+		Provider:     "coingecko",
+		Change24hPct: &change,
+		Volume24h:    &volume,
+		MarketCap:    &marketCap,
+		AsOf:         asOf,
+	}}
+
+	updates := toUpdates(quotes, assets, now)
+	if len(updates) != 1 {
+		t.Fatalf("expected 1 update, got %d", len(updates))
+	}
+	update := updates[0]
+	if !update.Change24hPct.Valid || update.Change24hPct.Float64 != -2.5 || update.Volume24h.Float64 != 3.2e10 {
+		t.Fatalf("unexpected market data: %+v", update)
+	}
+	if update.MarketCap.Valid {
+		t.Fatalf("expected non-finite market cap dropped, got %+v", update.MarketCap)
+	}
+	if !update.ProviderAsOf.Valid || !update.ProviderAsOf.Time.Equal(asOf) || !update.FetchedAt.Equal(now) {
+		t.Fatalf("unexpected timestamps: as_of=%+v fetched_at=%v", update.ProviderAsOf, update.FetchedAt)
+	}
 }
 
 func TestReconcileDueAndPruneState(t *testing.T) {
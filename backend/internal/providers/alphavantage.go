@@ -0,0 +1,104 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const alphaVantageDefaultBaseURL = "https://www.alphavantage.co"
+
+// AlphaVantageProvider quotes one symbol per request with GLOBAL_QUOTE. Class
+// shares use a dash (BRK-B).
+type AlphaVantageProvider struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// alphaVantageResponse holds a quote or one of the messages the API sends
+// with a 200 status instead: Error Message for bad requests, Note and
+// Information for rate limits and plan restrictions.
+type alphaVantageResponse struct {
+	GlobalQuote  map[string]string `json:"Global Quote"`
+	ErrorMessage string            `json:"Error Message"`
+	Note         string            `json:"Note"`
+	Information  string            `json:"Information"`
+}
+
+func NewAlphaVantageProvider(baseURL, apiKey string) *AlphaVantageProvider {
+	resolvedBaseURL := strings.TrimRight(baseURL, "/")
+	if resolvedBaseURL == "" {
+		resolvedBaseURL = alphaVantageDefaultBaseURL
+	}
+
+	return &AlphaVantageProvider{
+		baseURL: resolvedBaseURL,
+		apiKey:  apiKey,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Limits is the free plan's budget of 5 requests a minute.
+func (p *AlphaVantageProvider) Limits() Limits {
+	return Limits{BatchSize: 1, RequestsPerMinute: 5}
+}
+
+func (p *AlphaVantageProvider) FetchQuotes(ctx context.Context, lookupKeys []string) ([]AssetQuote, error) {
+	symbols, keysBySymbol := stockSymbols(lookupKeys, "-")
+	if len(symbols) == 0 {
+		return nil, nil
+	}
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("alpha vantage api key is not set")
+	}
+
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+
+	quotes := make([]AssetQuote, 0, len(symbols))
+	for _, symbol := range symbols {
+		query := url.Values{}
+		query.Set("function", "GLOBAL_QUOTE")
+		query.Set("symbol", symbol)
+		query.Set("apikey", p.apiKey)
+		body, err := getProviderBody(ctx, p.client, "alpha vantage", p.baseURL+"/query?"+query.Encode(), header)
+		if err != nil {
+			return nil, err
+		}
+
+		var payload alphaVantageResponse
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, err
+		}
+		switch {
+		case payload.ErrorMessage != "":
+			// Also returned for unknown symbols.
+			continue
+		case payload.Note != "":
+			return nil, fmt.Errorf("alpha vantage error: %s", payload.Note)
+		case payload.Information != "":
+			return nil, fmt.Errorf("alpha vantage error: %s", payload.Information)
+		}
+
+		price, err := strconv.ParseFloat(payload.GlobalQuote["05. price"], 64)
+		if err != nil || price <= 0 {
+			continue
+		}
+		for _, key := range keysBySymbol[symbol] {
+			quotes = append(quotes, AssetQuote{
+				LookupKey: key,
+				Price:     price,
+				Provider:  "alphavantage",
+			})
+		}
+	}
+	return quotes, nil
+}
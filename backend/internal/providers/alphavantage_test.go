@@ -0,0 +1,69 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAlphaVantageProviderFetchQuotes(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("function") != "GLOBAL_QUOTE" || query.Get("apikey") != "test-key" {
+			t.Fatalf("unexpected query: %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch query.Get("symbol") {
+		case "IBM":
+			_, _ = w.Write([]byte(`{"Global Quote":{"01. symbol":"IBM","05. price":"150.2500","07. latest trading day":"2026-10-16"}}`))
+		case "BRK-B":
+			_, _ = w.Write([]byte(`{"Global Quote":{"01. symbol":"BRK-B","05. price":"420.10"}}`))
+		default:
+			_, _ = w.Write([]byte(`{"Global Quote":{}}`))
+		}
+	}))
+	defer ts.Close()
+
+	p := NewAlphaVantageProvider(ts.URL, "test-key")
+	quotes, err := p.FetchQuotes(context.Background(), []string{"IBM", "BRK.B", "NOPE"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(quotes) != 2 {
+		t.Fatalf("expected 2 quotes, got %+v", quotes)
+	}
+	if quotes[0].LookupKey != "IBM" || quotes[0].Price != 150.25 || quotes[0].Provider != "alphavantage" {
+		t.Fatalf("unexpected first quote: %+v", quotes[0])
+	}
+	if quotes[1].LookupKey != "BRK.B" || quotes[1].Price != 420.1 {
+		t.Fatalf("unexpected second quote: %+v", quotes[1])
+	}
+}
+
+func TestAlphaVantageProviderFetchQuotes_RateLimitNote(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("symbol") == "BAD" {
+			_, _ = w.Write([]byte(`{"Error Message":"Invalid API call."}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"Note":"Thank you for using Alpha Vantage! Our standard API call frequency is 5 calls per minute."}`))
+	}))
+	defer ts.Close()
+
+	p := NewAlphaVantageProvider(ts.URL, "test-key")
+	quotes, err := p.FetchQuotes(context.Background(), []string{"BAD"})
+	if err != nil || len(quotes) != 0 {
+		t.Fatalf("expected unknown symbol to be skipped, got %+v, %v", quotes, err)
+	}
+	_, err = p.FetchQuotes(context.Background(), []string{"IBM"})
+	if err == nil || !strings.Contains(err.Error(), "call frequency") {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"asset-tracker/internal/config"
+)
+
+// CatalogEntry is one coin from a provider's listing endpoint.
+type CatalogEntry struct {
+	MarketDataID string
+	Symbol       string
+	Name         string
+}
+
+type CatalogProvider interface {
+	ListCatalog(ctx context.Context) ([]CatalogEntry, error)
+}
+
+// NewCatalogFromConfig lists from the first crypto provider when several are
+// configured.
+func NewCatalogFromConfig(cfg config.Config) (CatalogProvider, error) {
+	var settings config.ProviderSettings
+	if len(cfg.CryptoProviders) > 0 {
+		settings = cfg.CryptoProviders[0]
+	}
+	name := strings.TrimSpace(strings.ToLower(settings.Name))
+	switch name {
+	case "mobula":
+		return NewMobulaProvider(settings.BaseURL, settings.APIKey), nil
+	case "coingecko":
+		baseURL := settings.BaseURL
+		if baseURL == "" {
+			baseURL = CoinGeckoDefaultBaseURL("public")
+		}
+		return NewCoinGeckoProvider(baseURL, settings.APIKey), nil
+	case "coingecko-pro":
+		baseURL := settings.BaseURL
+		if baseURL == "" {
+			baseURL = CoinGeckoDefaultBaseURL("pro")
+		}
+		return NewCoinGeckoProvider(baseURL, settings.APIKey), nil
+	default:
+		return nil, fmt.Errorf("crypto provider %q does not support catalog listings", name)
+	}
+}
+
+type coinGeckoListEntry struct {
+	ID     string `json:"id"`
+	Symbol string `json:"symbol"`
+	Name   string `json:"name"`
+}
+
+func (p *CoinGeckoProvider) ListCatalog(ctx context.Context) ([]CatalogEntry, error) {
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("coingecko api key is not set")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/coins/list", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set(p.apiKeyHeader, p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return nil, fmt.Errorf("coingecko error: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var payload []coinGeckoListEntry
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, err
+	}
+
+	entries := make([]CatalogEntry, 0, len(payload))
+	for _, row := range payload {
+		entries = append(entries, CatalogEntry{
+			MarketDataID: row.ID,
+			Symbol:       row.Symbol,
+			Name:         row.Name,
+		})
+	}
+	return entries, nil
+}
+
+type mobulaAllResponse struct {
+	Data []mobulaListEntry `json:"data"`
+}
+
+type mobulaListEntry struct {
+	ID     json.RawMessage `json:"id"`
+	Name   string          `json:"name"`
+	Symbol string          `json:"symbol"`
+}
+
+// ListCatalog keys Mobula assets by lowercased name, which is the asset key
+// accepted by the multi-data endpoint.
+func (p *MobulaProvider) ListCatalog(ctx context.Context) ([]CatalogEntry, error) {
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("mobula api key is not set")
+	}
+
+	endpoint, err := url.Parse(p.baseURL + "/api/1/all")
+	if err != nil {
+		return nil, err
+	}
+	query := endpoint.Query()
+	query.Set("fields", "id,name,symbol")
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return nil, fmt.Errorf("mobula error: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var payload mobulaAllResponse
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, err
+	}
+
+	entries := make([]CatalogEntry, 0, len(payload.Data))
+	for _, row := range payload.Data {
+		key := normalizeMobulaLookupKey(row.Name)
+		if key == "" {
+			id, err := parseMobulaID(row.ID)
+			if err != nil {
+				continue
+			}
+			key = id
+		}
+		entries = append(entries, CatalogEntry{
+			MarketDataID: key,
+			Symbol:       row.Symbol,
+			Name:         row.Name,
+		})
+	}
+	return entries, nil
+}
@@ -0,0 +1,67 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCoinGeckoProviderListCatalog(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/coins/list" {
+			t.Fatalf("unexpected path %q", r.URL.Path)
+		}
+		if got := r.Header.Get("x-cg-demo-api-key"); got != "test-key" {
+			t.Fatalf("expected demo api key header, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":"bitcoin","symbol":"btc","name":"Bitcoin"},{"id":"ethereum","symbol":"eth","name":"Ethereum"}]`))
+	}))
+	defer ts.Close()
+
+	p := NewCoinGeckoProvider(ts.URL, "test-key")
+	entries, err := p.ListCatalog(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].MarketDataID != "bitcoin" || entries[0].Symbol != "btc" || entries[0].Name != "Bitcoin" {
+		t.Fatalf("unexpected first entry: %+v", entries[0])
+	}
+}
+
+func TestMobulaProviderListCatalogKeysByName(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/1/all" {
+			t.Fatalf("unexpected path %q", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "test-key" {
+			t.Fatalf("expected authorization header test-key, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"id":100001656,"name":"Bitcoin","symbol":"BTC"},{"id":42,"name":"","symbol":"ANON"}]}`))
+	}))
+	defer ts.Close()
+
+	p := NewMobulaProvider(ts.URL, "test-key")
+	entries, err := p.ListCatalog(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].MarketDataID != "bitcoin" || entries[0].Symbol != "BTC" {
+		t.Fatalf("unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].MarketDataID != "42" {
+		t.Fatalf("expected numeric id fallback, got %+v", entries[1])
+	}
+}
@@ -0,0 +1,117 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"asset-tracker/internal/telemetry"
+)
+
+// QuoteProvider is what StockProvider and CryptoProvider have in common.
+type QuoteProvider interface {
+	FetchQuotes(ctx context.Context, lookupKeys []string) ([]AssetQuote, error)
+}
+
+// ChainLink is one provider of a ChainProvider.
+type ChainLink struct {
+	Name     string
+	Provider QuoteProvider
+}
+
+// ChainProvider asks its links in order, passing each one only the keys that
+// earlier links failed on or did not price.
+type ChainProvider struct {
+	links []ChainLink
+}
+
+func NewChainProvider(links ...ChainLink) *ChainProvider {
+	return &ChainProvider{links: links}
+}
+
+// FetchQuotes returns every quote the chain found. Link errors are logged and
+// only returned, joined, when no link priced anything.
+func (p *ChainProvider) FetchQuotes(ctx context.Context, lookupKeys []string) ([]AssetQuote, error) {
+	remaining := make([]string, 0, len(lookupKeys))
+	seen := make(map[string]struct{}, len(lookupKeys))
+	for _, key := range lookupKeys {
+		normalized := strings.ToLower(strings.TrimSpace(key))
+		if normalized == "" {
+			continue
+		}
+		if _, ok := seen[normalized]; ok {
+			continue
+		}
+		seen[normalized] = struct{}{}
+		remaining = append(remaining, key)
+	}
+	if len(remaining) == 0 {
+		return nil, nil
+	}
+
+	var quotes []AssetQuote
+	var errs []error
+	for _, link := range p.links {
+		if len(remaining) == 0 {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		linkQuotes, err := link.Provider.FetchQuotes(ctx, remaining)
+		if err != nil {
+			telemetry.ProviderFetch(link.Name, len(remaining), 0, err)
+			slog.Warn("quote provider failed", "provider", link.Name, "keys", len(remaining), "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", link.Name, err))
+			continue
+		}
+
+		pending := make(map[string]struct{}, len(remaining))
+		for _, key := range remaining {
+			pending[strings.ToLower(strings.TrimSpace(key))] = struct{}{}
+		}
+		priced := make(map[string]struct{}, len(linkQuotes))
+		for _, quote := range linkQuotes {
+			normalized := strings.ToLower(strings.TrimSpace(quote.LookupKey))
+			if _, ok := pending[normalized]; !ok {
+				continue
+			}
+			if _, ok := priced[normalized]; ok {
+				continue
+			}
+			priced[normalized] = struct{}{}
+			if quote.Provider == "" {
+				quote.Provider = link.Name
+			}
+			quotes = append(quotes, quote)
+		}
+		telemetry.ProviderFetch(link.Name, len(remaining), len(priced), nil)
+
+		next := remaining[:0:0]
+		for _, key := range remaining {
+			if _, ok := priced[strings.ToLower(strings.TrimSpace(key))]; !ok {
+				next = append(next, key)
+			}
+		}
+		remaining = next
+	}
+
+	if len(quotes) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return quotes, nil
+}
+
+// FetchHistory returns the history of the first link that has any.
+func (p *ChainProvider) FetchHistory(ctx context.Context, lookupKey string, from, to time.Time) ([]HistoricalPrice, error) {
+	return historyFromLinks(ctx, p.links, lookupKey, from, to)
+}
+
+func (p *ChainProvider) supportsHistory() bool {
+	return linksSupportHistory(p.links)
+}
@@ -0,0 +1,91 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"asset-tracker/internal/config"
+)
+
+type stubProvider struct {
+	prices    map[string]float64
+	name      string
+	err       error
+	requested []string
+}
+
+func (p *stubProvider) FetchQuotes(ctx context.Context, lookupKeys []string) ([]AssetQuote, error) {
+	p.requested = append([]string(nil), lookupKeys...)
+	if p.err != nil {
+		return nil, p.err
+	}
+	var quotes []AssetQuote
+	for _, key := range lookupKeys {
+		if price, ok := p.prices[key]; ok {
+			quotes = append(quotes, AssetQuote{LookupKey: key, Price: price, Provider: p.name})
+		}
+	}
+	return quotes, nil
+}
+
+func TestChainProviderFallsBackPerKey(t *testing.T) {
+	t.Parallel()
+
+	primary := &stubProvider{name: "mobula", prices: map[string]float64{"bitcoin": 64000}}
+	secondary := &stubProvider{name: "coingecko", prices: map[string]float64{"bitcoin": 1, "pepe": 0.00001}}
+	chain := NewChainProvider(ChainLink{Name: "mobula", Provider: primary}, ChainLink{Name: "coingecko", Provider: secondary})
+
+	quotes, err := chain.FetchQuotes(context.Background(), []string{"bitcoin", "pepe", "unknown", "bitcoin"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Join(primary.requested, ",") != "bitcoin,pepe,unknown" {
+		t.Fatalf("unexpected primary keys: %v", primary.requested)
+	}
+	if strings.Join(secondary.requested, ",") != "pepe,unknown" {
+		t.Fatalf("unexpected secondary keys: %v", secondary.requested)
+	}
+	if len(quotes) != 2 || quotes[0].Provider != "mobula" || quotes[0].Price != 64000 || quotes[1].LookupKey != "pepe" || quotes[1].Provider != "coingecko" {
+		t.Fatalf("unexpected quotes: %+v", quotes)
+	}
+}
+
+func TestChainProviderSkipsFailingLink(t *testing.T) {
+	t.Parallel()
+
+	primary := &stubProvider{err: errors.New("status 502")}
+	secondary := &stubProvider{prices: map[string]float64{"bitcoin": 64000}}
+	chain := NewChainProvider(ChainLink{Name: "mobula", Provider: primary}, ChainLink{Name: "coingecko", Provider: secondary})
+
+	quotes, err := chain.FetchQuotes(context.Background(), []string{"bitcoin"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(quotes) != 1 || quotes[0].Provider != "coingecko" {
+		t.Fatalf("expected quote attributed to coingecko, got %+v", quotes)
+	}
+
+	secondary.err = errors.New("status 429")
+	_, err = chain.FetchQuotes(context.Background(), []string{"bitcoin"})
+	if err == nil || !strings.Contains(err.Error(), "mobula: status 502") || !strings.Contains(err.Error(), "coingecko: status 429") {
+		t.Fatalf("expected joined errors, got %v", err)
+	}
+}
+
+func TestNewFromConfigBuildsChainInOrder(t *testing.T) {
+	t.Parallel()
+
+	set := NewFromConfig(config.Config{CryptoProviders: []config.ProviderSettings{
+		{Name: "mobula", APIKey: "a"},
+		{Name: "coingecko", APIKey: "b"},
+	}})
+	chain, ok := set.Crypto.(*ChainProvider)
+	if !ok || len(chain.links) != 2 || chain.links[0].Name != "mobula" || chain.links[1].Name != "coingecko" {
+		t.Fatalf("unexpected crypto provider: %#v", set.Crypto)
+	}
+	if _, ok := set.Stock.(MissingProvider); !ok {
+		t.Fatalf("expected missing stock provider, got %#v", set.Stock)
+	}
+}
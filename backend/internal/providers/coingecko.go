@@ -3,10 +3,11 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -14,6 +15,7 @@ import (
 const (
 	coinGeckoPublicBaseURL = "https://api.coingecko.com/api/v3"
 	coinGeckoProBaseURL    = "https://pro-api.coingecko.com/api/v3"
+	coinGeckoMaxBatchSize  = 250
 )
 
 type CoinGeckoProvider struct {
@@ -46,62 +48,258 @@ func NewCoinGeckoProvider(baseURL, apiKey string) *CoinGeckoProvider {
 	}
 }
 
+// Limits keeps the ids query short and within the demo plan's 30 calls a
+// minute, or the pro plan's 500.
+func (p *CoinGeckoProvider) Limits() Limits {
+	if p.apiKeyHeader == "x-cg-pro-api-key" {
+		return Limits{BatchSize: coinGeckoMaxBatchSize, RequestsPerMinute: 500}
+	}
+	return Limits{BatchSize: coinGeckoMaxBatchSize, RequestsPerMinute: 30}
+}
+
+// SplitRequests puts coin ids and each platform's contract addresses in
+// separate batches. The demo plan quotes one contract address per request.
+func (p *CoinGeckoProvider) SplitRequests(keys []string, batchSize int) [][]string {
+	var ids, platforms []string
+	keysByPlatform := make(map[string][]string)
+	for _, key := range keys {
+		blockchain, _, ok := parseAddressKey(key)
+		if !ok {
+			ids = append(ids, key)
+			continue
+		}
+		platform := coinGeckoPlatform(blockchain)
+		if _, ok := keysByPlatform[platform]; !ok {
+			platforms = append(platforms, platform)
+		}
+		keysByPlatform[platform] = append(keysByPlatform[platform], key)
+	}
+
+	batches := chunkStrings(ids, batchSize)
+	for _, platform := range platforms {
+		batches = append(batches, chunkStrings(keysByPlatform[platform], min(batchSize, p.tokenBatchSize()))...)
+	}
+	return batches
+}
+
+// FetchQuotes prices coin ids through /simple/price and address lookup keys
+// through /simple/token_price, one request per platform. When a request
+// fails, the quotes of the others are returned with the joined errors; a 429
+// stops the remaining requests.
 func (p *CoinGeckoProvider) FetchQuotes(ctx context.Context, lookupKeys []string) ([]AssetQuote, error) {
-	ids := normalizeIDs(lookupKeys)
-	if len(ids) == 0 {
+	var addressKeys []string
+	idKeys := make([]string, 0, len(lookupKeys))
+	for _, key := range lookupKeys {
+		if _, _, ok := parseAddressKey(key); ok {
+			addressKeys = append(addressKeys, strings.TrimSpace(key))
+			continue
+		}
+		idKeys = append(idKeys, key)
+	}
+	ids := normalizeIDs(idKeys)
+	if len(ids) == 0 && len(addressKeys) == 0 {
 		return nil, nil
 	}
 	if p.apiKey == "" {
 		return nil, fmt.Errorf("coingecko api key is not set")
 	}
 
-	endpoint, err := url.Parse(p.baseURL + "/simple/price")
+	quotes := make([]AssetQuote, 0, len(ids)+len(addressKeys))
+	var errs []error
+	if len(ids) > 0 {
+		payload, err := p.fetchPrices(ctx, "/simple/price", url.Values{"ids": {strings.Join(ids, ",")}})
+		if err != nil && (ctx.Err() != nil || isRateLimited(err)) {
+			return nil, err
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for id, values := range payload {
+			if quote, ok := p.quote(id, values); ok {
+				quotes = append(quotes, quote)
+			}
+		}
+	}
+	if len(addressKeys) > 0 {
+		tokenQuotes, err := p.fetchTokenQuotes(ctx, addressKeys)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		quotes = append(quotes, tokenQuotes...)
+	}
+
+	return quotes, errors.Join(errs...)
+}
+
+// fetchTokenQuotes groups address keys by platform and maps the returned
+// contract addresses, which CoinGecko lowercases, back to the keys.
+func (p *CoinGeckoProvider) fetchTokenQuotes(ctx context.Context, addressKeys []string) ([]AssetQuote, error) {
+	var platforms []string
+	addressesByPlatform := make(map[string][]string)
+	keyByAddress := make(map[string]map[string]string)
+	for _, key := range addressKeys {
+		blockchain, address, _ := parseAddressKey(key)
+		platform := coinGeckoPlatform(blockchain)
+		if _, ok := keyByAddress[platform]; !ok {
+			platforms = append(platforms, platform)
+			keyByAddress[platform] = make(map[string]string)
+		}
+		normalized := strings.ToLower(address)
+		if _, ok := keyByAddress[platform][normalized]; ok {
+			continue
+		}
+		keyByAddress[platform][normalized] = key
+		addressesByPlatform[platform] = append(addressesByPlatform[platform], address)
+	}
+
+	quotes := make([]AssetQuote, 0, len(addressKeys))
+	var errs []error
+	for _, platform := range platforms {
+		for _, batch := range chunkStrings(addressesByPlatform[platform], p.tokenBatchSize()) {
+			query := url.Values{"contract_addresses": {strings.Join(batch, ",")}}
+			payload, err := p.fetchPrices(ctx, "/simple/token_price/"+url.PathEscape(platform), query)
+			if err != nil {
+				errs = append(errs, err)
+				if ctx.Err() != nil || isRateLimited(err) {
+					return quotes, errors.Join(errs...)
+				}
+				continue
+			}
+			for address, values := range payload {
+				key, ok := keyByAddress[platform][strings.ToLower(address)]
+				if !ok {
+					continue
+				}
+				if quote, ok := p.quote(key, values); ok {
+					quotes = append(quotes, quote)
+				}
+			}
+		}
+	}
+	return quotes, errors.Join(errs...)
+}
+
+// tokenBatchSize is the number of contract addresses per /simple/token_price
+// request: one on the demo plan.
+func (p *CoinGeckoProvider) tokenBatchSize() int {
+	if p.apiKeyHeader == "x-cg-pro-api-key" {
+		return coinGeckoMaxBatchSize
+	}
+	return 1
+}
+
+// quote reads one entry of a price response. Market data is requested with
+// every price and is null for coins CoinGecko has none for.
+func (p *CoinGeckoProvider) quote(key string, values map[string]*float64) (AssetQuote, bool) {
+	price := values[p.vsCurrency]
+	if price == nil {
+		return AssetQuote{}, false
+	}
+	quote := AssetQuote{
+		LookupKey:    key,
+		Price:        *price,
+		Provider:     "coingecko",
+		Change24hPct: values[p.vsCurrency+"_24h_change"],
+		Volume24h:    values[p.vsCurrency+"_24h_vol"],
+		MarketCap:    values[p.vsCurrency+"_market_cap"],
+	}
+	if updatedAt := values["last_updated_at"]; updatedAt != nil && *updatedAt > 0 {
+		quote.AsOf = time.Unix(int64(*updatedAt), 0).UTC()
+	}
+	return quote, true
+}
+
+func (p *CoinGeckoProvider) fetchPrices(ctx context.Context, path string, query url.Values) (map[string]map[string]*float64, error) {
+	endpoint, err := url.Parse(p.baseURL + path)
 	if err != nil {
 		return nil, err
 	}
-
-	query := endpoint.Query()
-	query.Set("ids", strings.Join(ids, ","))
 	query.Set("vs_currencies", p.vsCurrency)
+	query.Set("include_market_cap", "true")
+	query.Set("include_24hr_vol", "true")
+	query.Set("include_24hr_change", "true")
+	query.Set("include_last_updated_at", "true")
 	endpoint.RawQuery = query.Encode()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
-	if err != nil {
+	var payload map[string]map[string]*float64
+	if err := p.getJSON(ctx, endpoint.String(), &payload); err != nil {
 		return nil, err
 	}
+	return payload, nil
+}
+
+// FetchHistory reads /market_chart/range, by coin id or by contract address.
+// CoinGecko returns daily points for ranges over 90 days and hourly ones for
+// shorter ranges; either way one price per day is kept.
+func (p *CoinGeckoProvider) FetchHistory(ctx context.Context, lookupKey string, from, to time.Time) ([]HistoricalPrice, error) {
+	id := strings.ToLower(strings.TrimSpace(lookupKey))
+	if id == "" || !historyDay(from).Before(historyDay(to)) {
+		return nil, nil
+	}
+	path := "/coins/" + url.PathEscape(id)
+	if blockchain, address, ok := parseAddressKey(lookupKey); ok {
+		path = "/coins/" + url.PathEscape(coinGeckoPlatform(blockchain)) + "/contract/" + url.PathEscape(strings.ToLower(address))
+	}
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("coingecko api key is not set")
+	}
+
+	query := url.Values{
+		"vs_currency": {p.vsCurrency},
+		"from":        {strconv.FormatInt(historyDay(from).Unix(), 10)},
+		"to":          {strconv.FormatInt(historyDay(to).Unix(), 10)},
+	}
+	var payload struct {
+		Prices [][2]float64 `json:"prices"`
+	}
+	if err := p.getJSON(ctx, p.baseURL+path+"/market_chart/range?"+query.Encode(), &payload); err != nil {
+		return nil, err
+	}
+
+	points := make([]HistoricalPrice, 0, len(payload.Prices))
+	for _, point := range payload.Prices {
+		points = append(points, HistoricalPrice{At: time.UnixMilli(int64(point[0])).UTC(), Price: point[1], Provider: "coingecko"})
+	}
+	return dailyPrices(points, from, to), nil
+}
+
+func (p *CoinGeckoProvider) getJSON(ctx context.Context, endpoint string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set(p.apiKeyHeader, p.apiKey)
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
-		return nil, fmt.Errorf("coingecko error: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
-	}
-
-	var payload map[string]map[string]float64
-	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-		return nil, err
+		return newStatusError("coingecko", resp)
 	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
 
-	quotes := make([]AssetQuote, 0, len(payload))
-	for id, values := range payload {
-		price, ok := values[p.vsCurrency]
-		if !ok {
-			continue
-		}
-		quotes = append(quotes, AssetQuote{
-			LookupKey: id,
-			Price:     price,
-			Provider:  "coingecko",
-		})
+// coinGeckoPlatform maps common blockchain names to CoinGecko asset platform
+// ids; other names are used as they are.
+func coinGeckoPlatform(blockchain string) string {
+	switch blockchain = strings.ToLower(strings.TrimSpace(blockchain)); blockchain {
+	case "bsc", "bnb", "binance", "bnb smart chain", "bnb smart chain (bep20)":
+		return "binance-smart-chain"
+	case "polygon", "matic":
+		return "polygon-pos"
+	case "arbitrum":
+		return "arbitrum-one"
+	case "optimism":
+		return "optimistic-ethereum"
+	case "avalanche c-chain":
+		return "avalanche"
+	default:
+		return blockchain
 	}
-
-	return quotes, nil
 }
 
 func normalizeIDs(ids []string) []string {
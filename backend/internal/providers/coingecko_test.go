@@ -0,0 +1,177 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCoinGeckoProviderFetchQuotes(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("x-cg-demo-api-key"); got != "test-key" {
+			t.Fatalf("expected demo api key header, got %q", got)
+		}
+		query := r.URL.Query()
+		if r.URL.Path != "/simple/price" || query.Get("ids") != "bitcoin,ethereum" || query.Get("vs_currencies") != "usd" || query.Get("include_24hr_change") != "true" {
+			t.Fatalf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{
+			"bitcoin":{"usd":64000,"usd_market_cap":1.26e12,"usd_24h_vol":3.1e10,"usd_24h_change":-1.5,"last_updated_at":1792324800},
+			"ethereum":{"usd":3200,"usd_24h_change":null}
+		}`))
+	}))
+	defer ts.Close()
+
+	p := NewCoinGeckoProvider(ts.URL, "test-key")
+	quotes, err := p.FetchQuotes(context.Background(), []string{"Bitcoin", "ethereum", "bitcoin"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	sort.Slice(quotes, func(i, j int) bool { return quotes[i].LookupKey < quotes[j].LookupKey })
+	if len(quotes) != 2 || quotes[0].LookupKey != "bitcoin" || quotes[0].Price != 64000 || quotes[1].Provider != "coingecko" {
+		t.Fatalf("unexpected quotes: %+v", quotes)
+	}
+	bitcoin := quotes[0]
+	if bitcoin.Change24hPct == nil || *bitcoin.Change24hPct != -1.5 || bitcoin.Volume24h == nil || *bitcoin.MarketCap != 1.26e12 {
+		t.Fatalf("unexpected bitcoin market data: %+v", bitcoin)
+	}
+	if !bitcoin.AsOf.Equal(time.Unix(1792324800, 0)) {
+		t.Fatalf("unexpected as-of time: %v", bitcoin.AsOf)
+	}
+	if quotes[1].Change24hPct != nil || !quotes[1].AsOf.IsZero() {
+		t.Fatalf("expected no ethereum market data, got %+v", quotes[1])
+	}
+}
+
+func TestCoinGeckoProviderFetchQuotesByAddress(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path+"?"+r.URL.Query().Get("contract_addresses"))
+		switch r.URL.Path {
+		case "/simple/token_price/ethereum":
+			if r.URL.Query().Get("contract_addresses") != "0xa0b8" {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"0xa0b8":{"usd":1.0001}}`))
+		case "/simple/token_price/binance-smart-chain":
+			_, _ = w.Write([]byte(`{"0x55d3":{"usd":0.9998}}`))
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	p := NewCoinGeckoProvider(ts.URL, "test-key")
+	quotes, err := p.FetchQuotes(context.Background(), []string{"ethereum:0xa0b8", "bsc:0x55D3", "ethereum:0xdead"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(paths) != 3 || paths[0] != "/simple/token_price/ethereum?0xa0b8" || paths[2] != "/simple/token_price/binance-smart-chain?0x55D3" {
+		t.Fatalf("expected one request per demo-plan address, got %v", paths)
+	}
+	sort.Slice(quotes, func(i, j int) bool { return quotes[i].LookupKey < quotes[j].LookupKey })
+	if len(quotes) != 2 || quotes[0].LookupKey != "bsc:0x55D3" || quotes[0].Price != 0.9998 || quotes[1].LookupKey != "ethereum:0xa0b8" {
+		t.Fatalf("unexpected quotes: %+v", quotes)
+	}
+}
+
+func TestCoinGeckoProviderFetchHistory(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2024, 1, 1, 15, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC)
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		query := r.URL.Query()
+		if query.Get("vs_currency") != "usd" || query.Get("from") != "1704067200" || query.Get("to") != "1704240000" {
+			t.Fatalf("unexpected query: %s", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"prices":[[1704067200000,42000.5],[1704153600000,44100],[1704240000000,44900]],"market_caps":[],"total_volumes":[]}`))
+	}))
+	defer ts.Close()
+
+	p := NewCoinGeckoProvider(ts.URL, "test-key")
+	prices, err := p.FetchHistory(context.Background(), "Bitcoin", from, to)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(prices) != 2 || !prices[0].At.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) || prices[0].Price != 42000.5 || prices[1].Price != 44100 || prices[1].Provider != "coingecko" {
+		t.Fatalf("unexpected prices: %+v", prices)
+	}
+
+	if _, err := p.FetchHistory(context.Background(), AddressLookupKey("bsc", "0xABC"), from, to); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(paths) != 2 || paths[0] != "/coins/bitcoin/market_chart/range" || paths[1] != "/coins/binance-smart-chain/contract/0xabc/market_chart/range" {
+		t.Fatalf("unexpected paths: %v", paths)
+	}
+}
+
+func TestCoinGeckoProviderLimiterReservesEveryRequest(t *testing.T) {
+	t.Parallel()
+
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/simple/token_price/ethereum" && r.URL.Query().Get("contract_addresses") == "0xdead" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if r.URL.Path == "/simple/price" {
+			_, _ = w.Write([]byte(`{"bitcoin":{"usd":64000}}`))
+			return
+		}
+		address := r.URL.Query().Get("contract_addresses")
+		_, _ = w.Write([]byte(`{"` + address + `":{"usd":1}}`))
+	}))
+	defer ts.Close()
+
+	p := NewCoinGeckoProvider(ts.URL, "test-key")
+	limited, waits := newTestLimitedProvider(p, p.Limits())
+	quotes, err := limited.FetchQuotes(context.Background(), []string{"bitcoin", "ethereum:0xa0b8", "ethereum:0xdead", "bsc:0x55d3"})
+	if err != nil {
+		t.Fatalf("expected partial results without error, got %v", err)
+	}
+	if requests != 4 || len(*waits) != 4 {
+		t.Fatalf("expected a limiter slot per request, got requests=%d reservations=%d", requests, len(*waits))
+	}
+	if len(quotes) != 3 {
+		t.Fatalf("expected quotes for the other keys, got %+v", quotes)
+	}
+}
+
+func TestCoinGeckoProviderFetchQuotesKeepsPartialQuotes(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/simple/price":
+			_, _ = w.Write([]byte(`{"bitcoin":{"usd":64000}}`))
+		case "/simple/token_price/ethereum":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			_, _ = w.Write([]byte(`{"0x55d3":{"usd":0.9998}}`))
+		}
+	}))
+	defer ts.Close()
+
+	p := NewCoinGeckoProvider(ts.URL, "test-key")
+	quotes, err := p.FetchQuotes(context.Background(), []string{"bitcoin", "ethereum:0xa0b8", "bsc:0x55d3"})
+	if err == nil || !strings.Contains(err.Error(), "status 502") {
+		t.Fatalf("expected the failed request's error, got %v", err)
+	}
+	sort.Slice(quotes, func(i, j int) bool { return quotes[i].LookupKey < quotes[j].LookupKey })
+	if len(quotes) != 2 || quotes[0].LookupKey != "bitcoin" || quotes[1].LookupKey != "bsc:0x55d3" {
+		t.Fatalf("expected the other quotes, got %+v", quotes)
+	}
+}
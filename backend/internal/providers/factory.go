@@ -6,60 +6,132 @@ import (
 	"asset-tracker/internal/config"
 )
 
+// ProviderSet holds the quote providers. StockHistory and CryptoHistory are
+// the same chains, and are nil when no configured provider has price history.
 type ProviderSet struct {
-	Stock  StockProvider
-	Crypto CryptoProvider
+	Stock         StockProvider
+	Crypto        CryptoProvider
+	StockHistory  HistoryProvider
+	CryptoHistory HistoryProvider
 }
 
 func NewFromConfig(cfg config.Config) ProviderSet {
+	stock := buildChain("stock", cfg.StockProviders, cfg.StockProviderQuorum, func(settings config.ProviderSettings) QuoteProvider { return buildStock(cfg, settings) })
+	crypto := buildChain("crypto", cfg.CryptoProviders, cfg.CryptoProviderQuorum, func(settings config.ProviderSettings) QuoteProvider { return buildCrypto(cfg, settings) })
 	return ProviderSet{
-		Stock:  buildStock(cfg),
-		Crypto: buildCrypto(cfg),
+		Stock:         stock,
+		Crypto:        crypto,
+		StockHistory:  historyOf(stock),
+		CryptoHistory: historyOf(crypto),
 	}
 }
 
-func buildStock(cfg config.Config) StockProvider {
-	name := strings.TrimSpace(strings.ToLower(cfg.StockProviderName))
-	if name == "" {
-		return NewMissingProvider("stock")
+func historyOf(provider QuoteProvider) HistoryProvider {
+	history, ok := provider.(HistoryProvider)
+	if !ok || !supportsHistory(provider) {
+		return nil
+	}
+	return history
+}
+
+// buildChain wraps the configured providers in a ChainProvider, in order, so
+// a key one of them cannot price falls through to the next, or in a
+// QuorumProvider when quorum mode is on. Each provider is limited to its
+// batch size and request budget, retries transient failures per batch within
+// that budget, and sits behind a circuit breaker.
+func buildChain(kind string, providers []config.ProviderSettings, quorum config.QuorumSettings, build func(config.ProviderSettings) QuoteProvider) QuoteProvider {
+	if len(providers) == 0 {
+		return NewMissingProvider(kind)
+	}
+	links := make([]ChainLink, 0, len(providers))
+	for _, settings := range providers {
+		provider := build(settings)
+		limited := NewLimitedProvider(settings.Name, provider, providerLimits(provider, settings), DefaultRetryPolicy)
+		links = append(links, ChainLink{Name: settings.Name, Provider: NewBreakerProvider(settings.Name, limited, DefaultBreakerSettings)})
+	}
+	if quorum.Enabled {
+		return NewQuorumProvider(quorum.Tolerance, quorum.MinQuotes, links...)
+	}
+	return NewChainProvider(links...)
+}
+
+// providerLimits returns the provider's declared limits with the configured
+// overrides applied.
+func providerLimits(provider QuoteProvider, settings config.ProviderSettings) Limits {
+	var limits Limits
+	if limited, ok := provider.(RateLimited); ok {
+		limits = limited.Limits()
 	}
+	if settings.BatchSize > 0 {
+		limits.BatchSize = settings.BatchSize
+	}
+	if settings.RequestsPerMinute > 0 {
+		limits.RequestsPerMinute = settings.RequestsPerMinute
+	}
+	return limits
+}
 
-	switch name {
+func buildStock(cfg config.Config, settings config.ProviderSettings) StockProvider {
+	switch strings.TrimSpace(strings.ToLower(settings.Name)) {
+	case "finnhub":
+		return NewFinnhubProvider(settings.BaseURL, settings.APIKey)
+	case "twelvedata":
+		return NewTwelveDataProvider(settings.BaseURL, settings.APIKey)
+	case "alphavantage":
+		return NewAlphaVantageProvider(settings.BaseURL, settings.APIKey)
+	case "stooq":
+		return NewStooqProvider(settings.BaseURL)
+	case "simulated":
+		return NewSimulatedProvider(settings.Seed)
+	case "fixture":
+		return NewFixtureProvider(settings.Path)
 	case "http":
-		return NewHTTPProvider("stock", cfg.StockProviderBaseURL, cfg.StockProviderAPIKey)
+		return NewHTTPProvider(httpProviderConfig("stock", settings.BaseURL, settings.APIKey, cfg.StockProviderHTTP))
 	default:
 		return NewMissingProvider("stock")
 	}
 }
 
-func buildCrypto(cfg config.Config) CryptoProvider {
-	name := strings.TrimSpace(strings.ToLower(cfg.CryptoProviderName))
-	if name == "" {
-		return NewMissingProvider("crypto")
-	}
-
-	switch name {
+func buildCrypto(cfg config.Config, settings config.ProviderSettings) CryptoProvider {
+	switch strings.TrimSpace(strings.ToLower(settings.Name)) {
 	case "mobula":
-		baseURL := cfg.CryptoProviderBaseURL
+		baseURL := settings.BaseURL
 		if baseURL == "" {
 			baseURL = mobulaDefaultBaseURL
 		}
-		return NewMobulaProvider(baseURL, cfg.CryptoProviderAPIKey)
+		return NewMobulaProvider(baseURL, settings.APIKey)
 	case "coingecko":
-		baseURL := cfg.CryptoProviderBaseURL
+		baseURL := settings.BaseURL
 		if baseURL == "" {
 			baseURL = CoinGeckoDefaultBaseURL("public")
 		}
-		return NewCoinGeckoProvider(baseURL, cfg.CryptoProviderAPIKey)
+		return NewCoinGeckoProvider(baseURL, settings.APIKey)
 	case "coingecko-pro":
-		baseURL := cfg.CryptoProviderBaseURL
+		baseURL := settings.BaseURL
 		if baseURL == "" {
 			baseURL = CoinGeckoDefaultBaseURL("pro")
 		}
-		return NewCoinGeckoProvider(baseURL, cfg.CryptoProviderAPIKey)
+		return NewCoinGeckoProvider(baseURL, settings.APIKey)
+	case "simulated":
+		return NewSimulatedProvider(settings.Seed)
+	case "fixture":
+		return NewFixtureProvider(settings.Path)
 	case "http":
-		return NewHTTPProvider("crypto", cfg.CryptoProviderBaseURL, cfg.CryptoProviderAPIKey)
+		return NewHTTPProvider(httpProviderConfig("crypto", settings.BaseURL, settings.APIKey, cfg.CryptoProviderHTTP))
 	default:
 		return NewMissingProvider("crypto")
 	}
 }
+
+func httpProviderConfig(kind, urlTemplate, apiKey string, settings config.HTTPProviderSettings) HTTPProviderConfig {
+	return HTTPProviderConfig{
+		Kind:        kind,
+		URLTemplate: urlTemplate,
+		APIKey:      apiKey,
+		AuthHeader:  settings.AuthHeader,
+		BatchSize:   settings.BatchSize,
+		ResultsPath: settings.ResultsPath,
+		KeyField:    settings.KeyField,
+		PriceField:  settings.PriceField,
+	}
+}
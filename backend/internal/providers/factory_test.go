@@ -0,0 +1,30 @@
+package providers
+
+import (
+	"context"
+	"testing"
+
+	"asset-tracker/internal/config"
+)
+
+func TestNewFromConfigBuildsOfflineProviders(t *testing.T) {
+	t.Parallel()
+
+	path := writeFixture(t, "stocks.csv", "key,price\nAAPL,190.5\n")
+	set := NewFromConfig(config.Config{
+		StockProviders:  []config.ProviderSettings{{Name: "fixture", Path: path}},
+		CryptoProviders: []config.ProviderSettings{{Name: "simulated", Seed: 42}},
+	})
+
+	stock, err := set.Stock.FetchQuotes(context.Background(), []string{"AAPL"})
+	if err != nil || len(stock) != 1 || stock[0].Price != 190.5 || stock[0].Provider != "fixture" {
+		t.Fatalf("unexpected stock quotes: %+v err=%v", stock, err)
+	}
+	crypto, err := set.Crypto.FetchQuotes(context.Background(), []string{"bitcoin"})
+	if err != nil || len(crypto) != 1 || crypto[0].Provider != "simulated" {
+		t.Fatalf("unexpected crypto quotes: %+v err=%v", crypto, err)
+	}
+	if set.StockHistory != nil || set.CryptoHistory != nil {
+		t.Fatalf("expected no history providers, got %+v", set)
+	}
+}
@@ -0,0 +1,88 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const finnhubDefaultBaseURL = "https://finnhub.io/api/v1"
+
+// FinnhubProvider quotes one symbol per request from /quote. Class shares use
+// a dot (BRK.B).
+type FinnhubProvider struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+type finnhubQuote struct {
+	Current float64 `json:"c"`
+	Error   string  `json:"error"`
+}
+
+func NewFinnhubProvider(baseURL, apiKey string) *FinnhubProvider {
+	resolvedBaseURL := strings.TrimRight(baseURL, "/")
+	if resolvedBaseURL == "" {
+		resolvedBaseURL = finnhubDefaultBaseURL
+	}
+
+	return &FinnhubProvider{
+		baseURL: resolvedBaseURL,
+		apiKey:  apiKey,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Limits is the free plan's budget of 60 calls a minute.
+func (p *FinnhubProvider) Limits() Limits {
+	return Limits{BatchSize: 1, RequestsPerMinute: 60}
+}
+
+func (p *FinnhubProvider) FetchQuotes(ctx context.Context, lookupKeys []string) ([]AssetQuote, error) {
+	symbols, keysBySymbol := stockSymbols(lookupKeys, ".")
+	if len(symbols) == 0 {
+		return nil, nil
+	}
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("finnhub api key is not set")
+	}
+
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	header.Set("X-Finnhub-Token", p.apiKey)
+
+	quotes := make([]AssetQuote, 0, len(symbols))
+	for _, symbol := range symbols {
+		body, err := getProviderBody(ctx, p.client, "finnhub", p.baseURL+"/quote?symbol="+url.QueryEscape(symbol), header)
+		if err != nil {
+			return nil, err
+		}
+
+		var payload finnhubQuote
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, err
+		}
+		if payload.Error != "" {
+			return nil, fmt.Errorf("finnhub error: %s", payload.Error)
+		}
+		// Unknown symbols come back as a quote of zeros.
+		if payload.Current <= 0 {
+			continue
+		}
+		for _, key := range keysBySymbol[symbol] {
+			quotes = append(quotes, AssetQuote{
+				LookupKey: key,
+				Price:     payload.Current,
+				Provider:  "finnhub",
+			})
+		}
+	}
+	return quotes, nil
+}
@@ -0,0 +1,75 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFinnhubProviderFetchQuotes(t *testing.T) {
+	t.Parallel()
+
+	var symbols []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Finnhub-Token"); got != "test-key" {
+			t.Fatalf("expected token header test-key, got %q", got)
+		}
+		symbol := r.URL.Query().Get("symbol")
+		symbols = append(symbols, symbol)
+		w.Header().Set("Content-Type", "application/json")
+		switch symbol {
+		case "AAPL":
+			_, _ = w.Write([]byte(`{"c":190.5,"d":1.2,"dp":0.63,"h":191,"l":188,"o":189,"pc":189.3,"t":1760000000}`))
+		case "BRK.B":
+			_, _ = w.Write([]byte(`{"c":420.1}`))
+		default:
+			_, _ = w.Write([]byte(`{"c":0,"d":null,"dp":null,"h":0,"l":0,"o":0,"pc":0,"t":0}`))
+		}
+	}))
+	defer ts.Close()
+
+	p := NewFinnhubProvider(ts.URL, "test-key")
+	quotes, err := p.FetchQuotes(context.Background(), []string{"aapl", "BRK-B", "NOPE", "AAPL"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Join(symbols, ",") != "AAPL,BRK.B,NOPE" {
+		t.Fatalf("unexpected requested symbols: %q", symbols)
+	}
+	if len(quotes) != 3 {
+		t.Fatalf("expected 3 quotes, got %+v", quotes)
+	}
+	if quotes[0].LookupKey != "aapl" || quotes[0].Price != 190.5 || quotes[0].Provider != "finnhub" {
+		t.Fatalf("unexpected first quote: %+v", quotes[0])
+	}
+	if quotes[1].LookupKey != "AAPL" || quotes[1].Price != 190.5 {
+		t.Fatalf("unexpected second quote: %+v", quotes[1])
+	}
+	if quotes[2].LookupKey != "BRK-B" || quotes[2].Price != 420.1 {
+		t.Fatalf("unexpected third quote: %+v", quotes[2])
+	}
+}
+
+func TestFinnhubProviderFetchQuotes_Errors(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"Invalid API key."}`))
+	}))
+	defer ts.Close()
+
+	p := NewFinnhubProvider(ts.URL, "bad-key")
+	_, err := p.FetchQuotes(context.Background(), []string{"AAPL"})
+	if err == nil || !strings.Contains(err.Error(), "status 401") || !strings.Contains(err.Error(), "Invalid API key") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+
+	_, err = NewFinnhubProvider(ts.URL, "").FetchQuotes(context.Background(), []string{"AAPL"})
+	if err == nil || !strings.Contains(err.Error(), "api key") {
+		t.Fatalf("expected api key error, got %v", err)
+	}
+}
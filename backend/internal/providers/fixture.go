@@ -0,0 +1,177 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FixtureProvider replays quotes recorded in a JSON or CSV file, for tests and
+// offline development. A key's rows form its sequence, in file order: each
+// fetch returns the next one, and the last repeats once the sequence runs out.
+// Keys match case-insensitively; keys not in the file are not priced.
+//
+// JSON files hold an array of {"key", "price"} objects, CSV files a header row
+// with key and price columns. Both may add change_24h_pct, volume_24h and
+// market_cap.
+type FixtureProvider struct {
+	path string
+	now  func() time.Time
+
+	mu        sync.Mutex
+	sequences map[string][]fixtureQuote
+	next      map[string]int
+}
+
+type fixtureQuote struct {
+	Key          string   `json:"key"`
+	Price        float64  `json:"price"`
+	Change24hPct *float64 `json:"change_24h_pct"`
+	Volume24h    *float64 `json:"volume_24h"`
+	MarketCap    *float64 `json:"market_cap"`
+}
+
+// NewFixtureProvider reads the file on the first fetch. A fetch fails while
+// the file cannot be read, and the next one tries again.
+func NewFixtureProvider(path string) *FixtureProvider {
+	return &FixtureProvider{path: path, now: time.Now, next: make(map[string]int)}
+}
+
+func (p *FixtureProvider) FetchQuotes(ctx context.Context, lookupKeys []string) ([]AssetQuote, error) {
+	keys := normalizeLookupKeys(lookupKeys)
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.sequences == nil {
+		sequences, err := loadFixture(p.path)
+		if err != nil {
+			return nil, err
+		}
+		p.sequences = sequences
+	}
+	asOf := p.now().UTC()
+	quotes := make([]AssetQuote, 0, len(keys))
+	for _, key := range keys {
+		normalized := strings.ToLower(key)
+		sequence := p.sequences[normalized]
+		if len(sequence) == 0 {
+			continue
+		}
+		recorded := sequence[min(p.next[normalized], len(sequence)-1)]
+		p.next[normalized]++
+		quotes = append(quotes, AssetQuote{
+			LookupKey:    key,
+			Price:        recorded.Price,
+			Provider:     "fixture",
+			Change24hPct: recorded.Change24hPct,
+			Volume24h:    recorded.Volume24h,
+			MarketCap:    recorded.MarketCap,
+			AsOf:         asOf,
+		})
+	}
+	return quotes, nil
+}
+
+// loadFixture groups a fixture file's rows by lowercase key.
+func loadFixture(path string) (map[string][]fixtureQuote, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fixture error: %w", err)
+	}
+
+	var rows []fixtureQuote
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(body, &rows)
+	case ".csv":
+		rows, err = parseFixtureCSV(body)
+	default:
+		return nil, fmt.Errorf("fixture error: %s is not a .json or .csv file", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fixture error: %s: %w", path, err)
+	}
+
+	sequences := make(map[string][]fixtureQuote)
+	for i, row := range rows {
+		key := strings.ToLower(strings.TrimSpace(row.Key))
+		if key == "" || row.Price <= 0 {
+			return nil, fmt.Errorf("fixture error: %s: row %d needs a key and a positive price", path, i+1)
+		}
+		sequences[key] = append(sequences[key], row)
+	}
+	return sequences, nil
+}
+
+func parseFixtureCSV(body []byte) ([]fixtureQuote, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["key"]; !ok {
+		return nil, errors.New("missing key column")
+	}
+	if _, ok := columns["price"]; !ok {
+		return nil, errors.New("missing price column")
+	}
+
+	var rows []fixtureQuote
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		row := fixtureQuote{Key: field("key")}
+		if row.Price, err = strconv.ParseFloat(field("price"), 64); err != nil {
+			return nil, fmt.Errorf("row %d: invalid price %q", len(rows)+1, field("price"))
+		}
+		for name, target := range map[string]**float64{
+			"change_24h_pct": &row.Change24hPct,
+			"volume_24h":     &row.Volume24h,
+			"market_cap":     &row.MarketCap,
+		} {
+			if raw := field(name); raw != "" {
+				value, err := strconv.ParseFloat(raw, 64)
+				if err != nil {
+					return nil, fmt.Errorf("row %d: invalid %s %q", len(rows)+1, name, raw)
+				}
+				*target = &value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
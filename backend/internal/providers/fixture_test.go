@@ -0,0 +1,99 @@
+package providers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFixture(t *testing.T, name, body string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	return path
+}
+
+func TestFixtureProviderReplaysJSONSequences(t *testing.T) {
+	t.Parallel()
+
+	path := writeFixture(t, "quotes.json", `[
+		{"key": "bitcoin", "price": 64000, "change_24h_pct": 1.5},
+		{"key": "AAPL", "price": 190.5},
+		{"key": "Bitcoin", "price": 64500}
+	]`)
+	p := NewFixtureProvider(path)
+
+	var prices []float64
+	for range 3 {
+		quotes, err := p.FetchQuotes(context.Background(), []string{"bitcoin", "unknown"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(quotes) != 1 || quotes[0].LookupKey != "bitcoin" || quotes[0].Provider != "fixture" {
+			t.Fatalf("unexpected quotes: %+v", quotes)
+		}
+		prices = append(prices, quotes[0].Price)
+		if len(prices) == 1 && (quotes[0].Change24hPct == nil || *quotes[0].Change24hPct != 1.5) {
+			t.Fatalf("expected recorded market data, got %+v", quotes[0])
+		}
+	}
+	if prices[0] != 64000 || prices[1] != 64500 || prices[2] != 64500 {
+		t.Fatalf("expected the sequence then its last price, got %v", prices)
+	}
+}
+
+func TestFixtureProviderReadsCSV(t *testing.T) {
+	t.Parallel()
+
+	path := writeFixture(t, "quotes.csv", "key,price,market_cap\nAAPL,190.5,2.9e12\nAAPL,191,\n")
+	p := NewFixtureProvider(path)
+
+	first, err := p.FetchQuotes(context.Background(), []string{"aapl"})
+	if err != nil || len(first) != 1 || first[0].Price != 190.5 || first[0].MarketCap == nil || *first[0].MarketCap != 2.9e12 {
+		t.Fatalf("unexpected first quotes: %+v err=%v", first, err)
+	}
+	second, err := p.FetchQuotes(context.Background(), []string{"aapl"})
+	if err != nil || len(second) != 1 || second[0].Price != 191 || second[0].MarketCap != nil {
+		t.Fatalf("unexpected second quotes: %+v err=%v", second, err)
+	}
+}
+
+func TestFixtureProviderReportsBadFiles(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		filepath.Join(t.TempDir(), "missing.json"):          "no such file",
+		writeFixture(t, "quotes.txt", "bitcoin 64000"):      "not a .json or .csv file",
+		writeFixture(t, "bad.csv", "key,price\nAAPL,abc\n"): "invalid price",
+		writeFixture(t, "bad.json", `[{"key": "AAPL"}]`):    "positive price",
+	}
+	for path, want := range cases {
+		_, err := NewFixtureProvider(path).FetchQuotes(context.Background(), []string{"AAPL"})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected error containing %q, got %v", filepath.Base(path), want, err)
+		}
+	}
+}
+
+func TestFixtureProviderRetriesFailedLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "quotes.json")
+	p := NewFixtureProvider(path)
+	if _, err := p.FetchQuotes(context.Background(), []string{"bitcoin"}); err == nil {
+		t.Fatal("expected an error while the file is missing, got nil")
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"key": "bitcoin", "price": 64000}]`), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	quotes, err := p.FetchQuotes(context.Background(), []string{"bitcoin"})
+	if err != nil || len(quotes) != 1 || quotes[0].Price != 64000 {
+		t.Fatalf("expected the file to be read on the next fetch, got quotes=%+v err=%v", quotes, err)
+	}
+}
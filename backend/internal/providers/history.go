@@ -0,0 +1,120 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// ErrHistoryUnsupported is returned for a provider without a price history
+// endpoint.
+var ErrHistoryUnsupported = errors.New("provider has no price history")
+
+// HistoricalPrice is an asset's price on the day starting at At, midnight UTC.
+type HistoricalPrice struct {
+	At       time.Time
+	Price    float64
+	Provider string
+}
+
+// HistoryProvider returns daily prices for one lookup key from the day of from
+// up to, but not including, the day of to, oldest first. Days the provider has
+// no price for are missing.
+type HistoryProvider interface {
+	FetchHistory(ctx context.Context, lookupKey string, from, to time.Time) ([]HistoricalPrice, error)
+}
+
+// supportsHistory reports whether provider, or the provider it wraps, has a
+// price history endpoint.
+func supportsHistory(provider QuoteProvider) bool {
+	switch typed := provider.(type) {
+	case interface{ supportsHistory() bool }:
+		return typed.supportsHistory()
+	case HistoryProvider:
+		return true
+	default:
+		return false
+	}
+}
+
+func linksSupportHistory(links []ChainLink) bool {
+	for _, link := range links {
+		if supportsHistory(link.Provider) {
+			return true
+		}
+	}
+	return false
+}
+
+func fetchHistory(ctx context.Context, provider QuoteProvider, lookupKey string, from, to time.Time) ([]HistoricalPrice, error) {
+	history, ok := provider.(HistoryProvider)
+	if !ok || !supportsHistory(provider) {
+		return nil, ErrHistoryUnsupported
+	}
+	return history.FetchHistory(ctx, lookupKey, from, to)
+}
+
+// historyFromLinks asks each link with history in order and returns the first
+// non-empty answer.
+func historyFromLinks(ctx context.Context, links []ChainLink, lookupKey string, from, to time.Time) ([]HistoricalPrice, error) {
+	if !linksSupportHistory(links) {
+		return nil, ErrHistoryUnsupported
+	}
+	var errs []error
+	for _, link := range links {
+		if !supportsHistory(link.Provider) {
+			continue
+		}
+		prices, err := fetchHistory(ctx, link.Provider, lookupKey, from, to)
+		if err != nil {
+			slog.Warn("price history provider failed", "provider", link.Name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", link.Name, err))
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		if len(prices) == 0 {
+			continue
+		}
+		for i := range prices {
+			if prices[i].Provider == "" {
+				prices[i].Provider = link.Name
+			}
+		}
+		return prices, nil
+	}
+	return nil, errors.Join(errs...)
+}
+
+// dailyPrices keeps the last price of each UTC day in [from, to), stamped at
+// midnight, oldest first.
+func dailyPrices(points []HistoricalPrice, from, to time.Time) []HistoricalPrice {
+	start, end := historyDay(from), historyDay(to)
+	byDay := make(map[time.Time]HistoricalPrice, len(points))
+	for _, point := range points {
+		day := historyDay(point.At)
+		if day.Before(start) || !day.Before(end) || point.Price <= 0 {
+			continue
+		}
+		if last, ok := byDay[day]; ok && last.At.After(point.At) {
+			continue
+		}
+		byDay[day] = point
+	}
+
+	prices := make([]HistoricalPrice, 0, len(byDay))
+	for day, point := range byDay {
+		point.At = day
+		prices = append(prices, point)
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].At.Before(prices[j].At) })
+	return prices
+}
+
+func historyDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,93 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type historyStub struct {
+	stubProvider
+	prices []HistoricalPrice
+	err    error
+	calls  int
+}
+
+func (p *historyStub) FetchHistory(ctx context.Context, lookupKey string, from, to time.Time) ([]HistoricalPrice, error) {
+	p.calls++
+	return p.prices, p.err
+}
+
+func TestDailyPricesKeepsLastPricePerDay(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	points := []HistoricalPrice{
+		{At: day.Add(-time.Hour), Price: 1},
+		{At: day.Add(23 * time.Hour), Price: 12},
+		{At: day.Add(time.Hour), Price: 10},
+		{At: day.Add(26 * time.Hour), Price: 20},
+		{At: day.Add(50 * time.Hour), Price: 30},
+	}
+
+	prices := dailyPrices(points, day.Add(3*time.Hour), day.Add(48*time.Hour))
+	if len(prices) != 2 || !prices[0].At.Equal(day) || prices[0].Price != 12 || prices[1].Price != 20 {
+		t.Fatalf("unexpected daily prices: %+v", prices)
+	}
+}
+
+func TestChainProviderHistorySkipsLinksWithoutHistory(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	failing := &historyStub{err: &StatusError{Provider: "coingecko", StatusCode: http.StatusUnauthorized}}
+	empty := &historyStub{}
+	working := &historyStub{prices: []HistoricalPrice{{At: day, Price: 5}}}
+	chain := NewChainProvider(
+		ChainLink{Name: "mobula", Provider: NewLimitedProvider("mobula", &stubProvider{}, Limits{}, RetryPolicy{})},
+		ChainLink{Name: "coingecko", Provider: NewRetryProvider("coingecko", failing, DefaultRetryPolicy)},
+		ChainLink{Name: "empty", Provider: empty},
+		ChainLink{Name: "stooq", Provider: NewBreakerProvider("history-test", working, DefaultBreakerSettings)},
+	)
+
+	prices, err := chain.FetchHistory(context.Background(), "bitcoin", day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(prices) != 1 || prices[0].Provider != "stooq" || failing.calls != 1 || empty.calls != 1 {
+		t.Fatalf("unexpected history: prices=%+v calls=%d,%d", prices, failing.calls, empty.calls)
+	}
+}
+
+func TestChainProviderHistoryUnsupported(t *testing.T) {
+	t.Parallel()
+
+	chain := NewChainProvider(ChainLink{Name: "mobula", Provider: NewRetryProvider("mobula", &stubProvider{}, DefaultRetryPolicy)})
+	if historyOf(chain) != nil {
+		t.Fatal("expected no history provider")
+	}
+	if _, err := chain.FetchHistory(context.Background(), "bitcoin", time.Now().AddDate(0, 0, -2), time.Now()); !errors.Is(err, ErrHistoryUnsupported) {
+		t.Fatalf("expected ErrHistoryUnsupported, got %v", err)
+	}
+}
+
+func TestLimitedProviderPacesHistory(t *testing.T) {
+	t.Parallel()
+
+	inner := &historyStub{prices: []HistoricalPrice{{Price: 1}}}
+	limited, waits := newTestLimitedProvider(inner, Limits{RequestsPerMinute: 60})
+
+	for range 2 {
+		if _, err := limited.FetchHistory(context.Background(), "bitcoin", time.Now().AddDate(0, 0, -2), time.Now()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if _, err := limited.FetchQuotes(context.Background(), []string{"bitcoin"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(*waits) != 3 || (*waits)[1] < 900*time.Millisecond || (*waits)[2] < 1900*time.Millisecond {
+		t.Fatalf("expected history and quotes to share the budget, got %v", *waits)
+	}
+}
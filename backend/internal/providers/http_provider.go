@@ -2,40 +2,294 @@ package providers
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
 
+const httpProviderDefaultBatchSize = 50
+
+// HTTPProviderConfig points the generic provider at a JSON quote API.
+//
+// URLTemplate must contain {keys}, replaced by a comma-separated batch of
+// lookup keys, or {key}, which sends one request per key; {api_key} is
+// replaced by APIKey. AuthHeader names the header that carries APIKey, with an
+// optional value prefix after a colon ("Authorization: Bearer"); it defaults
+// to Authorization when APIKey is set and the template does not use it.
+//
+// ResultsPath, KeyField and PriceField are dot-separated paths; numeric
+// segments index arrays. ResultsPath selects the quotes, either an array or an
+// object. Without KeyField the object's keys are the lookup keys, and without
+// PriceField each quote is the price itself.
+type HTTPProviderConfig struct {
+	Kind        string
+	URLTemplate string
+	APIKey      string
+	AuthHeader  string
+	BatchSize   int
+	ResultsPath string
+	KeyField    string
+	PriceField  string
+}
+
 type HTTPProvider struct {
-	kind    string
-	baseURL string
-	apiKey  string
-	client  *http.Client
+	config HTTPProviderConfig
+	client *http.Client
 }
 
-func NewHTTPProvider(kind, baseURL, apiKey string) *HTTPProvider {
+func NewHTTPProvider(config HTTPProviderConfig) *HTTPProvider {
+	config.URLTemplate = strings.TrimSpace(config.URLTemplate)
+	if config.BatchSize <= 0 {
+		config.BatchSize = httpProviderDefaultBatchSize
+	}
+	if strings.Contains(config.URLTemplate, "{key}") {
+		config.BatchSize = 1
+	}
+	if config.AuthHeader == "" && config.APIKey != "" && !strings.Contains(config.URLTemplate, "{api_key}") {
+		config.AuthHeader = "Authorization"
+	}
+
 	return &HTTPProvider{
-		kind:    kind,
-		baseURL: strings.TrimRight(baseURL, "/"),
-		apiKey:  apiKey,
+		config: config,
 		client: &http.Client{
 			Timeout: 10 * time.Second,
 		},
 	}
 }
 
+func (p *HTTPProvider) Limits() Limits {
+	return Limits{BatchSize: p.config.BatchSize}
+}
+
 func (p *HTTPProvider) FetchQuotes(ctx context.Context, lookupKeys []string) ([]AssetQuote, error) {
-	if len(lookupKeys) == 0 {
+	keys := normalizeLookupKeys(lookupKeys)
+	if len(keys) == 0 {
 		return nil, nil
 	}
-	if p.baseURL == "" {
-		return nil, fmt.Errorf("%s provider base URL is not set", p.kind)
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	quotes := make([]AssetQuote, 0, len(keys))
+	for start := 0; start < len(keys); start += p.config.BatchSize {
+		batch := keys[start:min(start+p.config.BatchSize, len(keys))]
+		batchQuotes, err := p.fetchBatch(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		quotes = append(quotes, batchQuotes...)
+	}
+	return quotes, nil
+}
+
+func (p *HTTPProvider) validate() error {
+	template := p.config.URLTemplate
+	if template == "" {
+		return fmt.Errorf("%s provider base URL is not set", p.config.Kind)
+	}
+	if !strings.Contains(template, "{keys}") && !strings.Contains(template, "{key}") {
+		return fmt.Errorf("%s provider base URL must contain {keys} or {key}", p.config.Kind)
+	}
+	if p.config.APIKey == "" && (p.config.AuthHeader != "" || strings.Contains(template, "{api_key}")) {
+		return fmt.Errorf("%s provider API key is not set", p.config.Kind)
+	}
+	return nil
+}
+
+func (p *HTTPProvider) fetchBatch(ctx context.Context, keys []string) ([]AssetQuote, error) {
+	escaped := make([]string, 0, len(keys))
+	for _, key := range keys {
+		escaped = append(escaped, url.QueryEscape(key))
+	}
+	endpoint := strings.NewReplacer(
+		"{keys}", strings.Join(escaped, ","),
+		"{key}", escaped[0],
+		"{api_key}", url.QueryEscape(p.config.APIKey),
+	).Replace(p.config.URLTemplate)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.config.AuthHeader != "" {
+		name, prefix, _ := strings.Cut(p.config.AuthHeader, ":")
+		value := p.config.APIKey
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			value = prefix + " " + value
+		}
+		req.Header.Set(strings.TrimSpace(name), value)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, newStatusError(p.config.Kind+" http provider", resp)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	var payload any
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, err
+	}
+
+	results, ok := lookupJSONPath(payload, p.config.ResultsPath)
+	if !ok {
+		return nil, fmt.Errorf("%s http provider response has no %q", p.config.Kind, p.config.ResultsPath)
+	}
+	return p.extractQuotes(results, keys)
+}
+
+// extractQuotes maps results back to the requested keys, matching them
+// case-insensitively; quotes for keys that were not requested or without a
+// positive price are dropped.
+func (p *HTTPProvider) extractQuotes(results any, keys []string) ([]AssetQuote, error) {
+	requested := make(map[string]string, len(keys))
+	for _, key := range keys {
+		requested[strings.ToLower(key)] = key
+	}
+
+	quotes := make([]AssetQuote, 0, len(keys))
+	add := func(rawKey string, item any) {
+		key, ok := requested[strings.ToLower(strings.TrimSpace(rawKey))]
+		if !ok {
+			return
+		}
+		value, ok := lookupJSONPath(item, p.config.PriceField)
+		if !ok {
+			return
+		}
+		price, ok := jsonFloat(value)
+		if !ok || price <= 0 || math.IsNaN(price) || math.IsInf(price, 0) {
+			return
+		}
+		delete(requested, strings.ToLower(key))
+		quotes = append(quotes, AssetQuote{LookupKey: key, Price: price, Provider: "http"})
+	}
+	addItem := func(item any) {
+		rawKey, ok := lookupJSONPath(item, p.config.KeyField)
+		if !ok {
+			return
+		}
+		if key, ok := jsonString(rawKey); ok {
+			add(key, item)
+		}
+	}
+
+	switch typed := results.(type) {
+	case []any:
+		if p.config.KeyField == "" {
+			return nil, fmt.Errorf("%s http provider key field is required for array results", p.config.Kind)
+		}
+		for _, item := range typed {
+			addItem(item)
+		}
+	case map[string]any:
+		switch {
+		case p.config.KeyField != "":
+			if _, ok := lookupJSONPath(typed, p.config.KeyField); ok {
+				addItem(typed)
+				break
+			}
+			for _, item := range typed {
+				addItem(item)
+			}
+		case len(keys) == 1 && p.config.PriceField != "":
+			if _, ok := lookupJSONPath(typed, p.config.PriceField); ok {
+				add(keys[0], typed)
+				break
+			}
+			fallthrough
+		default:
+			for key, item := range typed {
+				add(key, item)
+			}
+		}
+	default:
+		if len(keys) == 1 && p.config.KeyField == "" && p.config.PriceField == "" {
+			add(keys[0], typed)
+			break
+		}
+		return nil, fmt.Errorf("%s http provider results must be an array or an object", p.config.Kind)
 	}
-	if p.apiKey == "" {
-		return nil, fmt.Errorf("%s provider API key is not set", p.kind)
+	return quotes, nil
+}
+
+func normalizeLookupKeys(keys []string) []string {
+	seen := make(map[string]struct{}, len(keys))
+	out := make([]string, 0, len(keys))
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		if _, ok := seen[strings.ToLower(key)]; ok {
+			continue
+		}
+		seen[strings.ToLower(key)] = struct{}{}
+		out = append(out, key)
+	}
+	return out
+}
+
+// lookupJSONPath walks a dot-separated path through decoded JSON; an empty
+// path returns value itself.
+func lookupJSONPath(value any, path string) (any, bool) {
+	if path == "" {
+		return value, true
+	}
+	for _, segment := range strings.Split(path, ".") {
+		switch typed := value.(type) {
+		case map[string]any:
+			next, ok := typed[segment]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(typed) {
+				return nil, false
+			}
+			value = typed[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// jsonFloat reads a number that may be encoded as a JSON string.
+func jsonFloat(value any) (float64, bool) {
+	switch typed := value.(type) {
+	case json.Number:
+		price, err := typed.Float64()
+		return price, err == nil
+	case string:
+		price, err := strconv.ParseFloat(strings.TrimSpace(typed), 64)
+		return price, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func jsonString(value any) (string, bool) {
+	switch typed := value.(type) {
+	case string:
+		return typed, true
+	case json.Number:
+		return typed.String(), true
+	default:
+		return "", false
 	}
-	return nil, errors.New("http provider not implemented yet")
 }
@@ -0,0 +1,140 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPProviderFetchQuotes_ArrayResultsInBatches(t *testing.T) {
+	t.Parallel()
+
+	var batches []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Api-Key"); got != "Token test-key" {
+			t.Fatalf("expected X-Api-Key header, got %q", got)
+		}
+		batches = append(batches, r.URL.Query().Get("symbols"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"quotes":[{"symbol":"aapl","last":{"price":"190.5"}},{"symbol":"MSFT","last":{"price":410}},{"symbol":"TSLA","last":{"price":0}},{"symbol":"BRK.B","last":{"price":420.1}}]}}`))
+	}))
+	defer ts.Close()
+
+	p := NewHTTPProvider(HTTPProviderConfig{
+		Kind:        "stock",
+		URLTemplate: ts.URL + "/quotes?symbols={keys}",
+		APIKey:      "test-key",
+		AuthHeader:  "X-Api-Key: Token",
+		BatchSize:   2,
+		ResultsPath: "data.quotes",
+		KeyField:    "symbol",
+		PriceField:  "last.price",
+	})
+	quotes, err := p.FetchQuotes(context.Background(), []string{"AAPL", "MSFT", "TSLA", "aapl"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(batches) != 2 || batches[0] != "AAPL,MSFT" || batches[1] != "TSLA" {
+		t.Fatalf("unexpected batches: %q", batches)
+	}
+	if len(quotes) != 2 {
+		t.Fatalf("expected 2 quotes, got %+v", quotes)
+	}
+	if quotes[0].LookupKey != "AAPL" || quotes[0].Price != 190.5 || quotes[0].Provider != "http" {
+		t.Fatalf("unexpected first quote: %+v", quotes[0])
+	}
+	if quotes[1].LookupKey != "MSFT" || quotes[1].Price != 410 {
+		t.Fatalf("unexpected second quote: %+v", quotes[1])
+	}
+}
+
+func TestHTTPProviderFetchQuotes_KeyedObject(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("token"); got != "test-key" {
+			t.Fatalf("expected token query test-key, got %q", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Fatalf("expected no authorization header, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"bitcoin":{"usd":64000},"ethereum":{"usd":3100}}`))
+	}))
+	defer ts.Close()
+
+	p := NewHTTPProvider(HTTPProviderConfig{
+		Kind:        "crypto",
+		URLTemplate: ts.URL + "/price?ids={keys}&token={api_key}",
+		APIKey:      "test-key",
+		PriceField:  "usd",
+	})
+	quotes, err := p.FetchQuotes(context.Background(), []string{"bitcoin", "ethereum"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	prices := map[string]float64{}
+	for _, quote := range quotes {
+		prices[quote.LookupKey] = quote.Price
+	}
+	if len(prices) != 2 || prices["bitcoin"] != 64000 || prices["ethereum"] != 3100 {
+		t.Fatalf("unexpected quotes: %+v", quotes)
+	}
+}
+
+func TestHTTPProviderFetchQuotes_PerKeyRequests(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"result":{"price":12.5}}`))
+	}))
+	defer ts.Close()
+
+	p := NewHTTPProvider(HTTPProviderConfig{
+		Kind:        "stock",
+		URLTemplate: ts.URL + "/quote/{key}",
+		ResultsPath: "result",
+		PriceField:  "price",
+	})
+	quotes, err := p.FetchQuotes(context.Background(), []string{"AAPL", "MSFT"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(paths) != 2 || paths[0] != "/quote/AAPL" || paths[1] != "/quote/MSFT" {
+		t.Fatalf("unexpected request paths: %q", paths)
+	}
+	if len(quotes) != 2 || quotes[0].LookupKey != "AAPL" || quotes[1].LookupKey != "MSFT" || quotes[1].Price != 12.5 {
+		t.Fatalf("unexpected quotes: %+v", quotes)
+	}
+}
+
+func TestHTTPProviderFetchQuotes_Errors(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"bad symbol"}`, http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	cases := []struct {
+		config HTTPProviderConfig
+		want   string
+	}{
+		{config: HTTPProviderConfig{Kind: "stock"}, want: "base URL is not set"},
+		{config: HTTPProviderConfig{Kind: "stock", URLTemplate: ts.URL}, want: "must contain {keys} or {key}"},
+		{config: HTTPProviderConfig{Kind: "stock", URLTemplate: ts.URL + "?s={keys}", AuthHeader: "X-Api-Key"}, want: "API key is not set"},
+		{config: HTTPProviderConfig{Kind: "stock", URLTemplate: ts.URL + "?s={keys}"}, want: "status 400"},
+	}
+	for _, tc := range cases {
+		p := NewHTTPProvider(tc.config)
+		_, err := p.FetchQuotes(context.Background(), []string{"AAPL"})
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("expected error containing %q, got %v", tc.want, err)
+		}
+	}
+}
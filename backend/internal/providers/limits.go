@@ -0,0 +1,256 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"asset-tracker/internal/telemetry"
+)
+
+const (
+	// defaultRetryAfter is the pause after a 429 without Retry-After.
+	defaultRetryAfter = 5 * time.Second
+	// maxRetryAfter is the longest Retry-After a batch is retried after;
+	// longer pauses skip the rest of the refresh.
+	maxRetryAfter = 30 * time.Second
+)
+
+// Limits is a provider's request budget: at most BatchSize keys per request
+// and RequestsPerMinute requests. Zero means no limit.
+type Limits struct {
+	BatchSize         int
+	RequestsPerMinute int
+}
+
+// RateLimited is implemented by providers that declare their Limits.
+type RateLimited interface {
+	Limits() Limits
+}
+
+// RequestSplitter is implemented by providers that send more than one request
+// for some sets of keys, such as one per blockchain. SplitRequests groups keys
+// into batches of at most batchSize that each take a single request, so
+// LimitedProvider can give every request its own slot.
+type RequestSplitter interface {
+	SplitRequests(keys []string, batchSize int) [][]string
+}
+
+// StatusError is a non-2xx provider response. RetryAfter is the response's
+// Retry-After, if it had one.
+type StatusError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s error: status %d: %s", e.Provider, e.StatusCode, e.Body)
+}
+
+func newStatusError(provider string, resp *http.Response) *StatusError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+	return &StatusError{
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(body)),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter reads Retry-After as seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+func isRateLimited(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusTooManyRequests
+}
+
+// LimitedProvider splits keys into batches of the provider's size and spaces
+// the requests to stay within its budget. A 429 pauses every later request
+// for its Retry-After, and the batch is retried once when that pause is
+// short. Transient failures are retried as the RetryPolicy allows; every
+// retry waits for its own request slot.
+type LimitedProvider struct {
+	name     string
+	provider QuoteProvider
+	limits   Limits
+	retry    RetryPolicy
+	limiter  *rateLimiter
+	sleep    func(ctx context.Context, d time.Duration) error
+}
+
+func NewLimitedProvider(name string, provider QuoteProvider, limits Limits, retry RetryPolicy) *LimitedProvider {
+	return &LimitedProvider{
+		name:     name,
+		provider: provider,
+		limits:   limits,
+		retry:    retry,
+		limiter:  newRateLimiter(limits.RequestsPerMinute),
+		sleep:    sleepContext,
+	}
+}
+
+// FetchQuotes merges the quotes of every batch, including those a failed
+// batch priced before failing. Batch errors are logged and only returned,
+// joined, when no batch priced anything. A 429 that cannot be waited out skips
+// the remaining batches.
+func (p *LimitedProvider) FetchQuotes(ctx context.Context, lookupKeys []string) ([]AssetQuote, error) {
+	keys := normalizeLookupKeys(lookupKeys)
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	batchSize := p.limits.BatchSize
+	if batchSize <= 0 {
+		batchSize = len(keys)
+	}
+
+	batches := chunkStrings(keys, batchSize)
+	if splitter, ok := p.provider.(RequestSplitter); ok {
+		batches = splitter.SplitRequests(keys, batchSize)
+	}
+
+	var quotes []AssetQuote
+	var errs []error
+	for _, batch := range batches {
+		batchQuotes, err := p.fetchBatch(ctx, batch)
+		quotes = append(quotes, batchQuotes...)
+		if err != nil {
+			slog.Warn("quote provider batch failed", "provider", p.name, "keys", len(batch), "error", err)
+			errs = append(errs, err)
+			if ctx.Err() != nil || isRateLimited(err) {
+				break
+			}
+		}
+	}
+
+	if len(quotes) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return quotes, nil
+}
+
+func (p *LimitedProvider) fetchBatch(ctx context.Context, batch []string) ([]AssetQuote, error) {
+	return limitedCall(ctx, p, func() ([]AssetQuote, error) { return p.provider.FetchQuotes(ctx, batch) })
+}
+
+// FetchHistory spends the same request budget as quotes.
+func (p *LimitedProvider) FetchHistory(ctx context.Context, lookupKey string, from, to time.Time) ([]HistoricalPrice, error) {
+	if !supportsHistory(p.provider) {
+		return nil, ErrHistoryUnsupported
+	}
+	return limitedCall(ctx, p, func() ([]HistoricalPrice, error) { return fetchHistory(ctx, p.provider, lookupKey, from, to) })
+}
+
+func (p *LimitedProvider) supportsHistory() bool {
+	return supportsHistory(p.provider)
+}
+
+func limitedCall[T any](ctx context.Context, p *LimitedProvider, call func() (T, error)) (T, error) {
+	var zero T
+	rateLimitRetried := false
+	for retries := 0; ; {
+		if err := p.sleep(ctx, p.limiter.reserve(time.Now())); err != nil {
+			return zero, err
+		}
+		result, err := call()
+		if err == nil || ctx.Err() != nil {
+			return result, err
+		}
+
+		switch {
+		case isRateLimited(err) && !rateLimitRetried:
+			var statusErr *StatusError
+			errors.As(err, &statusErr)
+			wait := statusErr.RetryAfter
+			if wait <= 0 {
+				wait = defaultRetryAfter
+			}
+			p.limiter.pauseUntil(time.Now().Add(wait))
+			if wait > maxRetryAfter {
+				return zero, err
+			}
+			rateLimitRetried = true
+		case isTransient(err) && retries < p.retry.MaxRetries:
+			telemetry.ProviderRetry(p.name)
+			slog.Warn("retrying quote provider", "provider", p.name, "attempt", retries+1, "error", err)
+			if err := p.sleep(ctx, p.retry.backoff(retries)); err != nil {
+				return zero, err
+			}
+			retries++
+		default:
+			return result, err
+		}
+	}
+}
+
+// rateLimiter spaces requests evenly. It lives as long as its provider, so
+// the budget holds across refresh cycles.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(perMinute int) *rateLimiter {
+	limiter := &rateLimiter{}
+	if perMinute > 0 {
+		limiter.interval = time.Minute / time.Duration(perMinute)
+	}
+	return limiter
+}
+
+// reserve claims the next request slot and returns how long to wait for it.
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	at := now
+	if l.next.After(at) {
+		at = l.next
+	}
+	l.next = at.Add(l.interval)
+	return at.Sub(now)
+}
+
+// pauseUntil holds every request until at.
+func (l *rateLimiter) pauseUntil(at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if at.After(l.next) {
+		l.next = at
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,203 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type scriptedProvider struct {
+	batches [][]string
+	respond func(call int, keys []string) ([]AssetQuote, error)
+}
+
+func (p *scriptedProvider) FetchQuotes(ctx context.Context, lookupKeys []string) ([]AssetQuote, error) {
+	p.batches = append(p.batches, append([]string(nil), lookupKeys...))
+	return p.respond(len(p.batches), lookupKeys)
+}
+
+func priceAll(keys []string) []AssetQuote {
+	quotes := make([]AssetQuote, 0, len(keys))
+	for _, key := range keys {
+		quotes = append(quotes, AssetQuote{LookupKey: key, Price: 1})
+	}
+	return quotes
+}
+
+func newTestLimitedProvider(provider QuoteProvider, limits Limits) (*LimitedProvider, *[]time.Duration) {
+	limited := NewLimitedProvider("test", provider, limits, RetryPolicy{})
+	var waits []time.Duration
+	limited.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	return limited, &waits
+}
+
+func TestLimitedProviderSplitsBatchesAndMergesPartialResults(t *testing.T) {
+	t.Parallel()
+
+	inner := &scriptedProvider{respond: func(call int, keys []string) ([]AssetQuote, error) {
+		if call == 2 {
+			return nil, errors.New("status 502")
+		}
+		return priceAll(keys), nil
+	}}
+	limited, _ := newTestLimitedProvider(inner, Limits{BatchSize: 2})
+
+	quotes, err := limited.FetchQuotes(context.Background(), []string{"a", "b", "c", "d", "e", "A"})
+	if err != nil {
+		t.Fatalf("expected partial results without error, got %v", err)
+	}
+	if len(inner.batches) != 3 || strings.Join(inner.batches[0], ",") != "a,b" || strings.Join(inner.batches[2], ",") != "e" {
+		t.Fatalf("unexpected batches: %v", inner.batches)
+	}
+	if len(quotes) != 3 || quotes[0].LookupKey != "a" || quotes[2].LookupKey != "e" {
+		t.Fatalf("unexpected quotes: %+v", quotes)
+	}
+}
+
+func TestLimitedProviderReturnsErrorWhenEveryBatchFails(t *testing.T) {
+	t.Parallel()
+
+	inner := &scriptedProvider{respond: func(call int, keys []string) ([]AssetQuote, error) {
+		return nil, errors.New("status 401")
+	}}
+	limited, _ := newTestLimitedProvider(inner, Limits{BatchSize: 1})
+
+	quotes, err := limited.FetchQuotes(context.Background(), []string{"a", "b"})
+	if err == nil || quotes != nil || len(inner.batches) != 2 {
+		t.Fatalf("expected joined error after both batches, got quotes=%+v err=%v batches=%v", quotes, err, inner.batches)
+	}
+}
+
+func TestLimitedProviderPacesRequests(t *testing.T) {
+	t.Parallel()
+
+	inner := &scriptedProvider{respond: func(call int, keys []string) ([]AssetQuote, error) {
+		return priceAll(keys), nil
+	}}
+	limited, waits := newTestLimitedProvider(inner, Limits{BatchSize: 1, RequestsPerMinute: 60})
+
+	if _, err := limited.FetchQuotes(context.Background(), []string{"a", "b", "c"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(*waits) != 3 || (*waits)[0] != 0 {
+		t.Fatalf("unexpected waits: %v", *waits)
+	}
+	for _, wait := range (*waits)[1:] {
+		if wait < 900*time.Millisecond || wait > 2*time.Second {
+			t.Fatalf("expected about a second between requests, got %v", *waits)
+		}
+	}
+}
+
+func TestLimitedProviderRetriesAfterRateLimit(t *testing.T) {
+	t.Parallel()
+
+	inner := &scriptedProvider{respond: func(call int, keys []string) ([]AssetQuote, error) {
+		if call == 1 {
+			return nil, &StatusError{Provider: "test", StatusCode: http.StatusTooManyRequests, RetryAfter: 2 * time.Second}
+		}
+		return priceAll(keys), nil
+	}}
+	limited, waits := newTestLimitedProvider(inner, Limits{BatchSize: 1})
+
+	quotes, err := limited.FetchQuotes(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(quotes) != 2 || len(inner.batches) != 3 || strings.Join(inner.batches[1], ",") != "a" {
+		t.Fatalf("expected batch a retried, got quotes=%+v batches=%v", quotes, inner.batches)
+	}
+	if len(*waits) != 3 || (*waits)[1] < time.Second || (*waits)[1] > 2*time.Second {
+		t.Fatalf("expected retry to wait for Retry-After, got %v", *waits)
+	}
+}
+
+func TestLimitedProviderRetryTakesRequestSlot(t *testing.T) {
+	t.Parallel()
+
+	inner := &scriptedProvider{respond: func(call int, keys []string) ([]AssetQuote, error) {
+		if call == 1 {
+			return nil, &StatusError{Provider: "test", StatusCode: http.StatusBadGateway}
+		}
+		return priceAll(keys), nil
+	}}
+	limited, waits := newTestLimitedProvider(inner, Limits{RequestsPerMinute: 60})
+	limited.retry = RetryPolicy{MaxRetries: 2, BaseDelay: time.Second, MaxDelay: 4 * time.Second}
+
+	quotes, err := limited.FetchQuotes(context.Background(), []string{"a"})
+	if err != nil || len(quotes) != 1 || len(inner.batches) != 2 {
+		t.Fatalf("expected quotes after retry, got quotes=%+v batches=%v err=%v", quotes, inner.batches, err)
+	}
+	// Two reservations a second apart, with the backoff between them.
+	if len(*waits) != 3 || (*waits)[0] != 0 || (*waits)[1] < 500*time.Millisecond || (*waits)[1] > time.Second {
+		t.Fatalf("unexpected waits: %v", *waits)
+	}
+	if wait := limited.limiter.reserve(time.Now()); wait < 1500*time.Millisecond {
+		t.Fatalf("expected the retry to reserve a second slot, next slot in %v", wait)
+	}
+}
+
+func TestLimitedProviderStopsOnLongRateLimit(t *testing.T) {
+	t.Parallel()
+
+	inner := &scriptedProvider{respond: func(call int, keys []string) ([]AssetQuote, error) {
+		if call == 2 {
+			return nil, &StatusError{Provider: "test", StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour}
+		}
+		return priceAll(keys), nil
+	}}
+	limited, _ := newTestLimitedProvider(inner, Limits{BatchSize: 1})
+
+	quotes, err := limited.FetchQuotes(context.Background(), []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("expected partial results without error, got %v", err)
+	}
+	if len(quotes) != 1 || len(inner.batches) != 2 {
+		t.Fatalf("expected remaining batches skipped, got quotes=%+v batches=%v", quotes, inner.batches)
+	}
+	if wait := limited.limiter.reserve(time.Now()); wait < 59*time.Minute {
+		t.Fatalf("expected limiter paused for Retry-After, got %v", wait)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	if got := parseRetryAfter("120", now); got != 2*time.Minute {
+		t.Fatalf("expected 2m, got %v", got)
+	}
+	if got := parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now); got != 30*time.Second {
+		t.Fatalf("expected 30s, got %v", got)
+	}
+	if got := parseRetryAfter("soon", now); got != 0 {
+		t.Fatalf("expected 0 for invalid value, got %v", got)
+	}
+}
+
+func TestGetProviderBodyReturnsStatusError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte("slow down"))
+	}))
+	defer server.Close()
+
+	_, err := getProviderBody(context.Background(), server.Client(), "finnhub", server.URL, nil)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests || statusErr.RetryAfter != 7*time.Second {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	if err.Error() != "finnhub error: status 429: slow down" {
+		t.Fatalf("unexpected message: %q", err.Error())
+	}
+}
@@ -4,8 +4,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -13,7 +13,11 @@ import (
 	"time"
 )
 
-const mobulaDefaultBaseURL = "https://api.mobula.io"
+const (
+	mobulaDefaultBaseURL    = "https://api.mobula.io"
+	mobulaMaxBatchSize      = 100
+	mobulaRequestsPerMinute = 60
+)
 
 type MobulaProvider struct {
 	baseURL string
@@ -27,9 +31,12 @@ type mobulaMultiDataResponse struct {
 }
 
 type mobulaAssetData struct {
-	Key   string          `json:"key"`
-	ID    json.RawMessage `json:"id"`
-	Price float64         `json:"price"`
+	Key            string          `json:"key"`
+	ID             json.RawMessage `json:"id"`
+	Price          float64         `json:"price"`
+	PriceChange24h *float64        `json:"price_change_24h"`
+	Volume         *float64        `json:"volume"`
+	MarketCap      *float64        `json:"market_cap"`
 }
 
 func NewMobulaProvider(baseURL, apiKey string) *MobulaProvider {
@@ -47,6 +54,26 @@ func NewMobulaProvider(baseURL, apiKey string) *MobulaProvider {
 	}
 }
 
+// Limits caps each multi-data query at 100 keys.
+func (p *MobulaProvider) Limits() Limits {
+	return Limits{BatchSize: mobulaMaxBatchSize, RequestsPerMinute: mobulaRequestsPerMinute}
+}
+
+// SplitRequests puts numeric ids, names and each blockchain's addresses in
+// separate batches, since each takes its own multi-data request.
+func (p *MobulaProvider) SplitRequests(keys []string, batchSize int) [][]string {
+	numericIDs, assetNames, addressKeys := partitionMobulaLookupKeys(normalizeMobulaLookupKeys(keys))
+	batches := append(chunkStrings(numericIDs, batchSize), chunkStrings(assetNames, batchSize)...)
+	blockchains, keysByBlockchain := groupMobulaAddressKeys(addressKeys)
+	for _, blockchain := range blockchains {
+		batches = append(batches, chunkStrings(keysByBlockchain[blockchain], batchSize)...)
+	}
+	return batches
+}
+
+// FetchQuotes sends a request each for numeric ids, names and every
+// blockchain's addresses. When one fails, the quotes of the others are
+// returned with the joined errors; a 429 stops the remaining requests.
 func (p *MobulaProvider) FetchQuotes(ctx context.Context, lookupKeys []string) ([]AssetQuote, error) {
 	keys := normalizeMobulaLookupKeys(lookupKeys)
 	if len(keys) == 0 {
@@ -56,22 +83,26 @@ func (p *MobulaProvider) FetchQuotes(ctx context.Context, lookupKeys []string) (
 		return nil, fmt.Errorf("mobula api key is not set")
 	}
 
-	numericIDs, assetNames := partitionMobulaLookupKeys(keys)
+	numericIDs, assetNames, addressKeys := partitionMobulaLookupKeys(keys)
 
 	allRows := make([]mobulaAssetData, 0, len(keys))
-	if len(numericIDs) > 0 {
-		rows, err := p.fetchRows(ctx, "ids", numericIDs)
-		if err != nil {
-			return nil, err
-		}
+	var errs []error
+	stopped := false
+	collect := func(rows []mobulaAssetData, err error) {
 		allRows = append(allRows, rows...)
-	}
-	if len(assetNames) > 0 {
-		rows, err := p.fetchRows(ctx, "assets", assetNames)
 		if err != nil {
-			return nil, err
+			errs = append(errs, err)
+			stopped = ctx.Err() != nil || isRateLimited(err)
 		}
-		allRows = append(allRows, rows...)
+	}
+	if len(numericIDs) > 0 {
+		collect(p.fetchRows(ctx, url.Values{"ids": {strings.Join(numericIDs, ",")}}))
+	}
+	if len(assetNames) > 0 && !stopped {
+		collect(p.fetchRows(ctx, url.Values{"assets": {strings.Join(assetNames, ",")}}))
+	}
+	if len(addressKeys) > 0 && !stopped {
+		collect(p.fetchAddressRows(ctx, addressKeys))
 	}
 	quoteByLookup := make(map[string]AssetQuote, len(allRows))
 	for _, row := range allRows {
@@ -88,9 +119,12 @@ func (p *MobulaProvider) FetchQuotes(ctx context.Context, lookupKeys []string) (
 			continue
 		}
 		quoteByLookup[lookupKey] = AssetQuote{
-			LookupKey: lookupKey,
-			Price:     row.Price,
-			Provider:  "mobula",
+			LookupKey:    lookupKey,
+			Price:        row.Price,
+			Provider:     "mobula",
+			Change24hPct: row.PriceChange24h,
+			Volume24h:    row.Volume,
+			MarketCap:    row.MarketCap,
 		}
 	}
 
@@ -107,17 +141,70 @@ func (p *MobulaProvider) FetchQuotes(ctx context.Context, lookupKeys []string) (
 		quotes = append(quotes, quote)
 	}
 
-	return quotes, nil
+	return quotes, errors.Join(errs...)
 }
 
-func (p *MobulaProvider) fetchRows(ctx context.Context, queryParam string, lookupKeys []string) ([]mobulaAssetData, error) {
+// fetchAddressRows quotes tokens by contract and keys each row by its address
+// lookup key. Rows only carry the address, and a token can have the same
+// address on several chains, so each blockchain gets its own request.
+func (p *MobulaProvider) fetchAddressRows(ctx context.Context, lookupKeys []string) ([]mobulaAssetData, error) {
+	blockchains, keysByBlockchain := groupMobulaAddressKeys(lookupKeys)
+
+	var matched []mobulaAssetData
+	var errs []error
+	for _, blockchain := range blockchains {
+		keys := keysByBlockchain[blockchain]
+		addresses := make([]string, 0, len(keys))
+		keyByAddress := make(map[string]string, len(keys))
+		for _, key := range keys {
+			_, address, _ := parseAddressKey(key)
+			addresses = append(addresses, address)
+			keyByAddress[strings.ToLower(address)] = key
+		}
+
+		rows, err := p.fetchRows(ctx, url.Values{
+			"assets":      {strings.Join(addresses, ",")},
+			"blockchains": {blockchain},
+		})
+		if err != nil {
+			errs = append(errs, err)
+			if ctx.Err() != nil || isRateLimited(err) {
+				break
+			}
+			continue
+		}
+		for _, row := range rows {
+			key, ok := keyByAddress[strings.ToLower(strings.TrimSpace(row.Key))]
+			if !ok {
+				continue
+			}
+			row.Key = key
+			matched = append(matched, row)
+		}
+	}
+	return matched, errors.Join(errs...)
+}
+
+// groupMobulaAddressKeys groups address lookup keys by blockchain, in the
+// order the blockchains first appear.
+func groupMobulaAddressKeys(lookupKeys []string) ([]string, map[string][]string) {
+	var blockchains []string
+	keysByBlockchain := make(map[string][]string)
+	for _, key := range lookupKeys {
+		blockchain, _, _ := parseAddressKey(key)
+		if _, ok := keysByBlockchain[blockchain]; !ok {
+			blockchains = append(blockchains, blockchain)
+		}
+		keysByBlockchain[blockchain] = append(keysByBlockchain[blockchain], key)
+	}
+	return blockchains, keysByBlockchain
+}
+
+func (p *MobulaProvider) fetchRows(ctx context.Context, query url.Values) ([]mobulaAssetData, error) {
 	endpoint, err := url.Parse(p.baseURL + "/api/1/market/multi-data")
 	if err != nil {
 		return nil, err
 	}
-
-	query := endpoint.Query()
-	query.Set(queryParam, strings.Join(lookupKeys, ","))
 	endpoint.RawQuery = query.Encode()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
@@ -134,8 +221,7 @@ func (p *MobulaProvider) fetchRows(ctx context.Context, queryParam string, looku
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
-		return nil, fmt.Errorf("mobula error: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, newStatusError("mobula", resp)
 	}
 
 	var payload mobulaMultiDataResponse
@@ -243,23 +329,28 @@ func normalizeMobulaLookupKey(key string) string {
 	if key == "" {
 		return ""
 	}
-	if isMobulaNumericID(key) {
+	if _, _, ok := parseAddressKey(key); ok || isMobulaNumericID(key) {
 		return key
 	}
 	return strings.ToLower(key)
 }
 
-func partitionMobulaLookupKeys(keys []string) ([]string, []string) {
+func partitionMobulaLookupKeys(keys []string) ([]string, []string, []string) {
 	ids := make([]string, 0, len(keys))
 	assets := make([]string, 0, len(keys))
+	var addresses []string
 	for _, key := range keys {
 		if isMobulaNumericID(key) {
 			ids = append(ids, key)
 			continue
 		}
+		if _, _, ok := parseAddressKey(key); ok {
+			addresses = append(addresses, key)
+			continue
+		}
 		assets = append(assets, key)
 	}
-	return ids, assets
+	return ids, assets, addresses
 }
 
 func isMobulaNumericID(value string) bool {
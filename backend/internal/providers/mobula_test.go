@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -163,6 +164,153 @@ func TestMobulaProviderFetchQuotes_DataKeyedObject(t *testing.T) {
 	}
 }
 
+func TestMobulaProviderFetchQuotes_MarketData(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"bitcoin":{"price":64000,"price_change_24h":2.5,"volume":3.1e10,"market_cap":null}}}`))
+	}))
+	defer ts.Close()
+
+	p := NewMobulaProvider(ts.URL, "test-key")
+	quotes, err := p.FetchQuotes(context.Background(), []string{"bitcoin"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(quotes) != 1 || quotes[0].Change24hPct == nil || *quotes[0].Change24hPct != 2.5 || *quotes[0].Volume24h != 3.1e10 {
+		t.Fatalf("unexpected quote: %+v", quotes)
+	}
+	if quotes[0].MarketCap != nil || !quotes[0].AsOf.IsZero() {
+		t.Fatalf("expected no market cap or as-of time, got %+v", quotes[0])
+	}
+}
+
+func TestMobulaProviderFetchQuotes_ByAddress(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("ids") {
+			t.Fatalf("expected no ids query, got %q", r.URL.RawQuery)
+		}
+		if r.URL.Query().Get("assets") == "bitcoin" {
+			_, _ = w.Write([]byte(`{"data":{"bitcoin":{"price":64000}}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch query := r.URL.Query(); query.Get("blockchains") + "/" + query.Get("assets") {
+		case "ethereum/0xa0b8":
+			_, _ = w.Write([]byte(`{"data":{"0xA0B8":{"price":1.0001}}}`))
+		case "solana/DezXAZ8z7Pnr":
+			_, _ = w.Write([]byte(`{"data":{"DezXAZ8z7Pnr":{"price":0.00002}}}`))
+		default:
+			t.Fatalf("unexpected address query %q", r.URL.RawQuery)
+		}
+	}))
+	defer ts.Close()
+
+	p := NewMobulaProvider(ts.URL, "test-key")
+	quotes, err := p.FetchQuotes(context.Background(), []string{"bitcoin", "ethereum:0xa0b8", "solana:DezXAZ8z7Pnr"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(quotes) != 3 {
+		t.Fatalf("expected 3 quotes, got %+v", quotes)
+	}
+	if quotes[1].LookupKey != "ethereum:0xa0b8" || quotes[1].Price != 1.0001 {
+		t.Fatalf("unexpected ethereum quote: %+v", quotes[1])
+	}
+	if quotes[2].LookupKey != "solana:DezXAZ8z7Pnr" || quotes[2].Price != 0.00002 {
+		t.Fatalf("unexpected solana quote: %+v", quotes[2])
+	}
+}
+
+func TestMobulaProviderFetchQuotes_SameAddressOnTwoChains(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var requested []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		blockchain := r.URL.Query().Get("blockchains")
+		mu.Lock()
+		requested = append(requested, blockchain)
+		mu.Unlock()
+		if got := r.URL.Query().Get("assets"); got != "0xdac1" {
+			t.Fatalf("unexpected assets query %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch blockchain {
+		case "ethereum":
+			_, _ = w.Write([]byte(`{"data":{"0xDAC1":{"price":1.0}}}`))
+		case "bsc":
+			_, _ = w.Write([]byte(`{"data":{"0xDAC1":{"price":0.98}}}`))
+		default:
+			t.Fatalf("unexpected blockchains query %q", blockchain)
+		}
+	}))
+	defer ts.Close()
+
+	p := NewMobulaProvider(ts.URL, "test-key")
+	quotes, err := p.FetchQuotes(context.Background(), []string{"ethereum:0xdac1", "bsc:0xdac1"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(requested) != 2 {
+		t.Fatalf("expected one request per blockchain, got %v", requested)
+	}
+	if len(quotes) != 2 {
+		t.Fatalf("expected 2 quotes, got %+v", quotes)
+	}
+	if quotes[0].LookupKey != "ethereum:0xdac1" || quotes[0].Price != 1.0 {
+		t.Fatalf("unexpected ethereum quote: %+v", quotes[0])
+	}
+	if quotes[1].LookupKey != "bsc:0xdac1" || quotes[1].Price != 0.98 {
+		t.Fatalf("unexpected bsc quote: %+v", quotes[1])
+	}
+}
+
+func TestMobulaProviderLimiterReservesEveryRequest(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var requested []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		mu.Lock()
+		requested = append(requested, query.Get("ids")+query.Get("blockchains"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case query.Get("blockchains") == "bsc":
+			http.Error(w, "upstream exploded", http.StatusBadGateway)
+		case query.Get("ids") != "":
+			_, _ = w.Write([]byte(`{"data":{"1":{"price":64000}}}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":{"0xDAC1":{"price":1.0}}}`))
+		}
+	}))
+	defer ts.Close()
+
+	p := NewMobulaProvider(ts.URL, "test-key")
+	limited, waits := newTestLimitedProvider(p, p.Limits())
+	quotes, err := limited.FetchQuotes(context.Background(), []string{"1", "ethereum:0xdac1", "bsc:0xdac1"})
+	if err != nil {
+		t.Fatalf("expected partial results without error, got %v", err)
+	}
+	if len(requested) != 3 || len(*waits) != 3 {
+		t.Fatalf("expected a limiter slot per request, got requests=%v reservations=%d", requested, len(*waits))
+	}
+	if len(quotes) != 2 || quotes[0].LookupKey != "1" || quotes[1].LookupKey != "ethereum:0xdac1" {
+		t.Fatalf("unexpected quotes: %+v", quotes)
+	}
+
+	// Called directly, the failed blockchain's error comes with the other quotes.
+	quotes, err = p.FetchQuotes(context.Background(), []string{"1", "ethereum:0xdac1", "bsc:0xdac1"})
+	if err == nil || !strings.Contains(err.Error(), "status 502") || len(quotes) != 2 {
+		t.Fatalf("expected partial quotes and the joined error, got quotes=%+v err=%v", quotes, err)
+	}
+}
+
 func TestMobulaProviderFetchQuotes_Non200(t *testing.T) {
 	t.Parallel()
 
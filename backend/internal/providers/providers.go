@@ -1,11 +1,39 @@
 package providers
 
-import "context"
+import (
+	"context"
+	"strings"
+	"time"
+)
 
 type AssetQuote struct {
 	LookupKey string
 	Price     float64
 	Provider  string
+	// Change24hPct, Volume24h and MarketCap are nil unless the provider
+	// reported them; AsOf is the provider's last-updated time, zero if unknown.
+	Change24hPct *float64
+	Volume24h    *float64
+	MarketCap    *float64
+	AsOf         time.Time
+	// Disagreement is set by QuorumProvider when a provider's price strayed
+	// from the others.
+	Disagreement *QuoteDisagreement
+}
+
+// QuoteDisagreement lists every provider's price for a key. When Rejected,
+// no majority agreed and the quote's Price must not be stored.
+type QuoteDisagreement struct {
+	Median    float64
+	Tolerance float64
+	Prices    []ProviderPrice
+	Rejected  bool
+}
+
+type ProviderPrice struct {
+	Provider string
+	Price    float64
+	Outlier  bool
 }
 
 type StockProvider interface {
@@ -15,3 +43,24 @@ type StockProvider interface {
 type CryptoProvider interface {
 	FetchQuotes(ctx context.Context, lookupKeys []string) ([]AssetQuote, error)
 }
+
+// AddressLookupKey is the lookup key of a token identified by its contract:
+// "<blockchain>:<address>". The blockchain is lowercased, and so are 0x
+// addresses; other addresses, such as Solana's, are case-sensitive.
+func AddressLookupKey(blockchain, address string) string {
+	address = strings.TrimSpace(address)
+	if strings.HasPrefix(strings.ToLower(address), "0x") {
+		address = strings.ToLower(address)
+	}
+	return strings.ToLower(strings.TrimSpace(blockchain)) + ":" + address
+}
+
+// parseAddressKey splits a key made by AddressLookupKey. Addresses have no
+// spaces, which tells them apart from asset names such as "Foo: Bar".
+func parseAddressKey(key string) (blockchain, address string, ok bool) {
+	blockchain, address, ok = strings.Cut(strings.TrimSpace(key), ":")
+	if !ok || strings.TrimSpace(blockchain) != blockchain || blockchain == "" || address == "" || strings.ContainsAny(address, " \t") {
+		return "", "", false
+	}
+	return blockchain, address, true
+}
@@ -0,0 +1,189 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"asset-tracker/internal/telemetry"
+)
+
+// QuorumProvider asks every link for every key and prices each key at the
+// median of the quotes that agree. A quote agrees when it is within tolerance
+// (a fraction) of the median of all quotes for the key. Keys with fewer than
+// minQuotes agreeing quotes, or where agreeing quotes are not a strict
+// majority, come back rejected so the caller keeps the last good price.
+type QuorumProvider struct {
+	links     []ChainLink
+	tolerance float64
+	minQuotes int
+}
+
+func NewQuorumProvider(tolerance float64, minQuotes int, links ...ChainLink) *QuorumProvider {
+	if minQuotes < 1 {
+		minQuotes = 1
+	}
+	return &QuorumProvider{links: links, tolerance: tolerance, minQuotes: minQuotes}
+}
+
+// FetchQuotes returns one quote per key that any link priced. Link errors are
+// logged and only returned, joined, when every link failed.
+func (p *QuorumProvider) FetchQuotes(ctx context.Context, lookupKeys []string) ([]AssetQuote, error) {
+	keys := make([]string, 0, len(lookupKeys))
+	requested := make(map[string]string, len(lookupKeys))
+	for _, key := range lookupKeys {
+		normalized := strings.ToLower(strings.TrimSpace(key))
+		if normalized == "" {
+			continue
+		}
+		if _, ok := requested[normalized]; ok {
+			continue
+		}
+		requested[normalized] = key
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	results := make([][]AssetQuote, len(p.links))
+	errs := make([]error, len(p.links))
+	var wg sync.WaitGroup
+	for i, link := range p.links {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			quotes, err := link.Provider.FetchQuotes(ctx, keys)
+			telemetry.ProviderFetch(link.Name, len(keys), len(quotes), err)
+			if err != nil {
+				slog.Warn("quote provider failed", "provider", link.Name, "keys", len(keys), "error", err)
+				errs[i] = fmt.Errorf("%s: %w", link.Name, err)
+				return
+			}
+			results[i] = quotes
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed == len(p.links) {
+		return nil, errors.Join(errs...)
+	}
+
+	pricesByKey := make(map[string][]ProviderPrice, len(keys))
+	quotesByKey := make(map[string][]AssetQuote, len(keys))
+	for i, quotes := range results {
+		seen := make(map[string]struct{}, len(quotes))
+		for _, quote := range quotes {
+			normalized := strings.ToLower(strings.TrimSpace(quote.LookupKey))
+			if _, ok := requested[normalized]; !ok || quote.Price <= 0 {
+				continue
+			}
+			if _, ok := seen[normalized]; ok {
+				continue
+			}
+			seen[normalized] = struct{}{}
+			provider := quote.Provider
+			if provider == "" {
+				provider = p.links[i].Name
+			}
+			pricesByKey[normalized] = append(pricesByKey[normalized], ProviderPrice{Provider: provider, Price: quote.Price})
+			quotesByKey[normalized] = append(quotesByKey[normalized], quote)
+		}
+	}
+
+	quotes := make([]AssetQuote, 0, len(pricesByKey))
+	for _, key := range keys {
+		normalized := strings.ToLower(strings.TrimSpace(key))
+		prices, ok := pricesByKey[normalized]
+		if !ok {
+			continue
+		}
+		quotes = append(quotes, p.resolve(key, prices, quotesByKey[normalized]))
+	}
+	return quotes, nil
+}
+
+// FetchHistory is not cross-checked: it returns the history of the first
+// link that has any, like a chain.
+func (p *QuorumProvider) FetchHistory(ctx context.Context, lookupKey string, from, to time.Time) ([]HistoricalPrice, error) {
+	return historyFromLinks(ctx, p.links, lookupKey, from, to)
+}
+
+func (p *QuorumProvider) supportsHistory() bool {
+	return linksSupportHistory(p.links)
+}
+
+// resolve prices a key from every provider's quote, in the same order as
+// prices. Market data comes from the first agreeing quote that has any.
+func (p *QuorumProvider) resolve(key string, prices []ProviderPrice, sources []AssetQuote) AssetQuote {
+	all := make([]float64, 0, len(prices))
+	for _, price := range prices {
+		all = append(all, price.Price)
+	}
+	median := medianOf(all)
+
+	var agreeing []float64
+	var providers []string
+	var details *AssetQuote
+	outliers := 0
+	for i := range prices {
+		if math.Abs(prices[i].Price-median)/median > p.tolerance {
+			prices[i].Outlier = true
+			outliers++
+			continue
+		}
+		agreeing = append(agreeing, prices[i].Price)
+		providers = append(providers, prices[i].Provider)
+		if details == nil && hasMarketData(sources[i]) {
+			details = &sources[i]
+		}
+	}
+
+	quote := AssetQuote{LookupKey: key, Price: median}
+	if len(agreeing) >= p.minQuotes && len(agreeing)*2 > len(prices) {
+		quote.Price = medianOf(agreeing)
+		quote.Provider = strings.Join(providers, "+")
+		if details != nil {
+			quote.Change24hPct = details.Change24hPct
+			quote.Volume24h = details.Volume24h
+			quote.MarketCap = details.MarketCap
+			quote.AsOf = details.AsOf
+		}
+		if outliers == 0 {
+			return quote
+		}
+	}
+	quote.Disagreement = &QuoteDisagreement{
+		Median:    median,
+		Tolerance: p.tolerance,
+		Prices:    prices,
+		Rejected:  quote.Provider == "",
+	}
+	return quote
+}
+
+func hasMarketData(quote AssetQuote) bool {
+	return quote.Change24hPct != nil || quote.Volume24h != nil || quote.MarketCap != nil || !quote.AsOf.IsZero()
+}
+
+func medianOf(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle]
+	}
+	return (sorted[middle-1] + sorted[middle]) / 2
+}
@@ -0,0 +1,149 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestQuorumProviderTakesMedianOfAgreeingQuotes(t *testing.T) {
+	t.Parallel()
+
+	quorum := NewQuorumProvider(0.05, 2,
+		ChainLink{Name: "mobula", Provider: &stubProvider{name: "mobula", prices: map[string]float64{"bitcoin": 64000}}},
+		ChainLink{Name: "coingecko", Provider: &stubProvider{name: "coingecko", prices: map[string]float64{"bitcoin": 64200}}},
+		ChainLink{Name: "http", Provider: &stubProvider{name: "http", prices: map[string]float64{"bitcoin": 63900}}},
+	)
+
+	quotes, err := quorum.FetchQuotes(context.Background(), []string{"bitcoin"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(quotes) != 1 || quotes[0].Price != 64000 || quotes[0].Disagreement != nil {
+		t.Fatalf("unexpected quotes: %+v", quotes)
+	}
+	if quotes[0].Provider != "mobula+coingecko+http" {
+		t.Fatalf("unexpected provider: %q", quotes[0].Provider)
+	}
+}
+
+func TestQuorumProviderFlagsOutlierAndKeepsMajority(t *testing.T) {
+	t.Parallel()
+
+	quorum := NewQuorumProvider(0.05, 2,
+		ChainLink{Name: "mobula", Provider: &stubProvider{name: "mobula", prices: map[string]float64{"bitcoin": 64000}}},
+		ChainLink{Name: "coingecko", Provider: &stubProvider{name: "coingecko", prices: map[string]float64{"bitcoin": 6400}}},
+		ChainLink{Name: "http", Provider: &stubProvider{name: "http", prices: map[string]float64{"bitcoin": 64400}}},
+	)
+
+	quotes, err := quorum.FetchQuotes(context.Background(), []string{"bitcoin"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(quotes) != 1 || quotes[0].Price != 64200 || quotes[0].Provider != "mobula+http" {
+		t.Fatalf("unexpected quotes: %+v", quotes)
+	}
+	disagreement := quotes[0].Disagreement
+	if disagreement == nil || disagreement.Rejected || disagreement.Median != 64000 {
+		t.Fatalf("expected accepted disagreement, got %+v", disagreement)
+	}
+	if len(disagreement.Prices) != 3 || !disagreement.Prices[1].Outlier || disagreement.Prices[0].Outlier {
+		t.Fatalf("expected coingecko flagged as outlier, got %+v", disagreement.Prices)
+	}
+}
+
+func TestQuorumProviderRejectsWithoutMajority(t *testing.T) {
+	t.Parallel()
+
+	quorum := NewQuorumProvider(0.05, 1,
+		ChainLink{Name: "mobula", Provider: &stubProvider{name: "mobula", prices: map[string]float64{"bitcoin": 64000}}},
+		ChainLink{Name: "coingecko", Provider: &stubProvider{name: "coingecko", prices: map[string]float64{"bitcoin": 50000}}},
+	)
+
+	quotes, err := quorum.FetchQuotes(context.Background(), []string{"bitcoin"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(quotes) != 1 || quotes[0].Provider != "" || quotes[0].Disagreement == nil || !quotes[0].Disagreement.Rejected {
+		t.Fatalf("expected rejected quote, got %+v", quotes)
+	}
+	if quotes[0].Disagreement.Median != 57000 {
+		t.Fatalf("unexpected median: %v", quotes[0].Disagreement.Median)
+	}
+}
+
+func TestQuorumProviderRequiresMinQuotes(t *testing.T) {
+	t.Parallel()
+
+	quorum := NewQuorumProvider(0.05, 2,
+		ChainLink{Name: "mobula", Provider: &stubProvider{name: "mobula", prices: map[string]float64{"bitcoin": 64000, "pepe": 0.00001}}},
+		ChainLink{Name: "coingecko", Provider: &stubProvider{name: "coingecko", prices: map[string]float64{"bitcoin": 64100}}},
+	)
+
+	quotes, err := quorum.FetchQuotes(context.Background(), []string{"bitcoin", "pepe"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(quotes) != 2 || quotes[0].Disagreement != nil || quotes[0].Price != 64050 {
+		t.Fatalf("unexpected bitcoin quote: %+v", quotes)
+	}
+	if quotes[1].LookupKey != "pepe" || quotes[1].Disagreement == nil || !quotes[1].Disagreement.Rejected {
+		t.Fatalf("expected pepe rejected with a single quote, got %+v", quotes[1])
+	}
+}
+
+func TestQuorumProviderIgnoresFailingLink(t *testing.T) {
+	t.Parallel()
+
+	quorum := NewQuorumProvider(0.05, 1,
+		ChainLink{Name: "mobula", Provider: &stubProvider{err: errors.New("status 502")}},
+		ChainLink{Name: "coingecko", Provider: &stubProvider{name: "coingecko", prices: map[string]float64{"bitcoin": 64000}}},
+	)
+
+	quotes, err := quorum.FetchQuotes(context.Background(), []string{"bitcoin"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(quotes) != 1 || quotes[0].Provider != "coingecko" || quotes[0].Disagreement != nil {
+		t.Fatalf("unexpected quotes: %+v", quotes)
+	}
+}
+
+func TestQuorumProviderReturnsErrorWhenEveryLinkFails(t *testing.T) {
+	t.Parallel()
+
+	quorum := NewQuorumProvider(0.05, 1,
+		ChainLink{Name: "mobula", Provider: &stubProvider{err: errors.New("status 502")}},
+		ChainLink{Name: "coingecko", Provider: &stubProvider{err: errors.New("status 429")}},
+	)
+
+	quotes, err := quorum.FetchQuotes(context.Background(), []string{"bitcoin"})
+	if err == nil || quotes != nil {
+		t.Fatalf("expected joined error, got quotes=%+v err=%v", quotes, err)
+	}
+}
+
+func TestQuorumProviderKeepsMarketDataOfAgreeingQuote(t *testing.T) {
+	t.Parallel()
+
+	change := 1.5
+	outlierChange := -40.0
+	withChange := func(price float64, change *float64) *scriptedProvider {
+		return &scriptedProvider{respond: func(call int, keys []string) ([]AssetQuote, error) {
+			return []AssetQuote{{LookupKey: keys[0], Price: price, Change24hPct: change}}, nil
+		}}
+	}
+	quorum := NewQuorumProvider(0.05, 2,
+		ChainLink{Name: "http", Provider: withChange(6400, &outlierChange)},
+		ChainLink{Name: "mobula", Provider: withChange(64000, nil)},
+		ChainLink{Name: "coingecko", Provider: withChange(64100, &change)},
+	)
+
+	quotes, err := quorum.FetchQuotes(context.Background(), []string{"bitcoin"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(quotes) != 1 || quotes[0].Change24hPct == nil || *quotes[0].Change24hPct != 1.5 {
+		t.Fatalf("expected market data from coingecko, got %+v", quotes)
+	}
+}
@@ -0,0 +1,222 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"asset-tracker/internal/telemetry"
+)
+
+// ErrCircuitOpen is returned, without calling the provider, while its circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("provider circuit breaker is open")
+
+// RetryPolicy bounds the retries of a RetryProvider or LimitedProvider. The delay before retry n
+// is BaseDelay doubled n times, capped at MaxDelay, with up to half of it
+// jittered off.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// BreakerSettings opens a circuit breaker after Failures calls in a row fail
+// and lets one probe through after Cooldown.
+type BreakerSettings struct {
+	Failures int
+	Cooldown time.Duration
+}
+
+var (
+	DefaultRetryPolicy     = RetryPolicy{MaxRetries: 2, BaseDelay: 500 * time.Millisecond, MaxDelay: 4 * time.Second}
+	DefaultBreakerSettings = BreakerSettings{Failures: 5, Cooldown: time.Minute}
+)
+
+// RetryProvider retries FetchQuotes and FetchHistory after transient failures: network
+// errors, 408 and 5xx. Provider calls are GETs, so repeating them is safe.
+type RetryProvider struct {
+	name     string
+	provider QuoteProvider
+	policy   RetryPolicy
+	sleep    func(ctx context.Context, d time.Duration) error
+}
+
+func NewRetryProvider(name string, provider QuoteProvider, policy RetryPolicy) *RetryProvider {
+	return &RetryProvider{name: name, provider: provider, policy: policy, sleep: sleepContext}
+}
+
+func (p *RetryProvider) FetchQuotes(ctx context.Context, lookupKeys []string) ([]AssetQuote, error) {
+	return retryCall(ctx, p, func() ([]AssetQuote, error) { return p.provider.FetchQuotes(ctx, lookupKeys) })
+}
+
+func (p *RetryProvider) FetchHistory(ctx context.Context, lookupKey string, from, to time.Time) ([]HistoricalPrice, error) {
+	if !supportsHistory(p.provider) {
+		return nil, ErrHistoryUnsupported
+	}
+	return retryCall(ctx, p, func() ([]HistoricalPrice, error) { return fetchHistory(ctx, p.provider, lookupKey, from, to) })
+}
+
+func (p *RetryProvider) supportsHistory() bool {
+	return supportsHistory(p.provider)
+}
+
+func retryCall[T any](ctx context.Context, p *RetryProvider, call func() (T, error)) (T, error) {
+	for attempt := 0; ; attempt++ {
+		result, err := call()
+		if err == nil || attempt >= p.policy.MaxRetries || ctx.Err() != nil || !isTransient(err) {
+			return result, err
+		}
+
+		telemetry.ProviderRetry(p.name)
+		slog.Warn("retrying quote provider", "provider", p.name, "attempt", attempt+1, "error", err)
+		if err := p.sleep(ctx, p.policy.backoff(attempt)); err != nil {
+			var zero T
+			return zero, err
+		}
+	}
+}
+
+func (policy RetryPolicy) backoff(attempt int) time.Duration {
+	delay := policy.BaseDelay << attempt
+	if delay <= 0 || delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+	if delay <= 1 {
+		return delay
+	}
+	return delay - rand.N(delay/2)
+}
+
+func isTransient(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusRequestTimeout || statusErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+type breakerState string
+
+const (
+	breakerClosed   breakerState = "closed"
+	breakerOpen     breakerState = "open"
+	breakerHalfOpen breakerState = "half_open"
+)
+
+// BreakerProvider stops calling a provider that keeps failing. While open it
+// fails fast with ErrCircuitOpen, so a chain moves on to the next provider.
+// After the cooldown it is half-open: one call probes the provider and its
+// outcome closes or reopens the breaker.
+type BreakerProvider struct {
+	name     string
+	provider QuoteProvider
+	settings BreakerSettings
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewBreakerProvider(name string, provider QuoteProvider, settings BreakerSettings) *BreakerProvider {
+	if settings.Failures < 1 {
+		settings.Failures = 1
+	}
+	telemetry.ProviderBreakerState(name, string(breakerClosed))
+	return &BreakerProvider{name: name, provider: provider, settings: settings, now: time.Now, state: breakerClosed}
+}
+
+func (p *BreakerProvider) FetchQuotes(ctx context.Context, lookupKeys []string) ([]AssetQuote, error) {
+	return breakerCall(ctx, p, func() ([]AssetQuote, error) { return p.provider.FetchQuotes(ctx, lookupKeys) })
+}
+
+func (p *BreakerProvider) FetchHistory(ctx context.Context, lookupKey string, from, to time.Time) ([]HistoricalPrice, error) {
+	if !supportsHistory(p.provider) {
+		return nil, ErrHistoryUnsupported
+	}
+	return breakerCall(ctx, p, func() ([]HistoricalPrice, error) { return fetchHistory(ctx, p.provider, lookupKey, from, to) })
+}
+
+func (p *BreakerProvider) supportsHistory() bool {
+	return supportsHistory(p.provider)
+}
+
+func breakerCall[T any](ctx context.Context, p *BreakerProvider, call func() (T, error)) (T, error) {
+	if !p.allow() {
+		var zero T
+		return zero, ErrCircuitOpen
+	}
+	result, err := call()
+	p.record(ctx, err)
+	return result, err
+}
+
+func (p *BreakerProvider) allow() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch p.state {
+	case breakerOpen:
+		if p.now().Sub(p.openedAt) < p.settings.Cooldown {
+			return false
+		}
+		p.setState(breakerHalfOpen)
+		p.probing = true
+		return true
+	case breakerHalfOpen:
+		if p.probing {
+			return false
+		}
+		p.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record updates the breaker with a call's outcome. Calls cut short by the
+// caller's context say nothing about the provider and are not counted.
+func (p *BreakerProvider) record(ctx context.Context, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.state == breakerHalfOpen {
+		p.probing = false
+	}
+	switch {
+	case err != nil && ctx.Err() != nil:
+		return
+	case err == nil:
+		p.failures = 0
+		if p.state != breakerClosed {
+			p.setState(breakerClosed)
+		}
+	case p.state == breakerHalfOpen:
+		p.open()
+	default:
+		p.failures++
+		if p.failures >= p.settings.Failures {
+			p.open()
+		}
+	}
+}
+
+func (p *BreakerProvider) open() {
+	p.openedAt = p.now()
+	p.failures = 0
+	p.setState(breakerOpen)
+	slog.Warn("quote provider circuit opened", "provider", p.name, "cooldown", p.settings.Cooldown)
+}
+
+func (p *BreakerProvider) setState(state breakerState) {
+	p.state = state
+	telemetry.ProviderBreakerState(p.name, string(state))
+}
@@ -0,0 +1,153 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryProviderRetriesTransientFailures(t *testing.T) {
+	t.Parallel()
+
+	inner := &scriptedProvider{respond: func(call int, keys []string) ([]AssetQuote, error) {
+		if call == 1 {
+			return nil, &StatusError{Provider: "mobula", StatusCode: http.StatusBadGateway}
+		}
+		return priceAll(keys), nil
+	}}
+	retry := NewRetryProvider("mobula", inner, RetryPolicy{MaxRetries: 2, BaseDelay: time.Second, MaxDelay: 4 * time.Second})
+	var waits []time.Duration
+	retry.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	quotes, err := retry.FetchQuotes(context.Background(), []string{"bitcoin"})
+	if err != nil || len(quotes) != 1 {
+		t.Fatalf("expected quotes after retry, got quotes=%+v err=%v", quotes, err)
+	}
+	if len(inner.batches) != 2 || len(waits) != 1 || waits[0] < 500*time.Millisecond || waits[0] > time.Second {
+		t.Fatalf("unexpected retry: calls=%d waits=%v", len(inner.batches), waits)
+	}
+}
+
+func TestRetryProviderGivesUpAfterMaxRetries(t *testing.T) {
+	t.Parallel()
+
+	inner := &scriptedProvider{respond: func(call int, keys []string) ([]AssetQuote, error) {
+		return nil, &StatusError{Provider: "mobula", StatusCode: http.StatusServiceUnavailable}
+	}}
+	retry := NewRetryProvider("mobula", inner, RetryPolicy{MaxRetries: 2, BaseDelay: time.Second, MaxDelay: 4 * time.Second})
+	var waits []time.Duration
+	retry.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	if _, err := retry.FetchQuotes(context.Background(), []string{"bitcoin"}); err == nil {
+		t.Fatal("expected error after retries, got nil")
+	}
+	if len(inner.batches) != 3 || len(waits) != 2 || waits[1] < time.Second || waits[1] > 2*time.Second {
+		t.Fatalf("expected 3 calls with backoff, got calls=%d waits=%v", len(inner.batches), waits)
+	}
+}
+
+func TestRetryProviderDoesNotRetryPermanentFailures(t *testing.T) {
+	t.Parallel()
+
+	inner := &scriptedProvider{respond: func(call int, keys []string) ([]AssetQuote, error) {
+		return nil, &StatusError{Provider: "mobula", StatusCode: http.StatusUnauthorized}
+	}}
+	retry := NewRetryProvider("mobula", inner, DefaultRetryPolicy)
+
+	if _, err := retry.FetchQuotes(context.Background(), []string{"bitcoin"}); err == nil || len(inner.batches) != 1 {
+		t.Fatalf("expected a single failed call, got calls=%d err=%v", len(inner.batches), err)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{&StatusError{StatusCode: http.StatusBadGateway}, true},
+		{&StatusError{StatusCode: http.StatusRequestTimeout}, true},
+		{&StatusError{StatusCode: http.StatusTooManyRequests}, false},
+		{&StatusError{StatusCode: http.StatusNotFound}, false},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{io.ErrUnexpectedEOF, true},
+		{errors.New("unsupported mobula data shape"), false},
+	}
+	for _, tc := range cases {
+		if got := isTransient(tc.err); got != tc.want {
+			t.Fatalf("isTransient(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestBreakerProviderOpensAndProbesHalfOpen(t *testing.T) {
+	t.Parallel()
+
+	fail := true
+	inner := &scriptedProvider{respond: func(call int, keys []string) ([]AssetQuote, error) {
+		if fail {
+			return nil, errors.New("status 502")
+		}
+		return priceAll(keys), nil
+	}}
+	breaker := NewBreakerProvider("breaker-test", inner, BreakerSettings{Failures: 2, Cooldown: time.Minute})
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	breaker.now = func() time.Time { return now }
+	state := func() string {
+		return expvar.Get("provider_breaker_state").(*expvar.Map).Get("breaker-test").String()
+	}
+
+	for range 2 {
+		_, _ = breaker.FetchQuotes(context.Background(), []string{"bitcoin"})
+	}
+	if _, err := breaker.FetchQuotes(context.Background(), []string{"bitcoin"}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected open circuit, got %v", err)
+	}
+	if len(inner.batches) != 2 || state() != `"open"` {
+		t.Fatalf("expected provider skipped while open, got calls=%d state=%s", len(inner.batches), state())
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := breaker.FetchQuotes(context.Background(), []string{"bitcoin"}); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected failed probe, got %v", err)
+	}
+	if _, err := breaker.FetchQuotes(context.Background(), []string{"bitcoin"}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected circuit reopened after failed probe, got %v", err)
+	}
+
+	fail = false
+	now = now.Add(time.Minute)
+	quotes, err := breaker.FetchQuotes(context.Background(), []string{"bitcoin"})
+	if err != nil || len(quotes) != 1 || state() != `"closed"` {
+		t.Fatalf("expected successful probe to close the circuit, got quotes=%+v err=%v state=%s", quotes, err, state())
+	}
+}
+
+func TestBreakerProviderIgnoresCanceledCalls(t *testing.T) {
+	t.Parallel()
+
+	inner := &scriptedProvider{respond: func(call int, keys []string) ([]AssetQuote, error) {
+		return nil, context.Canceled
+	}}
+	breaker := NewBreakerProvider("breaker-cancel-test", inner, BreakerSettings{Failures: 1, Cooldown: time.Minute})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _ = breaker.FetchQuotes(ctx, []string{"bitcoin"})
+	_, err := breaker.FetchQuotes(ctx, []string{"bitcoin"})
+	if errors.Is(err, ErrCircuitOpen) || len(inner.batches) != 2 {
+		t.Fatalf("expected canceled calls not to open the circuit, got calls=%d err=%v", len(inner.batches), err)
+	}
+}
@@ -0,0 +1,72 @@
+package providers
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+)
+
+// simulatedVolatility is the standard deviation of one step's log return.
+const simulatedVolatility = 0.01
+
+// SimulatedProvider prices any key with a random walk, for running the stack
+// without network or API keys. Each key has its own walk, seeded by the
+// provider seed and the key, and every fetch moves it one step, so the same
+// seed and sequence of fetches give the same prices.
+type SimulatedProvider struct {
+	seed int64
+	now  func() time.Time
+
+	mu    sync.Mutex
+	walks map[string]*simulatedWalk
+}
+
+type simulatedWalk struct {
+	rng   *rand.Rand
+	price float64
+}
+
+func NewSimulatedProvider(seed int64) *SimulatedProvider {
+	return &SimulatedProvider{seed: seed, now: time.Now, walks: make(map[string]*simulatedWalk)}
+}
+
+func (p *SimulatedProvider) FetchQuotes(ctx context.Context, lookupKeys []string) ([]AssetQuote, error) {
+	keys := normalizeLookupKeys(lookupKeys)
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	asOf := p.now().UTC()
+	quotes := make([]AssetQuote, 0, len(keys))
+	for _, key := range keys {
+		quotes = append(quotes, AssetQuote{
+			LookupKey: key,
+			Price:     p.step(strings.ToLower(key)),
+			Provider:  "simulated",
+			AsOf:      asOf,
+		})
+	}
+	return quotes, nil
+}
+
+// step moves the key's walk and returns its new price. A walk starts between
+// 1 and 10,000.
+func (p *SimulatedProvider) step(key string) float64 {
+	walk, ok := p.walks[key]
+	if !ok {
+		hash := fnv.New64a()
+		_, _ = hash.Write([]byte(key))
+		rng := rand.New(rand.NewPCG(uint64(p.seed), hash.Sum64()))
+		walk = &simulatedWalk{rng: rng, price: math.Pow(10, rng.Float64()*4)}
+		p.walks[key] = walk
+		return walk.price
+	}
+	walk.price *= math.Exp(walk.rng.NormFloat64() * simulatedVolatility)
+	return walk.price
+}
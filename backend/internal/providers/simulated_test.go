@@ -0,0 +1,63 @@
+package providers
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSimulatedProviderIsDeterministicPerSeed(t *testing.T) {
+	t.Parallel()
+
+	walk := func(seed int64, keys []string) []float64 {
+		p := NewSimulatedProvider(seed)
+		var prices []float64
+		for range 3 {
+			quotes, err := p.FetchQuotes(context.Background(), keys)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			for _, quote := range quotes {
+				if quote.Price <= 0 || quote.Provider != "simulated" || quote.AsOf.IsZero() {
+					t.Fatalf("unexpected quote: %+v", quote)
+				}
+				prices = append(prices, quote.Price)
+			}
+		}
+		return prices
+	}
+
+	first := walk(42, []string{"bitcoin", "AAPL"})
+	second := walk(42, []string{"bitcoin", "AAPL"})
+	if len(first) != 6 {
+		t.Fatalf("expected 6 prices, got %v", first)
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("expected the same walk for the same seed, got %v and %v", first, second)
+		}
+	}
+	if first[0] == first[2] || first[0] == first[1] {
+		t.Fatalf("expected the walk to move and keys to differ, got %v", first)
+	}
+	if other := walk(7, []string{"bitcoin", "AAPL"}); other[0] == first[0] {
+		t.Fatalf("expected another seed to give other prices, got %v", other)
+	}
+	if alone := walk(42, []string{"bitcoin"}); alone[1] != first[2] {
+		t.Fatalf("expected a key's walk not to depend on other keys, got %v and %v", alone, first)
+	}
+}
+
+func TestSimulatedProviderMatchesKeysCaseInsensitively(t *testing.T) {
+	t.Parallel()
+
+	p := NewSimulatedProvider(1)
+	quotes, err := p.FetchQuotes(context.Background(), []string{"Bitcoin", "bitcoin", " "})
+	if err != nil || len(quotes) != 1 || quotes[0].LookupKey != "Bitcoin" {
+		t.Fatalf("unexpected quotes: %+v err=%v", quotes, err)
+	}
+	reference := NewSimulatedProvider(1)
+	again, _ := reference.FetchQuotes(context.Background(), []string{"BITCOIN"})
+	if again[0].Price != quotes[0].Price {
+		t.Fatalf("expected the same walk for any case, got %v and %v", again[0].Price, quotes[0].Price)
+	}
+}
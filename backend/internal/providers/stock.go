@@ -0,0 +1,75 @@
+package providers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// classShareSymbol matches a class-share symbol (BRK.B, BRK-B, BRK/B).
+// Longer suffixes such as RY.TO, and the single-letter exchange suffixes in
+// exchangeSuffixes (VOD.L), name an exchange and are left alone.
+var classShareSymbol = regexp.MustCompile(`^([A-Z]+)[.\-/]([A-Z])$`)
+
+var exchangeSuffixes = map[string]bool{"F": true, "L": true, "T": true, "V": true}
+
+// stockSymbols uppercases and dedupes lookup keys and rewrites the class-share
+// separator to the provider's. It returns the provider symbols in order and,
+// for each, the lookup keys that map to it.
+func stockSymbols(lookupKeys []string, classSeparator string) ([]string, map[string][]string) {
+	symbols := make([]string, 0, len(lookupKeys))
+	keysBySymbol := make(map[string][]string, len(lookupKeys))
+	for _, key := range lookupKeys {
+		trimmed := strings.TrimSpace(key)
+		if trimmed == "" {
+			continue
+		}
+		symbol := strings.ToUpper(trimmed)
+		if match := classShareSymbol.FindStringSubmatch(symbol); match != nil && !exchangeSuffixes[match[2]] {
+			symbol = match[1] + classSeparator + match[2]
+		}
+		if _, ok := keysBySymbol[symbol]; !ok {
+			symbols = append(symbols, symbol)
+		}
+		if !slices.Contains(keysBySymbol[symbol], trimmed) {
+			keysBySymbol[symbol] = append(keysBySymbol[symbol], trimmed)
+		}
+	}
+	return symbols, keysBySymbol
+}
+
+func chunkStrings(values []string, size int) [][]string {
+	chunks := make([][]string, 0, (len(values)+size-1)/size)
+	for start := 0; start < len(values); start += size {
+		chunks = append(chunks, values[start:min(start+size, len(values))])
+	}
+	return chunks
+}
+
+// getProviderBody sends a GET and returns the body of a 2xx response; other
+// statuses become an error naming the provider.
+func getProviderBody(ctx context.Context, client *http.Client, provider string, endpoint string, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, newStatusError(provider, resp)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+}
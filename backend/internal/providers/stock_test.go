@@ -0,0 +1,21 @@
+package providers
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStockSymbols(t *testing.T) {
+	t.Parallel()
+
+	symbols, keysBySymbol := stockSymbols([]string{"brk.b", "BRK/B", " BRK-B ", "VOD.L", "ry.to", "AAPL", "brk.b", ""}, "-")
+	if want := []string{"BRK-B", "VOD.L", "RY.TO", "AAPL"}; !slices.Equal(symbols, want) {
+		t.Fatalf("expected symbols %v, got %v", want, symbols)
+	}
+	if want := []string{"brk.b", "BRK/B", "BRK-B"}; !slices.Equal(keysBySymbol["BRK-B"], want) {
+		t.Fatalf("expected BRK-B keys %v, got %v", want, keysBySymbol["BRK-B"])
+	}
+	if want := []string{"ry.to"}; !slices.Equal(keysBySymbol["RY.TO"], want) {
+		t.Fatalf("expected RY.TO keys %v, got %v", want, keysBySymbol["RY.TO"])
+	}
+}
@@ -0,0 +1,223 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	stooqDefaultBaseURL = "https://stooq.com"
+	stooqMaxBatchSize   = 50
+)
+
+// StooqProvider reads delayed quotes from Stooq's keyless CSV endpoint. Lookup
+// keys are US tickers; Stooq spells them lowercase with a .us suffix and a
+// dash for class shares (brk-b.us).
+type StooqProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewStooqProvider(baseURL string) *StooqProvider {
+	resolvedBaseURL := strings.TrimRight(baseURL, "/")
+	if resolvedBaseURL == "" {
+		resolvedBaseURL = stooqDefaultBaseURL
+	}
+
+	return &StooqProvider{
+		baseURL: resolvedBaseURL,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Limits leaves requests unpaced; Stooq publishes no budget.
+func (p *StooqProvider) Limits() Limits {
+	return Limits{BatchSize: stooqMaxBatchSize}
+}
+
+func (p *StooqProvider) FetchQuotes(ctx context.Context, lookupKeys []string) ([]AssetQuote, error) {
+	symbols, keysBySymbol := stockSymbols(lookupKeys, "-")
+	if len(symbols) == 0 {
+		return nil, nil
+	}
+
+	header := http.Header{}
+	header.Set("Accept", "text/csv")
+
+	quotes := make([]AssetQuote, 0, len(symbols))
+	for _, batch := range chunkStrings(symbols, stooqMaxBatchSize) {
+		stooqSymbols := make([]string, 0, len(batch))
+		for _, symbol := range batch {
+			stooqSymbols = append(stooqSymbols, stooqSymbol(symbol))
+		}
+		// Stooq separates symbols with a literal '+', so the query is not encoded.
+		endpoint := p.baseURL + "/q/l/?s=" + strings.Join(stooqSymbols, "+") + "&f=sc&h&e=csv"
+		body, err := getProviderBody(ctx, p.client, "stooq", endpoint, header)
+		if err != nil {
+			return nil, err
+		}
+
+		prices, err := parseStooqCSV(body)
+		if err != nil {
+			return nil, err
+		}
+		for i, symbol := range batch {
+			price, ok := prices[stooqSymbols[i]]
+			if !ok {
+				continue
+			}
+			for _, key := range keysBySymbol[symbol] {
+				quotes = append(quotes, AssetQuote{
+					LookupKey: key,
+					Price:     price,
+					Provider:  "stooq",
+				})
+			}
+		}
+	}
+	return quotes, nil
+}
+
+// FetchHistory reads daily closes from Stooq's CSV download. Stooq answers
+// "No data" for unknown symbols and empty ranges.
+func (p *StooqProvider) FetchHistory(ctx context.Context, lookupKey string, from, to time.Time) ([]HistoricalPrice, error) {
+	symbols, _ := stockSymbols([]string{lookupKey}, "-")
+	if len(symbols) == 0 || !historyDay(from).Before(historyDay(to)) {
+		return nil, nil
+	}
+
+	header := http.Header{}
+	header.Set("Accept", "text/csv")
+	// d2 is inclusive, so the range ends the day before to.
+	endpoint := p.baseURL + "/q/d/l/?s=" + stooqSymbol(symbols[0]) + "&i=d" +
+		"&d1=" + historyDay(from).Format("20060102") + "&d2=" + historyDay(to).AddDate(0, 0, -1).Format("20060102")
+	body, err := getProviderBody(ctx, p.client, "stooq", endpoint, header)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("No data")) {
+		return nil, nil
+	}
+
+	points, err := parseStooqHistoryCSV(body)
+	if err != nil {
+		return nil, err
+	}
+	return dailyPrices(points, from, to), nil
+}
+
+func stooqSymbol(symbol string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(symbol) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String() + ".us"
+}
+
+// parseStooqCSV reads "Symbol,Close" rows by lowercase symbol. Unknown symbols
+// have N/D as their close and are skipped.
+func parseStooqCSV(body []byte) (map[string]float64, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return map[string]float64{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	symbolColumn, closeColumn := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "symbol":
+			symbolColumn = i
+		case "close":
+			closeColumn = i
+		}
+	}
+	if symbolColumn < 0 || closeColumn < 0 {
+		return nil, errors.New("stooq error: unexpected csv header")
+	}
+
+	prices := make(map[string]float64)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) <= max(symbolColumn, closeColumn) {
+			continue
+		}
+		price, err := strconv.ParseFloat(strings.TrimSpace(record[closeColumn]), 64)
+		if err != nil || price <= 0 {
+			continue
+		}
+		prices[strings.ToLower(strings.TrimSpace(record[symbolColumn]))] = price
+	}
+	return prices, nil
+}
+
+// parseStooqHistoryCSV reads "Date,...,Close" rows.
+func parseStooqHistoryCSV(body []byte) ([]HistoricalPrice, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	dateColumn, closeColumn := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "date":
+			dateColumn = i
+		case "close":
+			closeColumn = i
+		}
+	}
+	if dateColumn < 0 || closeColumn < 0 {
+		return nil, errors.New("stooq error: unexpected csv header")
+	}
+
+	var points []HistoricalPrice
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) <= max(dateColumn, closeColumn) {
+			continue
+		}
+		day, err := time.Parse("2006-01-02", strings.TrimSpace(record[dateColumn]))
+		if err != nil {
+			continue
+		}
+		price, err := strconv.ParseFloat(strings.TrimSpace(record[closeColumn]), 64)
+		if err != nil || price <= 0 {
+			continue
+		}
+		points = append(points, HistoricalPrice{At: day, Price: price, Provider: "stooq"})
+	}
+	return points, nil
+}
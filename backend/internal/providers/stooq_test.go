@@ -0,0 +1,88 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStooqProviderFetchQuotes(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.RawQuery, "s=aapl.us+brk-b.us+nope.us&") {
+			t.Fatalf("unexpected query: %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write([]byte("Symbol,Close\r\nAAPL.US,190.5\r\nBRK-B.US,420.1\r\nNOPE.US,N/D\r\n"))
+	}))
+	defer ts.Close()
+
+	p := NewStooqProvider(ts.URL)
+	quotes, err := p.FetchQuotes(context.Background(), []string{"AAPL", "BRK.B", "NOPE"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(quotes) != 2 {
+		t.Fatalf("expected 2 quotes, got %+v", quotes)
+	}
+	if quotes[0].LookupKey != "AAPL" || quotes[0].Price != 190.5 || quotes[0].Provider != "stooq" {
+		t.Fatalf("unexpected first quote: %+v", quotes[0])
+	}
+	if quotes[1].LookupKey != "BRK.B" || quotes[1].Price != 420.1 {
+		t.Fatalf("unexpected second quote: %+v", quotes[1])
+	}
+}
+
+func TestStooqProviderFetchQuotes_UnexpectedBody(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("Exceeded the daily hits limit"))
+	}))
+	defer ts.Close()
+
+	p := NewStooqProvider(ts.URL)
+	_, err := p.FetchQuotes(context.Background(), []string{"AAPL"})
+	if err == nil || !strings.Contains(err.Error(), "unexpected csv header") {
+		t.Fatalf("expected header error, got %v", err)
+	}
+}
+
+func TestStooqProviderFetchHistory(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/q/d/l/" || r.URL.RawQuery != "s=brk-b.us&i=d&d1=20240102&d2=20240104" {
+			t.Fatalf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte("Date,Open,High,Low,Close,Volume\r\n2024-01-02,360,362,358,361.5,100\r\n2024-01-03,361,363,359,362.25,100\r\n"))
+	}))
+	defer ts.Close()
+
+	p := NewStooqProvider(ts.URL)
+	prices, err := p.FetchHistory(context.Background(), "BRK.B", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(prices) != 2 || prices[1].Price != 362.25 || prices[1].Provider != "stooq" || !prices[1].At.Equal(time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected prices: %+v", prices)
+	}
+}
+
+func TestStooqProviderFetchHistory_NoData(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("No data"))
+	}))
+	defer ts.Close()
+
+	prices, err := NewStooqProvider(ts.URL).FetchHistory(context.Background(), "NOPE", time.Now().AddDate(0, 0, -10), time.Now())
+	if err != nil || prices != nil {
+		t.Fatalf("expected no prices, got prices=%+v err=%v", prices, err)
+	}
+}
@@ -0,0 +1,131 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	twelveDataDefaultBaseURL = "https://api.twelvedata.com"
+	twelveDataMaxBatchSize   = 8
+)
+
+// TwelveDataProvider quotes symbols in batches from /price. Class shares use a
+// dot (BRK.B).
+type TwelveDataProvider struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// twelveDataPrice is either a price or, with Status "error", a failure. The
+// API reports failures in a 200 body, for the whole request or per symbol.
+type twelveDataPrice struct {
+	Price   string `json:"price"`
+	Status  string `json:"status"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func NewTwelveDataProvider(baseURL, apiKey string) *TwelveDataProvider {
+	resolvedBaseURL := strings.TrimRight(baseURL, "/")
+	if resolvedBaseURL == "" {
+		resolvedBaseURL = twelveDataDefaultBaseURL
+	}
+
+	return &TwelveDataProvider{
+		baseURL: resolvedBaseURL,
+		apiKey:  apiKey,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Limits is the free plan's budget of 8 API credits a minute. Twelve Data
+// charges a credit per symbol, not per request, so a batch of 8 spends the
+// whole minute.
+func (p *TwelveDataProvider) Limits() Limits {
+	return Limits{BatchSize: twelveDataMaxBatchSize, RequestsPerMinute: 1}
+}
+
+func (p *TwelveDataProvider) FetchQuotes(ctx context.Context, lookupKeys []string) ([]AssetQuote, error) {
+	symbols, keysBySymbol := stockSymbols(lookupKeys, ".")
+	if len(symbols) == 0 {
+		return nil, nil
+	}
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("twelve data api key is not set")
+	}
+
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	header.Set("Authorization", "apikey "+p.apiKey)
+
+	quotes := make([]AssetQuote, 0, len(symbols))
+	for _, batch := range chunkStrings(symbols, twelveDataMaxBatchSize) {
+		body, err := getProviderBody(ctx, p.client, "twelve data", p.baseURL+"/price?symbol="+url.QueryEscape(strings.Join(batch, ",")), header)
+		if err != nil {
+			return nil, err
+		}
+
+		prices, err := parseTwelveDataPrices(body, batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, symbol := range batch {
+			entry, ok := prices[symbol]
+			if !ok || entry.Status == "error" {
+				continue
+			}
+			price, err := strconv.ParseFloat(entry.Price, 64)
+			if err != nil || price <= 0 {
+				continue
+			}
+			for _, key := range keysBySymbol[symbol] {
+				quotes = append(quotes, AssetQuote{
+					LookupKey: key,
+					Price:     price,
+					Provider:  "twelvedata",
+				})
+			}
+		}
+	}
+	return quotes, nil
+}
+
+// parseTwelveDataPrices reads the single-symbol shape {"price": ...} and the
+// batch shape {"AAPL": {"price": ...}} into prices by symbol.
+func parseTwelveDataPrices(body []byte, symbols []string) (map[string]twelveDataPrice, error) {
+	var single twelveDataPrice
+	if err := json.Unmarshal(body, &single); err != nil {
+		return nil, err
+	}
+	if len(symbols) == 1 {
+		// An unknown symbol fails the request with a 400 or 404 code.
+		if single.Status == "error" && single.Code != http.StatusBadRequest && single.Code != http.StatusNotFound {
+			return nil, fmt.Errorf("twelve data error: code %d: %s", single.Code, single.Message)
+		}
+		return map[string]twelveDataPrice{symbols[0]: single}, nil
+	}
+
+	if single.Status == "error" {
+		return nil, fmt.Errorf("twelve data error: code %d: %s", single.Code, single.Message)
+	}
+
+	var batch map[string]twelveDataPrice
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return nil, err
+	}
+	prices := make(map[string]twelveDataPrice, len(batch))
+	for symbol, entry := range batch {
+		prices[strings.ToUpper(symbol)] = entry
+	}
+	return prices, nil
+}
@@ -0,0 +1,97 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTwelveDataProviderFetchQuotes_Batch(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "apikey test-key" {
+			t.Fatalf("expected apikey authorization, got %q", got)
+		}
+		if got := r.URL.Query().Get("symbol"); got != "AAPL,BRK.B,NOPE" {
+			t.Fatalf("expected symbol query AAPL,BRK.B,NOPE, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"AAPL":{"price":"190.50000"},"BRK.B":{"price":"420.1"},"NOPE":{"code":400,"message":"symbol not found","status":"error"}}`))
+	}))
+	defer ts.Close()
+
+	p := NewTwelveDataProvider(ts.URL, "test-key")
+	quotes, err := p.FetchQuotes(context.Background(), []string{"AAPL", "brk/b", "BRK-B", "NOPE"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(quotes) != 3 {
+		t.Fatalf("expected 3 quotes, got %+v", quotes)
+	}
+	if quotes[0].LookupKey != "AAPL" || quotes[0].Price != 190.5 || quotes[0].Provider != "twelvedata" {
+		t.Fatalf("unexpected first quote: %+v", quotes[0])
+	}
+	if quotes[1].LookupKey != "brk/b" || quotes[1].Price != 420.1 || quotes[2].LookupKey != "BRK-B" || quotes[2].Price != 420.1 {
+		t.Fatalf("unexpected class-share quotes: %+v", quotes[1:])
+	}
+}
+
+func TestTwelveDataProviderFetchQuotes_SingleAndBatchLimit(t *testing.T) {
+	t.Parallel()
+
+	var requests []int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		symbols := strings.Split(r.URL.Query().Get("symbol"), ",")
+		requests = append(requests, len(symbols))
+		w.Header().Set("Content-Type", "application/json")
+		if len(symbols) == 1 {
+			_, _ = w.Write([]byte(`{"price":"10"}`))
+			return
+		}
+		parts := make([]string, 0, len(symbols))
+		for _, symbol := range symbols {
+			parts = append(parts, fmt.Sprintf(`%q:{"price":"10"}`, symbol))
+		}
+		_, _ = w.Write([]byte("{" + strings.Join(parts, ",") + "}"))
+	}))
+	defer ts.Close()
+
+	keys := make([]string, 0, 9)
+	for i := 0; i < 9; i++ {
+		keys = append(keys, fmt.Sprintf("S%d", i))
+	}
+
+	p := NewTwelveDataProvider(ts.URL, "test-key")
+	quotes, err := p.FetchQuotes(context.Background(), keys)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(requests) != 2 || requests[0] != 8 || requests[1] != 1 {
+		t.Fatalf("unexpected request sizes: %v", requests)
+	}
+	if len(quotes) != 9 || quotes[8].LookupKey != "S8" {
+		t.Fatalf("expected 9 quotes, got %d", len(quotes))
+	}
+}
+
+func TestTwelveDataProviderFetchQuotes_ErrorPayload(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"code":429,"message":"You have run out of API credits for the current minute.","status":"error"}`))
+	}))
+	defer ts.Close()
+
+	p := NewTwelveDataProvider(ts.URL, "test-key")
+	for _, keys := range [][]string{{"AAPL"}, {"AAPL", "MSFT"}} {
+		_, err := p.FetchQuotes(context.Background(), keys)
+		if err == nil || !strings.Contains(err.Error(), "code 429") {
+			t.Fatalf("expected rate limit error for %v, got %v", keys, err)
+		}
+	}
+}
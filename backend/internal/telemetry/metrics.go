@@ -20,6 +20,13 @@ var (
 	wsConnectionsTotal         = expvar.NewInt("ws_connections_total")
 	wsAuthFailuresTotal        = expvar.NewInt("ws_auth_failures_total")
 	wsSessionInitFailuresTotal = expvar.NewInt("ws_session_init_failures_total")
+	providerRequestsTotal      = expvar.NewMap("provider_requests_total")
+	providerErrorsTotal        = expvar.NewMap("provider_errors_total")
+	providerKeysRequestedTotal = expvar.NewMap("provider_keys_requested_total")
+	providerQuotesTotal        = expvar.NewMap("provider_quotes_total")
+	providerRetriesTotal       = expvar.NewMap("provider_retries_total")
+	providerBreakerState       = expvar.NewMap("provider_breaker_state")
+	providerBreakerOpensTotal  = expvar.NewMap("provider_breaker_opens_total")
 )
 
 type statusRecorder struct {
@@ -84,3 +91,30 @@ func WSAuthFailure() {
 func WSSessionInitFailure() {
 	wsSessionInitFailuresTotal.Add(1)
 }
+
+// ProviderFetch records one FetchQuotes call of a quote provider: how many
+// keys it was asked for and how many it priced.
+func ProviderFetch(provider string, requested int, priced int, err error) {
+	providerRequestsTotal.Add(provider, 1)
+	providerKeysRequestedTotal.Add(provider, int64(requested))
+	providerQuotesTotal.Add(provider, int64(priced))
+	if err != nil {
+		providerErrorsTotal.Add(provider, 1)
+	}
+}
+
+// ProviderRetry records a provider call retried after a transient failure.
+func ProviderRetry(provider string) {
+	providerRetriesTotal.Add(provider, 1)
+}
+
+// ProviderBreakerState records a provider's circuit breaker state: closed,
+// open or half_open.
+func ProviderBreakerState(provider string, state string) {
+	value := new(expvar.String)
+	value.Set(state)
+	providerBreakerState.Set(provider, value)
+	if state == "open" {
+		providerBreakerOpensTotal.Add(provider, 1)
+	}
+}
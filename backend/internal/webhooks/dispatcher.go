@@ -0,0 +1,180 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"asset-tracker/internal/db"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+
+	defaultBatchSize   = 50
+	defaultMaxAttempts = 8
+	defaultBaseBackoff = 30 * time.Second
+	defaultMaxBackoff  = 6 * time.Hour
+	defaultLease       = 10 * time.Minute
+	requestTimeout     = 10 * time.Second
+	maxErrorLength     = 500
+)
+
+type Store interface {
+	ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]db.WebhookJob, error)
+	RecordWebhookAttempt(ctx context.Context, attempt db.WebhookAttempt) error
+}
+
+// Envelope is the JSON body posted to webhook endpoints. ID is the delivery
+// id and stays the same across retries, so receivers can deduplicate.
+type Envelope struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+type Dispatcher struct {
+	store       Store
+	client      *http.Client
+	now         func() time.Time
+	batchSize   int
+	maxAttempts int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+}
+
+// NewDispatcher posts with client, or with NewClient when client is nil.
+func NewDispatcher(store Store, client *http.Client) *Dispatcher {
+	if client == nil {
+		client = NewClient()
+	}
+	return &Dispatcher{
+		store:       store,
+		client:      client,
+		now:         time.Now,
+		batchSize:   defaultBatchSize,
+		maxAttempts: defaultMaxAttempts,
+		baseBackoff: defaultBaseBackoff,
+		maxBackoff:  defaultMaxBackoff,
+	}
+}
+
+// DispatchDue delivers every due delivery once and records the outcome. It
+// returns the number of successful deliveries.
+func (d *Dispatcher) DispatchDue(ctx context.Context) (int, error) {
+	jobs, err := d.store.ClaimWebhookDeliveries(ctx, d.now().UTC(), defaultLease, d.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, job := range jobs {
+		attempt := d.deliver(ctx, job)
+		if attempt.Status == db.WebhookDeliverySucceeded {
+			delivered++
+		}
+		if err := d.store.RecordWebhookAttempt(ctx, attempt); err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, job db.WebhookJob) db.WebhookAttempt {
+	attemptedAt := d.now().UTC()
+	attempt := db.WebhookAttempt{DeliveryID: job.ID, AttemptedAt: attemptedAt, NextAttemptAt: attemptedAt}
+
+	statusCode, err := d.post(ctx, job, attemptedAt)
+	attempt.StatusCode = statusCode
+	if err == nil {
+		attempt.Status = db.WebhookDeliverySucceeded
+		return attempt
+	}
+
+	attempt.Error = truncate(err.Error(), maxErrorLength)
+	attempts := job.Attempts + 1
+	if attempts >= d.maxAttempts {
+		attempt.Status = db.WebhookDeliveryFailed
+		return attempt
+	}
+	attempt.Status = db.WebhookDeliveryPending
+	attempt.NextAttemptAt = attemptedAt.Add(backoff(attempts, d.baseBackoff, d.maxBackoff))
+	return attempt
+}
+
+func (d *Dispatcher) post(ctx context.Context, job db.WebhookJob, sentAt time.Time) (int, error) {
+	body, err := json.Marshal(Envelope{
+		ID:        job.ID,
+		Type:      job.EventType,
+		CreatedAt: job.CreatedAt.UTC(),
+		Data:      json.RawMessage(job.Payload),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := sentAt.Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "asset-tracker-webhooks/1")
+	req.Header.Set(EventHeader, job.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(job.ID, 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, "v1="+Sign(job.Secret, timestamp, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("endpoint returned status %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" keyed by secret.
+// Receivers recompute it from the timestamp header and the raw body.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff doubles the delay after each failed attempt, capped at max.
+func backoff(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return delay
+}
+
+func truncate(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	return value[:limit]
+}
@@ -0,0 +1,240 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"asset-tracker/internal/db"
+)
+
+type mockStore struct {
+	jobs     []db.WebhookJob
+	attempts []db.WebhookAttempt
+}
+
+func (m *mockStore) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]db.WebhookJob, error) {
+	jobs := m.jobs
+	m.jobs = nil
+	return jobs, nil
+}
+
+func (m *mockStore) RecordWebhookAttempt(ctx context.Context, attempt db.WebhookAttempt) error {
+	m.attempts = append(m.attempts, attempt)
+	return nil
+}
+
+// newTestDispatcher lets deliveries reach httptest servers on loopback.
+func newTestDispatcher(store Store, now time.Time) *Dispatcher {
+	d := NewDispatcher(store, newClient(func(netip.Addr) bool { return true }))
+	d.now = func() time.Time { return now }
+	return d
+}
+
+func TestDispatchDueSignsPayload(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	var gotEnvelope Envelope
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		if err != nil {
+			t.Fatalf("invalid timestamp header: %v", err)
+		}
+		if got, want := r.Header.Get(SignatureHeader), "v1="+Sign("whsec_test", timestamp, body); got != want {
+			t.Fatalf("expected signature %q, got %q", want, got)
+		}
+		if got := r.Header.Get(EventHeader); got != db.WebhookEventLotCreated {
+			t.Fatalf("expected event header lot.created, got %q", got)
+		}
+		if err := json.Unmarshal(body, &gotEnvelope); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	store := &mockStore{jobs: []db.WebhookJob{{
+		WebhookDelivery: db.WebhookDelivery{ID: 11, EventType: db.WebhookEventLotCreated, Payload: []byte(`{"lot_id":5}`), CreatedAt: now},
+		URL:             ts.URL,
+		Secret:          "whsec_test",
+	}}}
+
+	delivered, err := newTestDispatcher(store, now).DispatchDue(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if delivered != 1 {
+		t.Fatalf("expected 1 delivery, got %d", delivered)
+	}
+	if gotEnvelope.ID != 11 || gotEnvelope.Type != db.WebhookEventLotCreated || string(gotEnvelope.Data) != `{"lot_id":5}` {
+		t.Fatalf("unexpected envelope: %+v", gotEnvelope)
+	}
+	if len(store.attempts) != 1 || store.attempts[0].Status != db.WebhookDeliverySucceeded || store.attempts[0].StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected attempts: %+v", store.attempts)
+	}
+}
+
+func TestDispatchDueSchedulesRetryWithBackoff(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	store := &mockStore{jobs: []db.WebhookJob{{
+		WebhookDelivery: db.WebhookDelivery{ID: 3, EventType: db.WebhookEventTest, Payload: []byte(`{}`), Attempts: 2},
+		URL:             ts.URL,
+		Secret:          "s",
+	}}}
+
+	if _, err := newTestDispatcher(store, now).DispatchDue(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	attempt := store.attempts[0]
+	if attempt.Status != db.WebhookDeliveryPending || attempt.StatusCode != http.StatusBadGateway {
+		t.Fatalf("unexpected attempt: %+v", attempt)
+	}
+	if want := now.Add(120 * time.Second); !attempt.NextAttemptAt.Equal(want) {
+		t.Fatalf("expected next attempt at %v, got %v", want, attempt.NextAttemptAt)
+	}
+}
+
+func TestDispatchDueGivesUpAfterMaxAttempts(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	store := &mockStore{jobs: []db.WebhookJob{{
+		WebhookDelivery: db.WebhookDelivery{ID: 3, EventType: db.WebhookEventTest, Payload: []byte(`{}`), Attempts: defaultMaxAttempts - 1},
+		URL:             ts.URL,
+	}}}
+
+	if _, err := newTestDispatcher(store, time.Now()).DispatchDue(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if attempt := store.attempts[0]; attempt.Status != db.WebhookDeliveryFailed || attempt.Error == "" {
+		t.Fatalf("expected failed attempt with error, got %+v", attempt)
+	}
+}
+
+func TestDispatchDueRefusesLoopback(t *testing.T) {
+	t.Parallel()
+
+	hit := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	store := &mockStore{jobs: []db.WebhookJob{{
+		WebhookDelivery: db.WebhookDelivery{ID: 4, EventType: db.WebhookEventTest, Payload: []byte(`{}`)},
+		URL:             ts.URL,
+	}}}
+
+	d := NewDispatcher(store, nil)
+	delivered, err := d.DispatchDue(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if delivered != 0 || hit {
+		t.Fatalf("expected loopback delivery to be refused, delivered=%d hit=%v", delivered, hit)
+	}
+	if attempt := store.attempts[0]; attempt.Status != db.WebhookDeliveryPending || !strings.Contains(attempt.Error, ErrBlockedAddress.Error()) {
+		t.Fatalf("expected blocked address error, got %+v", attempt)
+	}
+}
+
+func TestDispatchDueDoesNotFollowRedirects(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://10.0.0.1/internal", http.StatusFound)
+	}))
+	defer ts.Close()
+
+	store := &mockStore{jobs: []db.WebhookJob{{
+		WebhookDelivery: db.WebhookDelivery{ID: 5, EventType: db.WebhookEventTest, Payload: []byte(`{}`)},
+		URL:             ts.URL,
+	}}}
+
+	// Only the test server's loopback address is allowed, as if it were public.
+	d := NewDispatcher(store, newClient(func(addr netip.Addr) bool { return addr.IsLoopback() }))
+	if _, err := d.DispatchDue(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if attempt := store.attempts[0]; attempt.Status != db.WebhookDeliveryPending || attempt.StatusCode != http.StatusFound {
+		t.Fatalf("expected the redirect to be recorded as a failed attempt, got %+v", attempt)
+	}
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	t.Parallel()
+
+	for value, want := range map[string]bool{
+		"93.184.216.34":    true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"::1":              false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"fe80::1":          false,
+		"fc00::1":          false,
+		"0.0.0.0":          false,
+		"::":               false,
+		"224.0.0.1":        false,
+		"::ffff:127.0.0.1": false,
+	} {
+		if got := IsPublicAddr(netip.MustParseAddr(value)); got != want {
+			t.Fatalf("IsPublicAddr(%s) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	t.Parallel()
+
+	if got := backoff(1, time.Second, time.Minute); got != time.Second {
+		t.Fatalf("expected 1s, got %v", got)
+	}
+	if got := backoff(4, time.Second, time.Minute); got != 8*time.Second {
+		t.Fatalf("expected 8s, got %v", got)
+	}
+	if got := backoff(20, time.Second, time.Minute); got != time.Minute {
+		t.Fatalf("expected cap of 1m, got %v", got)
+	}
+}
+
+func TestBuildDailySummaryTotals(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	summary := BuildDailySummary(day, []db.Position{
+		{AssetID: 1, TotalQty: 2, AvgCost: 100, CurrentPrice: sql.NullFloat64{Float64: 150, Valid: true}, UnrealizedPL: sql.NullFloat64{Float64: 100, Valid: true}},
+		{AssetID: 2, TotalQty: 1, AvgCost: 50},
+	})
+
+	if summary.Date != "2026-10-18" || summary.PositionCount != 2 || summary.Unpriced != 1 {
+		t.Fatalf("unexpected summary header: %+v", summary)
+	}
+	if summary.CostBasis != 250 || summary.MarketValue != 300 || summary.UnrealizedPL != 100 {
+		t.Fatalf("unexpected summary totals: %+v", summary)
+	}
+}
@@ -0,0 +1,98 @@
+package webhooks
+
+import (
+	"context"
+	"time"
+
+	"asset-tracker/internal/db"
+)
+
+type SummaryStore interface {
+	ListDailySummaryTargets(ctx context.Context, dayStart time.Time) ([]db.WebhookTarget, error)
+	FetchPositionsForUser(ctx context.Context, userID string) ([]db.Position, error)
+	EnqueueWebhookDeliveries(ctx context.Context, webhookIDs []int64, userID string, eventType string, payload any) (int64, error)
+}
+
+type DailySummary struct {
+	Date          string            `json:"date"`
+	PositionCount int               `json:"position_count"`
+	CostBasis     float64           `json:"cost_basis"`
+	MarketValue   float64           `json:"market_value"`
+	UnrealizedPL  float64           `json:"unrealized_pl"`
+	Unpriced      int               `json:"unpriced_positions"`
+	Positions     []SummaryPosition `json:"positions"`
+}
+
+type SummaryPosition struct {
+	AssetID      int64    `json:"asset_id"`
+	TotalQty     float64  `json:"total_qty"`
+	AvgCost      float64  `json:"avg_cost"`
+	CurrentPrice *float64 `json:"current_price"`
+	UnrealizedPL *float64 `json:"unrealized_pl"`
+}
+
+// EnqueueDailySummaries queues one portfolio.daily_summary per subscribed
+// webhook per UTC day. It is safe to call repeatedly; webhooks that already
+// received today's summary are skipped.
+func EnqueueDailySummaries(ctx context.Context, store SummaryStore, now time.Time) (int64, error) {
+	now = now.UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	targets, err := store.ListDailySummaryTargets(ctx, dayStart)
+	if err != nil {
+		return 0, err
+	}
+
+	webhooksByUser := make(map[string][]int64)
+	var userIDs []string
+	for _, target := range targets {
+		if _, ok := webhooksByUser[target.UserID]; !ok {
+			userIDs = append(userIDs, target.UserID)
+		}
+		webhooksByUser[target.UserID] = append(webhooksByUser[target.UserID], target.WebhookID)
+	}
+
+	var queued int64
+	for _, userID := range userIDs {
+		positions, err := store.FetchPositionsForUser(ctx, userID)
+		if err != nil {
+			return queued, err
+		}
+		count, err := store.EnqueueWebhookDeliveries(ctx, webhooksByUser[userID], userID, db.WebhookEventDailySummary, BuildDailySummary(dayStart, positions))
+		if err != nil {
+			return queued, err
+		}
+		queued += count
+	}
+	return queued, nil
+}
+
+func BuildDailySummary(day time.Time, positions []db.Position) DailySummary {
+	summary := DailySummary{
+		Date:          day.Format("2006-01-02"),
+		PositionCount: len(positions),
+		Positions:     make([]SummaryPosition, 0, len(positions)),
+	}
+	for _, position := range positions {
+		item := SummaryPosition{
+			AssetID:  position.AssetID,
+			TotalQty: position.TotalQty,
+			AvgCost:  position.AvgCost,
+		}
+		summary.CostBasis += position.TotalQty * position.AvgCost
+		if position.CurrentPrice.Valid {
+			price := position.CurrentPrice.Float64
+			item.CurrentPrice = &price
+			summary.MarketValue += position.TotalQty * price
+		} else {
+			summary.Unpriced++
+		}
+		if position.UnrealizedPL.Valid {
+			pl := position.UnrealizedPL.Float64
+			item.UnrealizedPL = &pl
+			summary.UnrealizedPL += pl
+		}
+		summary.Positions = append(summary.Positions, item)
+	}
+	return summary
+}
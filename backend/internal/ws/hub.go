@@ -5,11 +5,20 @@ import (
 	"sync"
 )
 
+const subscriberOutboxSize = 16
+
+// Subscriber is one websocket session. AssetIDs holds every asset the session
+// receives, whether subscribed individually or through a watchlist; the
+// sources are kept apart so releasing one leaves the others in place.
 type Subscriber struct {
 	SessionID string
 	UserID    string
 	Portfolio bool
 	AssetIDs  map[int64]struct{}
+
+	assets     map[int64]struct{}
+	watchlists map[int64][]int64
+	outbox     chan serverMessage
 }
 
 type Hub struct {
@@ -31,9 +40,12 @@ func (h *Hub) Add(sessionID, userID string) error {
 		return fmt.Errorf("session already exists")
 	}
 	h.subscribers[sessionID] = &Subscriber{
-		SessionID: sessionID,
-		UserID:    userID,
-		AssetIDs:  map[int64]struct{}{},
+		SessionID:  sessionID,
+		UserID:     userID,
+		AssetIDs:   map[int64]struct{}{},
+		assets:     map[int64]struct{}{},
+		watchlists: map[int64][]int64{},
+		outbox:     make(chan serverMessage, subscriberOutboxSize),
 	}
 	return nil
 }
@@ -71,7 +83,8 @@ func (h *Hub) SubscribeAsset(sessionID string, assetID int64) {
 	if !ok {
 		return
 	}
-	sub.AssetIDs[assetID] = struct{}{}
+	sub.assets[assetID] = struct{}{}
+	sub.refreshAssetIDs()
 }
 
 func (h *Hub) UnsubscribeAsset(sessionID string, assetID int64) {
@@ -81,5 +94,78 @@ func (h *Hub) UnsubscribeAsset(sessionID string, assetID int64) {
 	if !ok {
 		return
 	}
-	delete(sub.AssetIDs, assetID)
+	delete(sub.assets, assetID)
+	sub.refreshAssetIDs()
+}
+
+// SubscribeWatchlist subscribes the session to assetIDs on behalf of a
+// watchlist, replacing the assets recorded for it by an earlier subscription.
+func (h *Hub) SubscribeWatchlist(sessionID string, watchlistID int64, assetIDs []int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sub, ok := h.subscribers[sessionID]
+	if !ok {
+		return
+	}
+	sub.watchlists[watchlistID] = append([]int64(nil), assetIDs...)
+	sub.refreshAssetIDs()
+}
+
+// UnsubscribeWatchlist releases the assets subscribed through watchlistID and
+// returns them. Assets the session also subscribed to individually or through
+// another watchlist stay subscribed.
+func (h *Hub) UnsubscribeWatchlist(sessionID string, watchlistID int64) []int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sub, ok := h.subscribers[sessionID]
+	if !ok {
+		return nil
+	}
+	assetIDs := sub.watchlists[watchlistID]
+	delete(sub.watchlists, watchlistID)
+	sub.refreshAssetIDs()
+	return assetIDs
+}
+
+func (s *Subscriber) refreshAssetIDs() {
+	assetIDs := make(map[int64]struct{}, len(s.assets))
+	for assetID := range s.assets {
+		assetIDs[assetID] = struct{}{}
+	}
+	for _, watched := range s.watchlists {
+		for _, assetID := range watched {
+			assetIDs[assetID] = struct{}{}
+		}
+	}
+	s.AssetIDs = assetIDs
+}
+
+// PublishToUser queues msg for every session of userID and returns the number
+// of sessions it was queued for. Sessions whose outbox is full are skipped
+// rather than blocking the publisher.
+func (h *Hub) PublishToUser(userID string, msg serverMessage) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	delivered := 0
+	for _, sub := range h.subscribers {
+		if sub.UserID != userID {
+			continue
+		}
+		select {
+		case sub.outbox <- msg:
+			delivered++
+		default:
+		}
+	}
+	return delivered
+}
+
+func (h *Hub) outbox(sessionID string) <-chan serverMessage {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	sub, ok := h.subscribers[sessionID]
+	if !ok {
+		return nil
+	}
+	return sub.outbox
 }
@@ -2,6 +2,7 @@ package ws
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -9,14 +10,22 @@ import (
 	"time"
 
 	"asset-tracker/internal/auth"
+	"asset-tracker/internal/db"
 	"asset-tracker/internal/telemetry"
 	"nhooyr.io/websocket"
 	"nhooyr.io/websocket/wsjson"
 )
 
 type Server struct {
-	Hub      *Hub
-	Verifier auth.Verifier
+	Hub        *Hub
+	Verifier   auth.Verifier
+	Watchlists WatchlistStore
+}
+
+// WatchlistStore resolves a user's watchlist to its asset ids. Watchlist
+// subscriptions are rejected when the server has none.
+type WatchlistStore interface {
+	ListWatchlistAssetIDs(ctx context.Context, userID string, watchlistID int64) ([]int64, error)
 }
 
 type messageType string
@@ -28,32 +37,59 @@ const (
 	messageTypeError        messageType = "error"
 	messageTypeSubscribed   messageType = "subscribed"
 	messageTypeUnsubscribed messageType = "unsubscribed"
+	messageTypeAlert        messageType = "alert_triggered"
 
 	messageScopePortfolio messageScope = "portfolio"
 	messageScopeAsset     messageScope = "asset"
+	messageScopeWatchlist messageScope = "watchlist"
 
 	messageActionSubscribe   messageAction = "subscribe"
 	messageActionUnsubscribe messageAction = "unsubscribe"
 )
 
 type clientMessage struct {
-	Type    string `json:"type"`
-	Scope   string `json:"scope"`
-	AssetID int64  `json:"asset_id"`
+	Type        string `json:"type"`
+	Scope       string `json:"scope"`
+	AssetID     int64  `json:"asset_id"`
+	WatchlistID int64  `json:"watchlist_id"`
 }
 
 type serverMessage struct {
-	Type    string `json:"type"`
-	Scope   string `json:"scope,omitempty"`
-	AssetID int64  `json:"asset_id,omitempty"`
-	UserID  string `json:"user_id,omitempty"`
-	Message string `json:"message,omitempty"`
+	Type        string      `json:"type"`
+	Scope       string      `json:"scope,omitempty"`
+	AssetID     int64       `json:"asset_id,omitempty"`
+	WatchlistID int64       `json:"watchlist_id,omitempty"`
+	AssetIDs    []int64     `json:"asset_ids,omitempty"`
+	UserID      string      `json:"user_id,omitempty"`
+	Message     string      `json:"message,omitempty"`
+	Alert       *AlertEvent `json:"alert,omitempty"`
+}
+
+type AlertEvent struct {
+	ID            int64   `json:"id"`
+	AlertID       int64   `json:"alert_id"`
+	AssetID       int64   `json:"asset_id"`
+	Kind          string  `json:"kind"`
+	Threshold     float64 `json:"threshold"`
+	ObservedValue float64 `json:"observed_value"`
+	Price         float64 `json:"price"`
+	TriggeredAt   string  `json:"triggered_at"`
 }
 
 func NewServer(hub *Hub, verifier auth.Verifier) *Server {
 	return &Server{Hub: hub, Verifier: verifier}
 }
 
+// PublishAlertEvent pushes a triggered alert to every connected session of
+// userID, regardless of subscriptions.
+func (s *Server) PublishAlertEvent(userID string, event AlertEvent) int {
+	return s.Hub.PublishToUser(userID, serverMessage{
+		Type:    string(messageTypeAlert),
+		AssetID: event.AssetID,
+		Alert:   &event,
+	})
+}
+
 func (s *Server) Handler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		token := extractToken(r)
@@ -104,6 +140,20 @@ func (s *Server) Handler() http.HandlerFunc {
 			return
 		}
 
+		go func(outbox <-chan serverMessage) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case msg := <-outbox:
+					if err := wsjson.Write(ctx, conn, msg); err != nil {
+						cancel()
+						return
+					}
+				}
+			}
+		}(s.Hub.outbox(sessionID))
+
 		for {
 			var msg clientMessage
 			if err := wsjson.Read(ctx, conn, &msg); err != nil {
@@ -116,7 +166,7 @@ func (s *Server) Handler() http.HandlerFunc {
 				return
 			}
 
-			if err := s.handleMessage(ctx, conn, sessionID, msg); err != nil {
+			if err := s.handleMessage(ctx, conn, sessionID, claims.Subject, msg); err != nil {
 				_ = wsjson.Write(ctx, conn, serverMessage{
 					Type:    string(messageTypeError),
 					Message: err.Error(),
@@ -126,12 +176,17 @@ func (s *Server) Handler() http.HandlerFunc {
 	}
 }
 
-func (s *Server) handleMessage(ctx context.Context, conn *websocket.Conn, sessionID string, msg clientMessage) error {
+func (s *Server) handleMessage(ctx context.Context, conn *websocket.Conn, sessionID string, userID string, msg clientMessage) error {
 	action := messageAction(strings.ToLower(strings.TrimSpace(msg.Type)))
 	scope := messageScope(strings.ToLower(strings.TrimSpace(msg.Scope)))
 
 	switch action {
 	case messageActionSubscribe:
+		reply := serverMessage{
+			Type:    string(messageTypeSubscribed),
+			Scope:   string(scope),
+			AssetID: msg.AssetID,
+		}
 		switch scope {
 		case messageScopePortfolio:
 			s.Hub.SubscribePortfolio(sessionID)
@@ -140,15 +195,24 @@ func (s *Server) handleMessage(ctx context.Context, conn *websocket.Conn, sessio
 				return fmt.Errorf("asset_id is required for asset subscriptions")
 			}
 			s.Hub.SubscribeAsset(sessionID, msg.AssetID)
+		case messageScopeWatchlist:
+			assetIDs, err := s.watchlistAssetIDs(ctx, userID, msg.WatchlistID)
+			if err != nil {
+				return err
+			}
+			s.Hub.SubscribeWatchlist(sessionID, msg.WatchlistID, assetIDs)
+			reply.WatchlistID = msg.WatchlistID
+			reply.AssetIDs = assetIDs
 		default:
-			return fmt.Errorf("invalid scope: use portfolio or asset")
+			return fmt.Errorf("invalid scope: use portfolio, asset or watchlist")
 		}
-		return wsjson.Write(ctx, conn, serverMessage{
-			Type:    string(messageTypeSubscribed),
+		return wsjson.Write(ctx, conn, reply)
+	case messageActionUnsubscribe:
+		reply := serverMessage{
+			Type:    string(messageTypeUnsubscribed),
 			Scope:   string(scope),
 			AssetID: msg.AssetID,
-		})
-	case messageActionUnsubscribe:
+		}
 		switch scope {
 		case messageScopePortfolio:
 			s.Hub.UnsubscribePortfolio(sessionID)
@@ -157,19 +221,41 @@ func (s *Server) handleMessage(ctx context.Context, conn *websocket.Conn, sessio
 				return fmt.Errorf("asset_id is required for asset subscriptions")
 			}
 			s.Hub.UnsubscribeAsset(sessionID, msg.AssetID)
+		case messageScopeWatchlist:
+			if msg.WatchlistID <= 0 {
+				return fmt.Errorf("watchlist_id is required for watchlist subscriptions")
+			}
+			reply.WatchlistID = msg.WatchlistID
+			reply.AssetIDs = s.Hub.UnsubscribeWatchlist(sessionID, msg.WatchlistID)
 		default:
-			return fmt.Errorf("invalid scope: use portfolio or asset")
+			return fmt.Errorf("invalid scope: use portfolio, asset or watchlist")
 		}
-		return wsjson.Write(ctx, conn, serverMessage{
-			Type:    string(messageTypeUnsubscribed),
-			Scope:   string(scope),
-			AssetID: msg.AssetID,
-		})
+		return wsjson.Write(ctx, conn, reply)
 	default:
 		return fmt.Errorf("invalid message type: use subscribe or unsubscribe")
 	}
 }
 
+// watchlistAssetIDs resolves a watchlist subscription to the list's current
+// assets; later edits to the list need a new subscription. Unsubscribing
+// releases the assets resolved here, not the list's assets at that time.
+func (s *Server) watchlistAssetIDs(ctx context.Context, userID string, watchlistID int64) ([]int64, error) {
+	if watchlistID <= 0 {
+		return nil, fmt.Errorf("watchlist_id is required for watchlist subscriptions")
+	}
+	if s.Watchlists == nil {
+		return nil, fmt.Errorf("watchlist subscriptions are unavailable")
+	}
+	assetIDs, err := s.Watchlists.ListWatchlistAssetIDs(ctx, userID, watchlistID)
+	if errors.Is(err, db.ErrWatchlistNotFound) {
+		return nil, fmt.Errorf("watchlist not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load watchlist")
+	}
+	return assetIDs, nil
+}
+
 func extractToken(r *http.Request) string {
 	if authz := strings.TrimSpace(r.Header.Get("Authorization")); authz != "" {
 		if strings.HasPrefix(strings.ToLower(authz), "bearer ") {
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"asset-tracker/internal/auth"
+	"asset-tracker/internal/db"
 	"nhooyr.io/websocket"
 	"nhooyr.io/websocket/wsjson"
 )
@@ -192,3 +193,159 @@ func singleSessionID(t *testing.T, hub *Hub) string {
 	}
 	return ""
 }
+
+func TestWSPublishAlertEventReachesOwnerOnly(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub()
+	srv := NewServer(hub, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "?token=good"
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, wsURL, nil)
+	if err != nil {
+		t.Fatalf("websocket dial failed: %v", err)
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "test done")
+
+	var ready serverMessage
+	if err := wsjson.Read(ctx, conn, &ready); err != nil {
+		t.Fatalf("failed reading ready message: %v", err)
+	}
+
+	if got := srv.PublishAlertEvent("user-2", AlertEvent{ID: 1}); got != 0 {
+		t.Fatalf("expected no sessions for other user, got %d", got)
+	}
+	if got := srv.PublishAlertEvent("user-1", AlertEvent{ID: 7, AlertID: 3, AssetID: 42, Kind: "price_above", Price: 101}); got != 1 {
+		t.Fatalf("expected alert queued for one session, got %d", got)
+	}
+
+	var got serverMessage
+	if err := wsjson.Read(ctx, conn, &got); err != nil {
+		t.Fatalf("failed reading alert message: %v", err)
+	}
+	if got.Type != "alert_triggered" || got.AssetID != 42 || got.Alert == nil || got.Alert.AlertID != 3 {
+		t.Fatalf("unexpected alert message: %+v", got)
+	}
+}
+
+type mockWatchlists map[int64][]int64
+
+func (m mockWatchlists) ListWatchlistAssetIDs(ctx context.Context, userID string, watchlistID int64) ([]int64, error) {
+	assetIDs, ok := m[watchlistID]
+	if !ok || userID != "user-1" {
+		return nil, db.ErrWatchlistNotFound
+	}
+	return assetIDs, nil
+}
+
+func TestWSWatchlistSubscription(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub()
+	srv := NewServer(hub, mockVerifier{claims: auth.Claims{Subject: "user-1"}})
+	srv.Watchlists = mockWatchlists{5: {7, 9}}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "?token=good"
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, wsURL, nil)
+	if err != nil {
+		t.Fatalf("websocket dial failed: %v", err)
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "test done")
+
+	var ready serverMessage
+	if err := wsjson.Read(ctx, conn, &ready); err != nil {
+		t.Fatalf("failed reading ready message: %v", err)
+	}
+
+	if err := wsjson.Write(ctx, conn, clientMessage{Type: "subscribe", Scope: "asset", AssetID: 7}); err != nil {
+		t.Fatalf("failed writing subscribe asset: %v", err)
+	}
+	var subscribedAsset serverMessage
+	if err := wsjson.Read(ctx, conn, &subscribedAsset); err != nil {
+		t.Fatalf("failed reading subscribed asset message: %v", err)
+	}
+
+	if err := wsjson.Write(ctx, conn, clientMessage{Type: "subscribe", Scope: "watchlist", WatchlistID: 5}); err != nil {
+		t.Fatalf("failed writing subscribe watchlist: %v", err)
+	}
+	var subscribed serverMessage
+	if err := wsjson.Read(ctx, conn, &subscribed); err != nil {
+		t.Fatalf("failed reading subscribed message: %v", err)
+	}
+	if subscribed.Type != "subscribed" || subscribed.WatchlistID != 5 || len(subscribed.AssetIDs) != 2 {
+		t.Fatalf("unexpected subscribed response: %+v", subscribed)
+	}
+	sessionID := singleSessionID(t, hub)
+	for _, assetID := range []int64{7, 9} {
+		if _, ok := hub.subscribers[sessionID].AssetIDs[assetID]; !ok {
+			t.Fatalf("expected asset %d to be subscribed", assetID)
+		}
+	}
+
+	if err := wsjson.Write(ctx, conn, clientMessage{Type: "subscribe", Scope: "watchlist", WatchlistID: 6}); err != nil {
+		t.Fatalf("failed writing subscribe to missing watchlist: %v", err)
+	}
+	var got serverMessage
+	if err := wsjson.Read(ctx, conn, &got); err != nil {
+		t.Fatalf("failed reading error message: %v", err)
+	}
+	if got.Type != "error" || got.Message != "watchlist not found" {
+		t.Fatalf("expected watchlist not found error, got %+v", got)
+	}
+
+	if err := wsjson.Write(ctx, conn, clientMessage{Type: "unsubscribe", Scope: "watchlist", WatchlistID: 5}); err != nil {
+		t.Fatalf("failed writing unsubscribe watchlist: %v", err)
+	}
+	if err := wsjson.Read(ctx, conn, &got); err != nil {
+		t.Fatalf("failed reading unsubscribed message: %v", err)
+	}
+	if got.Type != "unsubscribed" || len(got.AssetIDs) != 2 {
+		t.Fatalf("expected watchlist assets to be unsubscribed, got %+v", got)
+	}
+	hub.mu.RLock()
+	assetIDs := hub.subscribers[sessionID].AssetIDs
+	hub.mu.RUnlock()
+	if _, ok := assetIDs[7]; !ok || len(assetIDs) != 1 {
+		t.Fatalf("expected only the individually subscribed asset to remain, got %v", assetIDs)
+	}
+}
+
+func TestHubUnsubscribeWatchlistReleasesOnlyItsAssets(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub()
+	if err := hub.Add("session-1", "user-1"); err != nil {
+		t.Fatalf("failed to add session: %v", err)
+	}
+	hub.SubscribeAsset("session-1", 1)
+	hub.SubscribeWatchlist("session-1", 5, []int64{1, 2, 3})
+	hub.SubscribeWatchlist("session-1", 6, []int64{3, 4})
+
+	// Whatever the list holds now, only the assets it was subscribed with go.
+	if released := hub.UnsubscribeWatchlist("session-1", 5); len(released) != 3 {
+		t.Fatalf("expected 3 released assets, got %v", released)
+	}
+	assetIDs := hub.subscribers["session-1"].AssetIDs
+	for _, assetID := range []int64{1, 3, 4} {
+		if _, ok := assetIDs[assetID]; !ok {
+			t.Fatalf("expected asset %d to stay subscribed, got %v", assetID, assetIDs)
+		}
+	}
+	if _, ok := assetIDs[2]; ok || len(assetIDs) != 3 {
+		t.Fatalf("expected asset 2 to be released, got %v", assetIDs)
+	}
+
+	if released := hub.UnsubscribeWatchlist("session-1", 7); released != nil {
+		t.Fatalf("expected nothing released for an unsubscribed watchlist, got %v", released)
+	}
+}
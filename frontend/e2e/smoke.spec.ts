@@ -188,7 +188,7 @@ async function setupMockRoutes(page: Page) {
       await route.fulfill({
         status: 200,
         contentType: 'application/json',
-        body: JSON.stringify(lotsResponse(state))
+        body: JSON.stringify({ items: lotsResponse(state), next_cursor: null })
       });
       return;
     }
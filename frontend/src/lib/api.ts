@@ -42,6 +42,11 @@ interface LotDTO {
   purchased_at: string;
 }
 
+interface LotPageDTO {
+  items: LotDTO[];
+  next_cursor: string | null;
+}
+
 interface AssetDTO {
   id: number;
   symbol: string;
@@ -135,7 +140,19 @@ export async function fetchPositions(): Promise<Position[]> {
 }
 
 export async function fetchLots(): Promise<Lot[]> {
-  const rows = await apiRequest<LotDTO[]>('/api/v1/lots');
+  const rows: LotDTO[] = [];
+  let cursor: string | null = null;
+
+  do {
+    const params = new URLSearchParams({ limit: '500' });
+    if (cursor !== null) {
+      params.set('cursor', cursor);
+    }
+
+    const page: LotPageDTO = await apiRequest<LotPageDTO>(`/api/v1/lots?${params.toString()}`);
+    rows.push(...page.items);
+    cursor = page.next_cursor;
+  } while (cursor !== null);
 
   return rows.map((row) => ({
     id: Number(row.id),
@@ -0,0 +1,71 @@
+begin;
+
+create type public.alert_kind as enum ('price_above', 'price_below', 'position_drawdown', 'change_24h');
+
+create table if not exists public.alerts (
+  id bigserial primary key,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  asset_id bigint not null references public.assets(id) on delete cascade,
+  kind public.alert_kind not null,
+  threshold numeric(30, 10) not null,
+  cooldown_sec integer not null default 3600,
+  hysteresis_pct numeric(10, 6) not null default 0.01,
+  enabled boolean not null default true,
+  armed boolean not null default true,
+  last_triggered_at timestamptz,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  constraint alerts_threshold_positive check (threshold > 0),
+  constraint alerts_cooldown_non_negative check (cooldown_sec >= 0),
+  constraint alerts_hysteresis_range check (hysteresis_pct >= 0 and hysteresis_pct < 1)
+);
+
+create table if not exists public.alert_events (
+  id bigserial primary key,
+  alert_id bigint not null references public.alerts(id) on delete cascade,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  asset_id bigint not null references public.assets(id) on delete cascade,
+  kind public.alert_kind not null,
+  threshold numeric(30, 10) not null,
+  observed_value numeric(30, 10) not null,
+  price numeric(30, 10) not null,
+  triggered_at timestamptz not null default now()
+);
+
+create index if not exists alerts_user_id_idx on public.alerts (user_id);
+create index if not exists alerts_asset_enabled_idx on public.alerts (asset_id) where enabled;
+create index if not exists alert_events_user_triggered_idx on public.alert_events (user_id, triggered_at desc);
+
+create trigger alerts_set_updated_at
+before update on public.alerts
+for each row execute procedure public.set_updated_at();
+
+alter table public.alerts enable row level security;
+alter table public.alert_events enable row level security;
+
+create policy alerts_select_own
+on public.alerts
+for select
+using (user_id = auth.uid());
+
+create policy alerts_insert_own
+on public.alerts
+for insert
+with check (user_id = auth.uid());
+
+create policy alerts_update_own
+on public.alerts
+for update
+using (user_id = auth.uid());
+
+create policy alerts_delete_own
+on public.alerts
+for delete
+using (user_id = auth.uid());
+
+create policy alert_events_select_own
+on public.alert_events
+for select
+using (user_id = auth.uid());
+
+commit;
@@ -0,0 +1,68 @@
+begin;
+
+create table if not exists public.webhooks (
+  id bigserial primary key,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  url text not null,
+  secret text not null,
+  events text[] not null,
+  enabled boolean not null default true,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  constraint webhooks_url_http check (url ~* '^https?://'),
+  constraint webhooks_events_not_empty check (cardinality(events) > 0)
+);
+
+create table if not exists public.webhook_deliveries (
+  id bigserial primary key,
+  webhook_id bigint not null references public.webhooks(id) on delete cascade,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  event_type text not null,
+  payload jsonb not null,
+  status text not null default 'pending',
+  attempts integer not null default 0,
+  next_attempt_at timestamptz not null default now(),
+  last_status_code integer,
+  last_error text,
+  created_at timestamptz not null default now(),
+  delivered_at timestamptz,
+  constraint webhook_deliveries_status_valid check (status in ('pending', 'succeeded', 'failed'))
+);
+
+create index if not exists webhooks_user_id_idx on public.webhooks (user_id);
+create index if not exists webhook_deliveries_due_idx on public.webhook_deliveries (next_attempt_at) where status = 'pending';
+create index if not exists webhook_deliveries_webhook_created_idx on public.webhook_deliveries (webhook_id, created_at desc);
+
+create trigger webhooks_set_updated_at
+before update on public.webhooks
+for each row execute procedure public.set_updated_at();
+
+alter table public.webhooks enable row level security;
+alter table public.webhook_deliveries enable row level security;
+
+create policy webhooks_select_own
+on public.webhooks
+for select
+using (user_id = auth.uid());
+
+create policy webhooks_insert_own
+on public.webhooks
+for insert
+with check (user_id = auth.uid());
+
+create policy webhooks_update_own
+on public.webhooks
+for update
+using (user_id = auth.uid());
+
+create policy webhooks_delete_own
+on public.webhooks
+for delete
+using (user_id = auth.uid());
+
+create policy webhook_deliveries_select_own
+on public.webhook_deliveries
+for select
+using (user_id = auth.uid());
+
+commit;
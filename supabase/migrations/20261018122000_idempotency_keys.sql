@@ -0,0 +1,23 @@
+begin;
+
+create table if not exists public.idempotency_keys (
+  user_id uuid not null references auth.users(id) on delete cascade,
+  key text not null,
+  method text not null,
+  path text not null,
+  request_hash text not null,
+  status_code integer,
+  response_body bytea,
+  response_content_type text,
+  created_at timestamptz not null default now(),
+  expires_at timestamptz not null,
+  primary key (user_id, key),
+  constraint idempotency_keys_key_length check (char_length(key) between 1 and 255)
+);
+
+create index if not exists idempotency_keys_expires_at_idx on public.idempotency_keys (expires_at);
+
+-- Only the API server (service role) reads or writes idempotency keys.
+alter table public.idempotency_keys enable row level security;
+
+commit;
@@ -0,0 +1,9 @@
+begin;
+
+-- Keyset pagination for GET /api/v1/lots: (sort column, id) per user, with an
+-- asset-scoped variant for the asset_id filter.
+create index if not exists lots_user_purchased_id_idx on public.lots (user_id, purchased_at desc, id desc);
+create index if not exists lots_user_created_id_idx on public.lots (user_id, created_at desc, id desc);
+create index if not exists lots_user_asset_purchased_id_idx on public.lots (user_id, asset_id, purchased_at desc, id desc);
+
+commit;
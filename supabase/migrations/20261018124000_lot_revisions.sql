@@ -0,0 +1,21 @@
+begin;
+
+-- Lots carry a revision that is bumped on every update; the API exposes it as
+-- the lot's ETag for If-Match preconditions.
+alter table public.lots add column if not exists revision bigint not null default 1;
+
+create or replace function public.bump_revision()
+returns trigger
+language plpgsql
+as $$
+begin
+  new.revision = old.revision + 1;
+  return new;
+end;
+$$;
+
+create trigger lots_bump_revision
+before update on public.lots
+for each row execute procedure public.bump_revision();
+
+commit;
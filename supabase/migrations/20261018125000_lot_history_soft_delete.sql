@@ -0,0 +1,102 @@
+begin;
+
+-- Lots are soft-deleted so they can be restored for a retention window; the
+-- worker purges them afterwards.
+alter table public.lots add column if not exists deleted_at timestamptz;
+
+create index if not exists lots_deleted_at_idx on public.lots (deleted_at) where deleted_at is not null;
+
+-- Append-only audit trail of lot changes. lot_id has no foreign key so the
+-- history outlives purged lots.
+create table if not exists public.lot_events (
+  id bigserial primary key,
+  lot_id bigint not null,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  event_type text not null,
+  actor text not null,
+  before jsonb,
+  after jsonb,
+  created_at timestamptz not null default now(),
+  constraint lot_events_type_valid check (event_type in ('created', 'updated', 'deleted', 'restored'))
+);
+
+create index if not exists lot_events_user_lot_id_idx on public.lot_events (user_id, lot_id, id);
+
+-- The actor is taken from the app.actor setting when a job sets it, then the
+-- signed-in Supabase user, then the lot owner.
+create or replace function public.record_lot_event()
+returns trigger
+language plpgsql
+security definer
+set search_path = public
+as $$
+declare
+  kind text;
+  before_row jsonb;
+begin
+  if tg_op = 'INSERT' then
+    kind := 'created';
+  elsif old.deleted_at is null and new.deleted_at is not null then
+    kind := 'deleted';
+  elsif old.deleted_at is not null and new.deleted_at is null then
+    kind := 'restored';
+  else
+    kind := 'updated';
+  end if;
+
+  if tg_op = 'UPDATE' then
+    before_row := to_jsonb(old) - 'user_id';
+  end if;
+
+  insert into public.lot_events (lot_id, user_id, event_type, actor, before, after)
+  values (
+    new.id,
+    new.user_id,
+    kind,
+    coalesce(nullif(current_setting('app.actor', true), ''), auth.uid()::text, new.user_id::text),
+    before_row,
+    to_jsonb(new) - 'user_id'
+  );
+  return new;
+end;
+$$;
+
+create trigger lots_record_event
+after insert or update on public.lots
+for each row execute procedure public.record_lot_event();
+
+create or replace view public.positions_view as
+select
+  l.user_id,
+  l.asset_id,
+  sum(l.quantity) as total_qty,
+  sum(l.quantity * l.unit_cost) / nullif(sum(l.quantity), 0) as avg_cost,
+  pc.price as current_price,
+  (pc.price - (sum(l.quantity * l.unit_cost) / nullif(sum(l.quantity), 0))) * sum(l.quantity) as unrealized_pl
+from public.lots l
+left join public.prices_current pc on pc.asset_id = l.asset_id
+where l.deleted_at is null
+group by l.user_id, l.asset_id, pc.price;
+
+create or replace view public.lot_performance_view as
+select
+  l.id as lot_id,
+  l.user_id,
+  l.asset_id,
+  l.quantity,
+  l.unit_cost,
+  l.purchased_at,
+  pc.price as current_price,
+  (pc.price - l.unit_cost) * l.quantity as unrealized_pl
+from public.lots l
+left join public.prices_current pc on pc.asset_id = l.asset_id
+where l.deleted_at is null;
+
+alter table public.lot_events enable row level security;
+
+create policy lot_events_select_own
+on public.lot_events
+for select
+using (user_id = auth.uid());
+
+commit;
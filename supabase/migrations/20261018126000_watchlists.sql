@@ -0,0 +1,69 @@
+begin;
+
+create table if not exists public.watchlists (
+  id bigserial primary key,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  name text not null,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  constraint watchlists_name_length check (char_length(name) between 1 and 100),
+  constraint watchlists_user_name_unique unique (user_id, name)
+);
+
+-- Watched assets are priced by the worker like held ones.
+create table if not exists public.watchlist_assets (
+  watchlist_id bigint not null references public.watchlists(id) on delete cascade,
+  asset_id bigint not null references public.assets(id) on delete cascade,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  added_at timestamptz not null default now(),
+  primary key (watchlist_id, asset_id)
+);
+
+create index if not exists watchlist_assets_asset_id_idx on public.watchlist_assets (asset_id);
+
+create trigger watchlists_set_updated_at
+before update on public.watchlists
+for each row execute procedure public.set_updated_at();
+
+alter table public.watchlists enable row level security;
+alter table public.watchlist_assets enable row level security;
+
+create policy watchlists_select_own
+on public.watchlists
+for select
+using (user_id = auth.uid());
+
+create policy watchlists_insert_own
+on public.watchlists
+for insert
+with check (user_id = auth.uid());
+
+create policy watchlists_update_own
+on public.watchlists
+for update
+using (user_id = auth.uid());
+
+create policy watchlists_delete_own
+on public.watchlists
+for delete
+using (user_id = auth.uid());
+
+create policy watchlist_assets_select_own
+on public.watchlist_assets
+for select
+using (user_id = auth.uid());
+
+create policy watchlist_assets_insert_own
+on public.watchlist_assets
+for insert
+with check (
+  user_id = auth.uid()
+  and exists (select 1 from public.watchlists w where w.id = watchlist_id and w.user_id = auth.uid())
+);
+
+create policy watchlist_assets_delete_own
+on public.watchlist_assets
+for delete
+using (user_id = auth.uid());
+
+commit;
@@ -0,0 +1,56 @@
+begin;
+
+create type public.corporate_action_kind as enum ('split', 'reverse_split', 'ticker_change', 'redenomination');
+
+-- ratio is new units per old unit: a 2-for-1 split is 2, a 1-for-10 reverse
+-- split is 0.1. Ticker changes carry new_symbol instead.
+create table if not exists public.corporate_actions (
+  id bigserial primary key,
+  asset_id bigint not null references public.assets(id) on delete cascade,
+  kind public.corporate_action_kind not null,
+  ratio numeric(30, 10),
+  new_symbol text,
+  previous_symbol text,
+  effective_at timestamptz not null,
+  notes text,
+  created_by uuid references auth.users(id) on delete set null,
+  created_at timestamptz not null default now(),
+  applied_at timestamptz,
+  constraint corporate_actions_kind_fields check (
+    (kind = 'ticker_change' and ratio is null and new_symbol is not null)
+    or (kind <> 'ticker_change' and ratio > 0 and new_symbol is null)
+  )
+);
+
+-- One row per lot changed by an applied action.
+create table if not exists public.corporate_action_adjustments (
+  id bigserial primary key,
+  action_id bigint not null references public.corporate_actions(id) on delete cascade,
+  lot_id bigint not null,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  quantity_before numeric(30, 10) not null,
+  quantity_after numeric(30, 10) not null,
+  unit_cost_before numeric(30, 10) not null,
+  unit_cost_after numeric(30, 10) not null,
+  created_at timestamptz not null default now(),
+  unique (action_id, lot_id)
+);
+
+create index if not exists corporate_actions_asset_effective_idx on public.corporate_actions (asset_id, effective_at desc);
+create index if not exists corporate_action_adjustments_user_lot_idx on public.corporate_action_adjustments (user_id, lot_id);
+
+alter table public.corporate_actions enable row level security;
+alter table public.corporate_action_adjustments enable row level security;
+
+create policy corporate_actions_select_authenticated
+on public.corporate_actions
+for select
+to authenticated
+using (true);
+
+create policy corporate_action_adjustments_select_own
+on public.corporate_action_adjustments
+for select
+using (user_id = auth.uid());
+
+commit;
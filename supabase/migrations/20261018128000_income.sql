@@ -0,0 +1,74 @@
+begin;
+
+create type public.income_kind as enum ('dividend', 'staking_reward', 'interest', 'airdrop');
+
+-- amount is the value received, in currency. lot_id points at the lot created
+-- when the income was reinvested.
+create table if not exists public.income (
+  id bigserial primary key,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  asset_id bigint not null references public.assets(id) on delete cascade,
+  kind public.income_kind not null,
+  amount numeric(30, 10) not null,
+  currency text not null default 'USD',
+  received_at timestamptz not null,
+  lot_id bigint references public.lots(id) on delete set null,
+  notes text,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  constraint income_amount_positive check (amount > 0),
+  constraint income_currency_format check (currency ~ '^[A-Z0-9]{2,10}$')
+);
+
+create index if not exists income_user_received_idx on public.income (user_id, received_at desc, id desc);
+create index if not exists income_user_asset_idx on public.income (user_id, asset_id);
+
+create trigger income_set_updated_at
+before update on public.income
+for each row execute procedure public.set_updated_at();
+
+-- income_received sums USD income only; other currencies are listed by the
+-- income API but not converted.
+create or replace view public.positions_view as
+select
+  l.user_id,
+  l.asset_id,
+  sum(l.quantity) as total_qty,
+  sum(l.quantity * l.unit_cost) / nullif(sum(l.quantity), 0) as avg_cost,
+  pc.price as current_price,
+  (pc.price - (sum(l.quantity * l.unit_cost) / nullif(sum(l.quantity), 0))) * sum(l.quantity) as unrealized_pl,
+  coalesce(i.income_received, 0) as income_received
+from public.lots l
+left join public.prices_current pc on pc.asset_id = l.asset_id
+left join (
+  select user_id, asset_id, sum(amount) as income_received
+  from public.income
+  where currency = 'USD'
+  group by user_id, asset_id
+) i on i.user_id = l.user_id and i.asset_id = l.asset_id
+where l.deleted_at is null
+group by l.user_id, l.asset_id, pc.price, i.income_received;
+
+alter table public.income enable row level security;
+
+create policy income_select_own
+on public.income
+for select
+using (user_id = auth.uid());
+
+create policy income_insert_own
+on public.income
+for insert
+with check (user_id = auth.uid());
+
+create policy income_update_own
+on public.income
+for update
+using (user_id = auth.uid());
+
+create policy income_delete_own
+on public.income
+for delete
+using (user_id = auth.uid());
+
+commit;
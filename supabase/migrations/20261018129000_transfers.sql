@@ -0,0 +1,57 @@
+begin;
+
+-- location is a free-form custodian label such as an exchange or wallet name;
+-- null means the lot has not been labelled.
+alter table public.lots add column if not exists location text;
+alter table public.lots add constraint lots_location_length check (char_length(location) between 1 and 100);
+
+create index if not exists lots_user_asset_location_idx on public.lots (user_id, asset_id, location) where deleted_at is null;
+
+-- A transfer moves quantity of one asset to to_location. from_location is set
+-- for FIFO transfers; transfers of specific lots record each lot's location
+-- on its leg. fee_quantity units are lost in transit.
+create table if not exists public.transfers (
+  id bigserial primary key,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  asset_id bigint not null references public.assets(id) on delete cascade,
+  from_location text,
+  to_location text not null,
+  quantity numeric(30, 10) not null,
+  fee_quantity numeric(30, 10) not null default 0,
+  transferred_at timestamptz not null,
+  notes text,
+  created_at timestamptz not null default now(),
+  constraint transfers_quantity_positive check (quantity > 0),
+  constraint transfers_fee_range check (fee_quantity >= 0 and fee_quantity < quantity)
+);
+
+-- One row per source lot. destination_lot_id equals source_lot_id when the
+-- whole lot moved.
+create table if not exists public.transfer_legs (
+  id bigserial primary key,
+  transfer_id bigint not null references public.transfers(id) on delete cascade,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  source_lot_id bigint not null,
+  destination_lot_id bigint not null,
+  from_location text,
+  quantity numeric(30, 10) not null,
+  received_quantity numeric(30, 10) not null
+);
+
+create index if not exists transfers_user_transferred_idx on public.transfers (user_id, transferred_at desc, id desc);
+create index if not exists transfer_legs_transfer_id_idx on public.transfer_legs (transfer_id);
+
+alter table public.transfers enable row level security;
+alter table public.transfer_legs enable row level security;
+
+create policy transfers_select_own
+on public.transfers
+for select
+using (user_id = auth.uid());
+
+create policy transfer_legs_select_own
+on public.transfer_legs
+for select
+using (user_id = auth.uid());
+
+commit;
@@ -0,0 +1,37 @@
+begin;
+
+-- A target weight for one asset or for every asset of a type. Weights are
+-- fractions of the portfolio; whatever is left over is held as cash.
+create table if not exists public.allocation_targets (
+  id bigserial primary key,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  asset_id bigint references public.assets(id) on delete cascade,
+  asset_type public.asset_type,
+  weight numeric(10, 8) not null,
+  created_at timestamptz not null default now(),
+  constraint allocation_targets_one_subject check ((asset_id is null) <> (asset_type is null)),
+  constraint allocation_targets_weight_range check (weight > 0 and weight <= 1),
+  constraint allocation_targets_user_asset_unique unique (user_id, asset_id),
+  constraint allocation_targets_user_type_unique unique (user_id, asset_type)
+);
+
+create index if not exists allocation_targets_asset_id_idx on public.allocation_targets (asset_id) where asset_id is not null;
+
+alter table public.allocation_targets enable row level security;
+
+create policy allocation_targets_select_own
+on public.allocation_targets
+for select
+using (user_id = auth.uid());
+
+create policy allocation_targets_insert_own
+on public.allocation_targets
+for insert
+with check (user_id = auth.uid());
+
+create policy allocation_targets_delete_own
+on public.allocation_targets
+for delete
+using (user_id = auth.uid());
+
+commit;
@@ -0,0 +1,31 @@
+begin;
+
+-- Assets a user compares their portfolio against. The worker prices them even
+-- when nobody holds them.
+create table if not exists public.benchmarks (
+  user_id uuid not null references auth.users(id) on delete cascade,
+  asset_id bigint not null references public.assets(id) on delete cascade,
+  created_at timestamptz not null default now(),
+  primary key (user_id, asset_id)
+);
+
+create index if not exists benchmarks_asset_id_idx on public.benchmarks (asset_id);
+
+alter table public.benchmarks enable row level security;
+
+create policy benchmarks_select_own
+on public.benchmarks
+for select
+using (user_id = auth.uid());
+
+create policy benchmarks_insert_own
+on public.benchmarks
+for insert
+with check (user_id = auth.uid());
+
+create policy benchmarks_delete_own
+on public.benchmarks
+for delete
+using (user_id = auth.uid());
+
+commit;
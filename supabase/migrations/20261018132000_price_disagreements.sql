@@ -0,0 +1,20 @@
+begin;
+
+-- Refreshes in quorum mode where a provider's quote strayed from the median by
+-- more than the tolerance. Rejected rows kept the last good price.
+create table if not exists public.price_disagreements (
+  id bigserial primary key,
+  asset_id bigint not null references public.assets(id) on delete cascade,
+  median numeric(30, 10) not null,
+  tolerance numeric(10, 6) not null,
+  quotes jsonb not null,
+  rejected boolean not null,
+  detected_at timestamptz not null default now()
+);
+
+create index if not exists price_disagreements_asset_detected_idx on public.price_disagreements (asset_id, detected_at desc);
+
+-- Service role only: no policies are defined for price disagreements.
+alter table public.price_disagreements enable row level security;
+
+commit;
@@ -0,0 +1,37 @@
+-- Optional market data reported with a quote. provider_as_of is the
+-- provider's last-updated time; fetched_at stays the worker's clock.
+alter table public.prices_current
+  add column if not exists change_24h_pct numeric(20, 8),
+  add column if not exists volume_24h numeric(30, 4),
+  add column if not exists market_cap numeric(30, 4),
+  add column if not exists provider_as_of timestamptz;
+
+alter table public.price_snapshots
+  add column if not exists change_24h_pct numeric(20, 8),
+  add column if not exists volume_24h numeric(30, 4),
+  add column if not exists market_cap numeric(30, 4),
+  add column if not exists provider_as_of timestamptz;
+
+create or replace view public.positions_view as
+select
+  l.user_id,
+  l.asset_id,
+  sum(l.quantity) as total_qty,
+  sum(l.quantity * l.unit_cost) / nullif(sum(l.quantity), 0) as avg_cost,
+  pc.price as current_price,
+  (pc.price - (sum(l.quantity * l.unit_cost) / nullif(sum(l.quantity), 0))) * sum(l.quantity) as unrealized_pl,
+  coalesce(i.income_received, 0) as income_received,
+  pc.change_24h_pct,
+  pc.volume_24h,
+  pc.market_cap,
+  pc.provider_as_of as price_as_of
+from public.lots l
+left join public.prices_current pc on pc.asset_id = l.asset_id
+left join (
+  select user_id, asset_id, sum(amount) as income_received
+  from public.income
+  where currency = 'USD'
+  group by user_id, asset_id
+) i on i.user_id = l.user_id and i.asset_id = l.asset_id
+where l.deleted_at is null
+group by l.user_id, l.asset_id, pc.price, i.income_received, pc.change_24h_pct, pc.volume_24h, pc.market_cap, pc.provider_as_of;
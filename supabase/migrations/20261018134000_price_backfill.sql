@@ -0,0 +1,23 @@
+begin;
+
+-- Daily prices fetched from a provider's history endpoint for days before the
+-- worker first priced the asset. One backfilled row per asset and day keeps
+-- the backfill idempotent.
+alter table public.price_snapshots
+  add column if not exists backfilled boolean not null default false;
+
+create unique index if not exists price_snapshots_backfilled_uidx on public.price_snapshots (asset_id, fetched_at) where backfilled;
+
+-- Backfill progress per asset: every day from covered_from onwards is done.
+-- last_error is the most recent failure, retried after a day.
+create table if not exists public.price_backfills (
+  asset_id bigint primary key references public.assets(id) on delete cascade,
+  covered_from timestamptz,
+  last_error text,
+  attempted_at timestamptz not null default now()
+);
+
+-- Service role only: no policies are defined for price backfills.
+alter table public.price_backfills enable row level security;
+
+commit;
@@ -0,0 +1,8 @@
+begin;
+
+-- Response headers replayed with a stored idempotent response, such as the
+-- ETag a client needs for its next If-Match.
+alter table public.idempotency_keys
+  add column if not exists response_headers jsonb not null default '{}'::jsonb;
+
+commit;
@@ -4,9 +4,29 @@ begin;
 alter table public.profiles enable row level security;
 alter table public.user_settings enable row level security;
 alter table public.lots enable row level security;
+alter table public.lot_events enable row level security;
 alter table public.assets enable row level security;
 alter table public.prices_current enable row level security;
 alter table public.price_snapshots enable row level security;
+alter table public.alerts enable row level security;
+alter table public.alert_events enable row level security;
+alter table public.webhooks enable row level security;
+alter table public.webhook_deliveries enable row level security;
+alter table public.watchlists enable row level security;
+alter table public.watchlist_assets enable row level security;
+alter table public.corporate_actions enable row level security;
+alter table public.corporate_action_adjustments enable row level security;
+alter table public.income enable row level security;
+alter table public.transfers enable row level security;
+alter table public.transfer_legs enable row level security;
+alter table public.allocation_targets enable row level security;
+alter table public.benchmarks enable row level security;
+-- Service role only: no policies are defined for idempotency keys.
+alter table public.idempotency_keys enable row level security;
+-- Service role only: no policies are defined for price disagreements.
+alter table public.price_disagreements enable row level security;
+-- Service role only: no policies are defined for price backfills.
+alter table public.price_backfills enable row level security;
 
 -- Profiles
 create policy profiles_select_own
@@ -61,6 +81,12 @@ on public.lots
 for delete
 using (user_id = auth.uid());
 
+-- Lot events are append-only and written by a trigger.
+create policy lot_events_select_own
+on public.lot_events
+for select
+using (user_id = auth.uid());
+
 -- Assets (read-only for authenticated users)
 create policy assets_select_authenticated
 on public.assets
@@ -81,4 +107,171 @@ for select
 to authenticated
 using (true);
 
+-- Alerts
+create policy alerts_select_own
+on public.alerts
+for select
+using (user_id = auth.uid());
+
+create policy alerts_insert_own
+on public.alerts
+for insert
+with check (user_id = auth.uid());
+
+create policy alerts_update_own
+on public.alerts
+for update
+using (user_id = auth.uid());
+
+create policy alerts_delete_own
+on public.alerts
+for delete
+using (user_id = auth.uid());
+
+create policy alert_events_select_own
+on public.alert_events
+for select
+using (user_id = auth.uid());
+
+-- Webhooks
+create policy webhooks_select_own
+on public.webhooks
+for select
+using (user_id = auth.uid());
+
+create policy webhooks_insert_own
+on public.webhooks
+for insert
+with check (user_id = auth.uid());
+
+create policy webhooks_update_own
+on public.webhooks
+for update
+using (user_id = auth.uid());
+
+create policy webhooks_delete_own
+on public.webhooks
+for delete
+using (user_id = auth.uid());
+
+create policy webhook_deliveries_select_own
+on public.webhook_deliveries
+for select
+using (user_id = auth.uid());
+
+-- Watchlists
+create policy watchlists_select_own
+on public.watchlists
+for select
+using (user_id = auth.uid());
+
+create policy watchlists_insert_own
+on public.watchlists
+for insert
+with check (user_id = auth.uid());
+
+create policy watchlists_update_own
+on public.watchlists
+for update
+using (user_id = auth.uid());
+
+create policy watchlists_delete_own
+on public.watchlists
+for delete
+using (user_id = auth.uid());
+
+create policy watchlist_assets_select_own
+on public.watchlist_assets
+for select
+using (user_id = auth.uid());
+
+create policy watchlist_assets_insert_own
+on public.watchlist_assets
+for insert
+with check (
+  user_id = auth.uid()
+  and exists (select 1 from public.watchlists w where w.id = watchlist_id and w.user_id = auth.uid())
+);
+
+create policy watchlist_assets_delete_own
+on public.watchlist_assets
+for delete
+using (user_id = auth.uid());
+
+-- Corporate actions (written by admins through the API)
+create policy corporate_actions_select_authenticated
+on public.corporate_actions
+for select
+to authenticated
+using (true);
+
+create policy corporate_action_adjustments_select_own
+on public.corporate_action_adjustments
+for select
+using (user_id = auth.uid());
+
+-- Income
+create policy income_select_own
+on public.income
+for select
+using (user_id = auth.uid());
+
+create policy income_insert_own
+on public.income
+for insert
+with check (user_id = auth.uid());
+
+create policy income_update_own
+on public.income
+for update
+using (user_id = auth.uid());
+
+create policy income_delete_own
+on public.income
+for delete
+using (user_id = auth.uid());
+
+-- Transfers (written through the API)
+create policy transfers_select_own
+on public.transfers
+for select
+using (user_id = auth.uid());
+
+create policy transfer_legs_select_own
+on public.transfer_legs
+for select
+using (user_id = auth.uid());
+
+-- Allocation targets
+create policy allocation_targets_select_own
+on public.allocation_targets
+for select
+using (user_id = auth.uid());
+
+create policy allocation_targets_insert_own
+on public.allocation_targets
+for insert
+with check (user_id = auth.uid());
+
+create policy allocation_targets_delete_own
+on public.allocation_targets
+for delete
+using (user_id = auth.uid());
+
+-- Benchmarks
+create policy benchmarks_select_own
+on public.benchmarks
+for select
+using (user_id = auth.uid());
+
+create policy benchmarks_insert_own
+on public.benchmarks
+for insert
+with check (user_id = auth.uid());
+
+create policy benchmarks_delete_own
+on public.benchmarks
+for delete
+using (user_id = auth.uid());
+
 commit;
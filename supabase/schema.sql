@@ -2,6 +2,9 @@ begin;
 
 -- Types
 create type public.asset_type as enum ('crypto', 'stock');
+create type public.alert_kind as enum ('price_above', 'price_below', 'position_drawdown', 'change_24h');
+create type public.corporate_action_kind as enum ('split', 'reverse_split', 'ticker_change', 'redenomination');
+create type public.income_kind as enum ('dividend', 'staking_reward', 'interest', 'airdrop');
 
 -- Core tables
 create table if not exists public.assets (
@@ -57,15 +60,26 @@ create table if not exists public.lots (
   purchased_at timestamptz not null,
   created_at timestamptz not null default now(),
   updated_at timestamptz not null default now(),
+  revision bigint not null default 1,
+  deleted_at timestamptz,
+  -- Free-form custodian label such as an exchange or wallet name.
+  location text,
   constraint lots_quantity_positive check (quantity > 0),
-  constraint lots_unit_cost_non_negative check (unit_cost >= 0)
+  constraint lots_unit_cost_non_negative check (unit_cost >= 0),
+  constraint lots_location_length check (char_length(location) between 1 and 100)
 );
 
+-- change_24h_pct, volume_24h, market_cap and provider_as_of are reported by
+-- some providers only; provider_as_of is the provider's last-updated time.
 create table if not exists public.prices_current (
   asset_id bigint primary key references public.assets(id) on delete cascade,
   price numeric(30, 10) not null,
   fetched_at timestamptz not null,
-  provider text not null
+  provider text not null,
+  change_24h_pct numeric(20, 8),
+  volume_24h numeric(30, 4),
+  market_cap numeric(30, 4),
+  provider_as_of timestamptz
 );
 
 create table if not exists public.price_snapshots (
@@ -73,15 +87,277 @@ create table if not exists public.price_snapshots (
   asset_id bigint not null references public.assets(id) on delete cascade,
   price numeric(30, 10) not null,
   fetched_at timestamptz not null,
-  provider text not null
+  provider text not null,
+  change_24h_pct numeric(20, 8),
+  volume_24h numeric(30, 4),
+  market_cap numeric(30, 4),
+  provider_as_of timestamptz,
+  backfilled boolean not null default false
+);
+
+-- Backfill progress per asset: every day from covered_from onwards is done.
+-- last_error is the most recent failure, retried after a day.
+create table if not exists public.price_backfills (
+  asset_id bigint primary key references public.assets(id) on delete cascade,
+  covered_from timestamptz,
+  last_error text,
+  attempted_at timestamptz not null default now()
+);
+
+-- Refreshes in quorum mode where a provider's quote strayed from the median by
+-- more than the tolerance. Rejected rows kept the last good price.
+create table if not exists public.price_disagreements (
+  id bigserial primary key,
+  asset_id bigint not null references public.assets(id) on delete cascade,
+  median numeric(30, 10) not null,
+  tolerance numeric(10, 6) not null,
+  quotes jsonb not null,
+  rejected boolean not null,
+  detected_at timestamptz not null default now()
+);
+
+create table if not exists public.alerts (
+  id bigserial primary key,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  asset_id bigint not null references public.assets(id) on delete cascade,
+  kind public.alert_kind not null,
+  threshold numeric(30, 10) not null,
+  cooldown_sec integer not null default 3600,
+  hysteresis_pct numeric(10, 6) not null default 0.01,
+  enabled boolean not null default true,
+  armed boolean not null default true,
+  last_triggered_at timestamptz,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  constraint alerts_threshold_positive check (threshold > 0),
+  constraint alerts_cooldown_non_negative check (cooldown_sec >= 0),
+  constraint alerts_hysteresis_range check (hysteresis_pct >= 0 and hysteresis_pct < 1)
+);
+
+create table if not exists public.alert_events (
+  id bigserial primary key,
+  alert_id bigint not null references public.alerts(id) on delete cascade,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  asset_id bigint not null references public.assets(id) on delete cascade,
+  kind public.alert_kind not null,
+  threshold numeric(30, 10) not null,
+  observed_value numeric(30, 10) not null,
+  price numeric(30, 10) not null,
+  triggered_at timestamptz not null default now()
+);
+
+create table if not exists public.webhooks (
+  id bigserial primary key,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  url text not null,
+  secret text not null,
+  events text[] not null,
+  enabled boolean not null default true,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  constraint webhooks_url_http check (url ~* '^https?://'),
+  constraint webhooks_events_not_empty check (cardinality(events) > 0)
+);
+
+create table if not exists public.webhook_deliveries (
+  id bigserial primary key,
+  webhook_id bigint not null references public.webhooks(id) on delete cascade,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  event_type text not null,
+  payload jsonb not null,
+  status text not null default 'pending',
+  attempts integer not null default 0,
+  next_attempt_at timestamptz not null default now(),
+  last_status_code integer,
+  last_error text,
+  created_at timestamptz not null default now(),
+  delivered_at timestamptz,
+  constraint webhook_deliveries_status_valid check (status in ('pending', 'succeeded', 'failed'))
+);
+
+create table if not exists public.idempotency_keys (
+  user_id uuid not null references auth.users(id) on delete cascade,
+  key text not null,
+  method text not null,
+  path text not null,
+  request_hash text not null,
+  status_code integer,
+  response_body bytea,
+  response_content_type text,
+  response_headers jsonb not null default '{}'::jsonb,
+  created_at timestamptz not null default now(),
+  expires_at timestamptz not null,
+  primary key (user_id, key),
+  constraint idempotency_keys_key_length check (char_length(key) between 1 and 255)
+);
+
+create table if not exists public.lot_events (
+  id bigserial primary key,
+  lot_id bigint not null,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  event_type text not null,
+  actor text not null,
+  before jsonb,
+  after jsonb,
+  created_at timestamptz not null default now(),
+  constraint lot_events_type_valid check (event_type in ('created', 'updated', 'deleted', 'restored'))
+);
+
+create table if not exists public.watchlists (
+  id bigserial primary key,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  name text not null,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  constraint watchlists_name_length check (char_length(name) between 1 and 100),
+  constraint watchlists_user_name_unique unique (user_id, name)
+);
+
+create table if not exists public.watchlist_assets (
+  watchlist_id bigint not null references public.watchlists(id) on delete cascade,
+  asset_id bigint not null references public.assets(id) on delete cascade,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  added_at timestamptz not null default now(),
+  primary key (watchlist_id, asset_id)
+);
+
+-- ratio is new units per old unit: a 2-for-1 split is 2, a 1-for-10 reverse
+-- split is 0.1. Ticker changes carry new_symbol instead.
+create table if not exists public.corporate_actions (
+  id bigserial primary key,
+  asset_id bigint not null references public.assets(id) on delete cascade,
+  kind public.corporate_action_kind not null,
+  ratio numeric(30, 10),
+  new_symbol text,
+  previous_symbol text,
+  effective_at timestamptz not null,
+  notes text,
+  created_by uuid references auth.users(id) on delete set null,
+  created_at timestamptz not null default now(),
+  applied_at timestamptz,
+  constraint corporate_actions_kind_fields check (
+    (kind = 'ticker_change' and ratio is null and new_symbol is not null)
+    or (kind <> 'ticker_change' and ratio > 0 and new_symbol is null)
+  )
+);
+
+-- One row per lot changed by an applied action.
+create table if not exists public.corporate_action_adjustments (
+  id bigserial primary key,
+  action_id bigint not null references public.corporate_actions(id) on delete cascade,
+  lot_id bigint not null,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  quantity_before numeric(30, 10) not null,
+  quantity_after numeric(30, 10) not null,
+  unit_cost_before numeric(30, 10) not null,
+  unit_cost_after numeric(30, 10) not null,
+  created_at timestamptz not null default now(),
+  unique (action_id, lot_id)
+);
+
+-- amount is the value received, in currency. lot_id points at the lot created
+-- when the income was reinvested.
+create table if not exists public.income (
+  id bigserial primary key,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  asset_id bigint not null references public.assets(id) on delete cascade,
+  kind public.income_kind not null,
+  amount numeric(30, 10) not null,
+  currency text not null default 'USD',
+  received_at timestamptz not null,
+  lot_id bigint references public.lots(id) on delete set null,
+  notes text,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  constraint income_amount_positive check (amount > 0),
+  constraint income_currency_format check (currency ~ '^[A-Z0-9]{2,10}$')
+);
+
+-- A transfer moves quantity of one asset to to_location. from_location is set
+-- for FIFO transfers; transfers of specific lots record each lot's location
+-- on its leg. fee_quantity units are lost in transit.
+create table if not exists public.transfers (
+  id bigserial primary key,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  asset_id bigint not null references public.assets(id) on delete cascade,
+  from_location text,
+  to_location text not null,
+  quantity numeric(30, 10) not null,
+  fee_quantity numeric(30, 10) not null default 0,
+  transferred_at timestamptz not null,
+  notes text,
+  created_at timestamptz not null default now(),
+  constraint transfers_quantity_positive check (quantity > 0),
+  constraint transfers_fee_range check (fee_quantity >= 0 and fee_quantity < quantity)
+);
+
+-- One row per source lot. destination_lot_id equals source_lot_id when the
+-- whole lot moved.
+create table if not exists public.transfer_legs (
+  id bigserial primary key,
+  transfer_id bigint not null references public.transfers(id) on delete cascade,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  source_lot_id bigint not null,
+  destination_lot_id bigint not null,
+  from_location text,
+  quantity numeric(30, 10) not null,
+  received_quantity numeric(30, 10) not null
+);
+
+-- A target weight for one asset or for every asset of a type. Weights are
+-- fractions of the portfolio; whatever is left over is held as cash.
+create table if not exists public.allocation_targets (
+  id bigserial primary key,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  asset_id bigint references public.assets(id) on delete cascade,
+  asset_type public.asset_type,
+  weight numeric(10, 8) not null,
+  created_at timestamptz not null default now(),
+  constraint allocation_targets_one_subject check ((asset_id is null) <> (asset_type is null)),
+  constraint allocation_targets_weight_range check (weight > 0 and weight <= 1),
+  constraint allocation_targets_user_asset_unique unique (user_id, asset_id),
+  constraint allocation_targets_user_type_unique unique (user_id, asset_type)
+);
+
+-- Assets a user compares their portfolio against. The worker prices them even
+-- when nobody holds them.
+create table if not exists public.benchmarks (
+  user_id uuid not null references auth.users(id) on delete cascade,
+  asset_id bigint not null references public.assets(id) on delete cascade,
+  created_at timestamptz not null default now(),
+  primary key (user_id, asset_id)
 );
 
 -- Indexes
 create index if not exists lots_user_id_idx on public.lots (user_id);
 create index if not exists lots_asset_id_idx on public.lots (asset_id);
+create index if not exists lots_user_purchased_id_idx on public.lots (user_id, purchased_at desc, id desc);
+create index if not exists lots_user_created_id_idx on public.lots (user_id, created_at desc, id desc);
+create index if not exists lots_user_asset_purchased_id_idx on public.lots (user_id, asset_id, purchased_at desc, id desc);
 create index if not exists lots_user_asset_idx on public.lots (user_id, asset_id);
+create index if not exists lots_deleted_at_idx on public.lots (deleted_at) where deleted_at is not null;
+create index if not exists lots_user_asset_location_idx on public.lots (user_id, asset_id, location) where deleted_at is null;
+create index if not exists lot_events_user_lot_id_idx on public.lot_events (user_id, lot_id, id);
+create index if not exists watchlist_assets_asset_id_idx on public.watchlist_assets (asset_id);
+create index if not exists corporate_actions_asset_effective_idx on public.corporate_actions (asset_id, effective_at desc);
+create index if not exists corporate_action_adjustments_user_lot_idx on public.corporate_action_adjustments (user_id, lot_id);
+create index if not exists income_user_received_idx on public.income (user_id, received_at desc, id desc);
+create index if not exists income_user_asset_idx on public.income (user_id, asset_id);
+create index if not exists transfers_user_transferred_idx on public.transfers (user_id, transferred_at desc, id desc);
+create index if not exists transfer_legs_transfer_id_idx on public.transfer_legs (transfer_id);
+create index if not exists allocation_targets_asset_id_idx on public.allocation_targets (asset_id) where asset_id is not null;
+create index if not exists benchmarks_asset_id_idx on public.benchmarks (asset_id);
 create unique index if not exists assets_crypto_market_data_id_idx on public.assets (market_data_id) where type = 'crypto' and market_data_id is not null;
 create index if not exists price_snapshots_asset_fetched_idx on public.price_snapshots (asset_id, fetched_at desc);
+create unique index if not exists price_snapshots_backfilled_uidx on public.price_snapshots (asset_id, fetched_at) where backfilled;
+create index if not exists price_disagreements_asset_detected_idx on public.price_disagreements (asset_id, detected_at desc);
+create index if not exists alerts_user_id_idx on public.alerts (user_id);
+create index if not exists alerts_asset_enabled_idx on public.alerts (asset_id) where enabled;
+create index if not exists alert_events_user_triggered_idx on public.alert_events (user_id, triggered_at desc);
+create index if not exists webhooks_user_id_idx on public.webhooks (user_id);
+create index if not exists webhook_deliveries_due_idx on public.webhook_deliveries (next_attempt_at) where status = 'pending';
+create index if not exists webhook_deliveries_webhook_created_idx on public.webhook_deliveries (webhook_id, created_at desc);
+create index if not exists idempotency_keys_expires_at_idx on public.idempotency_keys (expires_at);
 
 -- Helper functions and triggers
 create or replace function public.set_updated_at()
@@ -94,6 +370,55 @@ begin
 end;
 $$;
 
+create or replace function public.bump_revision()
+returns trigger
+language plpgsql
+as $$
+begin
+  new.revision = old.revision + 1;
+  return new;
+end;
+$$;
+
+-- The actor is taken from the app.actor setting when a job sets it, then the
+-- signed-in Supabase user, then the lot owner.
+create or replace function public.record_lot_event()
+returns trigger
+language plpgsql
+security definer
+set search_path = public
+as $$
+declare
+  kind text;
+  before_row jsonb;
+begin
+  if tg_op = 'INSERT' then
+    kind := 'created';
+  elsif old.deleted_at is null and new.deleted_at is not null then
+    kind := 'deleted';
+  elsif old.deleted_at is not null and new.deleted_at is null then
+    kind := 'restored';
+  else
+    kind := 'updated';
+  end if;
+
+  if tg_op = 'UPDATE' then
+    before_row := to_jsonb(old) - 'user_id';
+  end if;
+
+  insert into public.lot_events (lot_id, user_id, event_type, actor, before, after)
+  values (
+    new.id,
+    new.user_id,
+    kind,
+    coalesce(nullif(current_setting('app.actor', true), ''), auth.uid()::text, new.user_id::text),
+    before_row,
+    to_jsonb(new) - 'user_id'
+  );
+  return new;
+end;
+$$;
+
 create or replace function public.clamp_refresh_interval()
 returns trigger
 language plpgsql
@@ -142,6 +467,14 @@ create trigger lots_set_updated_at
 before update on public.lots
 for each row execute procedure public.set_updated_at();
 
+create trigger lots_bump_revision
+before update on public.lots
+for each row execute procedure public.bump_revision();
+
+create trigger lots_record_event
+after insert or update on public.lots
+for each row execute procedure public.record_lot_event();
+
 create trigger user_settings_set_updated_at
 before update on public.user_settings
 for each row execute procedure public.set_updated_at();
@@ -150,6 +483,22 @@ create trigger app_settings_set_updated_at
 before update on public.app_settings
 for each row execute procedure public.set_updated_at();
 
+create trigger alerts_set_updated_at
+before update on public.alerts
+for each row execute procedure public.set_updated_at();
+
+create trigger webhooks_set_updated_at
+before update on public.webhooks
+for each row execute procedure public.set_updated_at();
+
+create trigger watchlists_set_updated_at
+before update on public.watchlists
+for each row execute procedure public.set_updated_at();
+
+create trigger income_set_updated_at
+before update on public.income
+for each row execute procedure public.set_updated_at();
+
 create trigger user_settings_clamp_refresh_interval
 before insert or update on public.user_settings
 for each row execute procedure public.clamp_refresh_interval();
@@ -166,10 +515,23 @@ select
   sum(l.quantity) as total_qty,
   sum(l.quantity * l.unit_cost) / nullif(sum(l.quantity), 0) as avg_cost,
   pc.price as current_price,
-  (pc.price - (sum(l.quantity * l.unit_cost) / nullif(sum(l.quantity), 0))) * sum(l.quantity) as unrealized_pl
+  (pc.price - (sum(l.quantity * l.unit_cost) / nullif(sum(l.quantity), 0))) * sum(l.quantity) as unrealized_pl,
+  coalesce(i.income_received, 0) as income_received,
+  pc.change_24h_pct,
+  pc.volume_24h,
+  pc.market_cap,
+  pc.provider_as_of as price_as_of
 from public.lots l
 left join public.prices_current pc on pc.asset_id = l.asset_id
-group by l.user_id, l.asset_id, pc.price;
+-- Only USD income is summed; other currencies are not converted.
+left join (
+  select user_id, asset_id, sum(amount) as income_received
+  from public.income
+  where currency = 'USD'
+  group by user_id, asset_id
+) i on i.user_id = l.user_id and i.asset_id = l.asset_id
+where l.deleted_at is null
+group by l.user_id, l.asset_id, pc.price, i.income_received, pc.change_24h_pct, pc.volume_24h, pc.market_cap, pc.provider_as_of;
 
 create or replace view public.lot_performance_view as
 select
@@ -182,6 +544,7 @@ select
   pc.price as current_price,
   (pc.price - l.unit_cost) * l.quantity as unrealized_pl
 from public.lots l
-left join public.prices_current pc on pc.asset_id = l.asset_id;
+left join public.prices_current pc on pc.asset_id = l.asset_id
+where l.deleted_at is null;
 
 commit;